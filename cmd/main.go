@@ -1,17 +1,32 @@
 package main
 
 import (
+	"context"
 	graph2 "github.com/aaanger/graphql-test/internal/graph"
+	"github.com/aaanger/graphql-test/internal/graph/loaders"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/jobs"
 	commentRepository "github.com/aaanger/graphql-test/internal/repository/comment"
+	"github.com/aaanger/graphql-test/internal/repository/memory"
 	postRepository "github.com/aaanger/graphql-test/internal/repository/post"
+	reactionRepository "github.com/aaanger/graphql-test/internal/repository/reaction"
+	reportRepository "github.com/aaanger/graphql-test/internal/repository/report"
+	searchRepository "github.com/aaanger/graphql-test/internal/repository/search"
+	sessionRepository "github.com/aaanger/graphql-test/internal/repository/session"
 	UserRepository "github.com/aaanger/graphql-test/internal/repository/user"
+	voteRepository "github.com/aaanger/graphql-test/internal/repository/vote"
 	"github.com/aaanger/graphql-test/pkg/db"
+	"github.com/aaanger/graphql-test/pkg/jwt"
 	"github.com/aaanger/graphql-test/pkg/middleware"
+	"github.com/aaanger/graphql-test/pkg/pubsub"
+	"github.com/aaanger/graphql-test/pkg/ratelimit"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/handler/extension"
@@ -21,7 +36,23 @@ import (
 	"github.com/vektah/gqlparser/v2/ast"
 )
 
-const defaultPort = "8080"
+const (
+	defaultPort = "8080"
+
+	storageMemory   = "memory"
+	storagePostgres = "postgres"
+
+	subscriptionBufferSize = 16
+	websocketKeepAlive     = 10 * time.Second
+
+	defaultPostRestoreWindow = 7 * 24 * time.Hour
+	defaultPostPurgeInterval = time.Hour
+	postPublishInterval      = 10 * time.Second
+
+	defaultReactions = "👍,👎,😄,🎉,😕,❤️,🚀,👀"
+
+	defaultRateLimits = "login=10/1m,register=5/1h,refreshToken=30/1m,createPost=10/1m,createComment=30/1m,reportPost=10/1m,reportComment=10/1m"
+)
 
 func main() {
 	err := godotenv.Load()
@@ -29,33 +60,113 @@ func main() {
 		logrus.Fatalf("Error loading .env file: %s", err)
 	}
 
-	db, err := db.Open(db.PostgresConfig{
-		Host:     os.Getenv("PSQL_HOST"),
-		Port:     os.Getenv("PSQL_PORT"),
-		User:     os.Getenv("PSQL_USER"),
-		Password: os.Getenv("PSQL_PASSWORD"),
-		DBName:   os.Getenv("PSQL_DBNAME"),
-		SSLMode:  "disable",
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = defaultPort
+	}
+
+	tokens, err := jwt.Open(jwt.Config{
+		KeyID:            os.Getenv("JWT_KEY_ID"),
+		Secret:           os.Getenv("JWT_SECRET"),
+		SecretFile:       os.Getenv("JWT_SECRET_FILE"),
+		PrivateKeyFile:   os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		VerificationKeys: os.Getenv("JWT_VERIFICATION_KEYS"),
 	})
 	if err != nil {
-		logrus.Fatalf("Error connecting to db: %s", err)
+		logrus.Fatalf("Error loading jwt keys: %s", err)
 	}
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = defaultPort
+	postRestoreWindow, err := durationEnv("POST_RESTORE_WINDOW", defaultPostRestoreWindow)
+	if err != nil {
+		logrus.Fatalf("Error reading POST_RESTORE_WINDOW: %s", err)
+	}
+
+	postPurgeInterval, err := durationEnv("POST_PURGE_INTERVAL", defaultPostPurgeInterval)
+	if err != nil {
+		logrus.Fatalf("Error reading POST_PURGE_INTERVAL: %s", err)
 	}
 
-	userRepo := UserRepository.NewUserRepository(db)
-	postRepo := postRepository.NewPostRepository(db)
-	commentRepo := commentRepository.NewCommentRepository(db)
+	reactions := listEnv("REACTION_EMOJIS", defaultReactions)
+
+	rateLimits, err := ratelimit.ParseLimits(stringEnv("RATE_LIMITS", defaultRateLimits))
+	if err != nil {
+		logrus.Fatalf("Error reading RATE_LIMITS: %s", err)
+	}
+
+	var (
+		userRepo     UserRepository.IUserRepository
+		postRepo     postRepository.IPostRepository
+		commentRepo  commentRepository.ICommentRepository
+		sessionRepo  sessionRepository.ISessionRepository
+		voteRepo     voteRepository.IVoteRepository
+		reactionRepo reactionRepository.IReactionRepository
+		searchRepo   searchRepository.ISearchRepository
+		reportRepo   reportRepository.IReportRepository
+	)
+
+	storage := os.Getenv("STORAGE")
+	switch storage {
+	case storageMemory:
+		s := memory.NewStorage()
+
+		userRepo = memory.NewUserRepository(s)
+		postRepo = memory.NewPostRepository(s)
+		commentRepo = memory.NewCommentRepository(s)
+		sessionRepo = memory.NewSessionRepository(s)
+		voteRepo = memory.NewVoteRepository(s)
+		reactionRepo = memory.NewReactionRepository(s)
+		searchRepo = memory.NewSearchRepository(s)
+		reportRepo = memory.NewReportRepository(s)
+	case storagePostgres, "":
+		db, err := db.Open(db.PostgresConfig{
+			Host:     os.Getenv("PSQL_HOST"),
+			Port:     os.Getenv("PSQL_PORT"),
+			User:     os.Getenv("PSQL_USER"),
+			Password: os.Getenv("PSQL_PASSWORD"),
+			DBName:   os.Getenv("PSQL_DBNAME"),
+			SSLMode:  "disable",
+		})
+		if err != nil {
+			logrus.Fatalf("Error connecting to db: %s", err)
+		}
+
+		userRepo = UserRepository.NewUserRepository(db)
+		postRepo = postRepository.NewPostRepository(db)
+		commentRepo = commentRepository.NewCommentRepository(db)
+		sessionRepo = sessionRepository.NewSessionRepository(db)
+		voteRepo = voteRepository.NewVoteRepository(db)
+		reactionRepo = reactionRepository.NewReactionRepository(db)
+		searchRepo = searchRepository.NewSearchRepository(db)
+		reportRepo = reportRepository.NewReportRepository(db)
+	default:
+		logrus.Fatalf("Unknown storage %q, expected %q or %q", storage, storageMemory, storagePostgres)
+	}
+
+	go jobs.NewPostPurger(postRepo, postRestoreWindow, postPurgeInterval).Run(context.Background())
+	go jobs.NewPostScheduler(postRepo, postPublishInterval).Run(context.Background())
 
 	srv := handler.New(graph2.NewExecutableSchema(graph2.Config{Resolvers: &graph2.Resolver{
-		UserRepo:    userRepo,
-		PostRepo:    postRepo,
-		CommentRepo: commentRepo,
-	}}))
+		UserRepo:          userRepo,
+		PostRepo:          postRepo,
+		CommentRepo:       commentRepo,
+		SessionRepo:       sessionRepo,
+		VoteRepo:          voteRepo,
+		ReactionRepo:      reactionRepo,
+		SearchRepo:        searchRepo,
+		ReportRepo:        reportRepo,
+		Tokens:            tokens,
+		CommentHub:        pubsub.NewHub[int, *model.Comment](subscriptionBufferSize),
+		PostRestoreWindow: postRestoreWindow,
+		Reactions:         reactions,
+	}, Directives: graph2.NewDirectiveRoot()}))
+
+	srv.SetErrorPresenter(graph2.ErrorPresenter)
+	srv.SetRecoverFunc(graph2.RecoverFunc)
 
+	srv.AddTransport(transport.Websocket{
+		KeepAlivePingInterval: websocketKeepAlive,
+		InitFunc:              middleware.WebsocketInit(tokens, sessionRepo, userRepo),
+	})
 	srv.AddTransport(transport.Options{})
 	srv.AddTransport(transport.GET{})
 	srv.AddTransport(transport.POST{})
@@ -66,10 +177,62 @@ func main() {
 	srv.Use(extension.AutomaticPersistedQuery{
 		Cache: lru.New[string](100),
 	})
+	srv.Use(loaders.Extension{
+		UserRepo:     userRepo,
+		CommentRepo:  commentRepo,
+		VoteRepo:     voteRepo,
+		ReactionRepo: reactionRepo,
+	})
+	srv.Use(ratelimit.Extension{
+		Store:  ratelimit.NewMemoryStore(),
+		Limits: rateLimits,
+	})
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
-	http.Handle("/query", middleware.UserIdentity(srv))
+	http.Handle("/.well-known/jwks.json", tokens.JWKSHandler())
+	http.Handle("/query", middleware.ClientIP(os.Getenv("TRUST_PROXY") == "true",
+		middleware.UserIdentity(tokens, sessionRepo, userRepo, srv)))
 
 	log.Printf("connect to http://localhost:%s/ for GraphQL playground", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
+
+// durationEnv reads a duration such as "72h" from the environment variable,
+// falling back to def when it isn't set.
+func durationEnv(name string, def time.Duration) (time.Duration, error) {
+	value := os.Getenv(name)
+	if value == "" {
+		return def, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// stringEnv reads the environment variable, falling back to def when it isn't
+// set.
+func stringEnv(name, def string) string {
+	value := os.Getenv(name)
+	if value == "" {
+		return def
+	}
+
+	return value
+}
+
+// listEnv reads a comma separated list from the environment variable,
+// falling back to def when it isn't set.
+func listEnv(name, def string) []string {
+	value := os.Getenv(name)
+	if value == "" {
+		value = def
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+
+	return list
+}
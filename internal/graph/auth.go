@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/jwt"
+	"github.com/aaanger/graphql-test/pkg/middleware"
+	"strings"
+	"time"
+)
+
+// startSession opens a new session for the user and issues its first pair of
+// access and refresh tokens.
+func (r *Resolver) startSession(ctx context.Context, user *model.User) (*model.AuthRes, error) {
+	refreshToken, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionRepo.CreateSession(ctx, user.ID, jwt.HashRefreshToken(refreshToken), time.Now().Add(jwt.RefreshTokenExpire))
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := r.Tokens.GenerateAccessToken(user.ID, session.ID, user.Role.String())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthRes{
+		User:         user,
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// viewer returns the authenticated user as a policy actor.
+func viewer(ctx context.Context) (policy.Actor, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return policy.Actor{}, err
+	}
+
+	return policy.Actor{
+		UserID: userID,
+		Role:   viewerRole(ctx),
+	}, nil
+}
+
+// canSeeEmail reports whether the viewer may read the email of the user: their
+// own, anyone's as an admin, and the one of the account an AuthRes was just
+// issued for, before the new token is sent along with requests.
+func canSeeEmail(ctx context.Context, user *model.User) bool {
+	fc := graphql.GetFieldContext(ctx)
+	if fc != nil && fc.Parent != nil && fc.Parent.Object == "AuthRes" {
+		return true
+	}
+
+	actor, err := viewer(ctx)
+	if err != nil {
+		return false
+	}
+
+	return actor.UserID == user.ID || policy.HasRole(actor.Role, model.RoleAdmin)
+}
+
+// setRole changes the role of another user on behalf of the viewer.
+func (r *Resolver) setRole(ctx context.Context, userID int, role model.Role) (*model.User, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = policy.CanSetRole(actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.UserRepo.SetRole(ctx, userID, role)
+}
+
+// canBanUser looks up the role of the user and checks the actor may ban or
+// unban them.
+func (r *Resolver) canBanUser(ctx context.Context, actor policy.Actor, userID int) error {
+	users, err := r.UserRepo.GetUsersByIDs(ctx, []int{userID})
+	if err != nil {
+		return err
+	}
+
+	if len(users) == 0 {
+		return apperror.NotFound("user not found")
+	}
+
+	return policy.CanBanUser(actor, userID, users[0].Role)
+}
+
+// banUser bans another user on behalf of the viewer, until the given time or
+// for good when until is nil.
+func (r *Resolver) banUser(ctx context.Context, userID int, until *time.Time, reason string) (*model.User, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.canBanUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, apperror.Validation("ban reason is required")
+	}
+
+	if len(reason) > 500 {
+		return nil, apperror.Validation("ban reason must be less than 500 chars")
+	}
+
+	if until != nil {
+		// banned_until keeps no time zone, the offset would be lost
+		utc := until.UTC()
+		until = &utc
+
+		if !until.After(time.Now()) {
+			return nil, apperror.Validation("ban must end in the future")
+		}
+	}
+
+	return r.UserRepo.BanUser(ctx, userID, until, reason)
+}
+
+// unbanUser lifts the ban of another user on behalf of the viewer.
+func (r *Resolver) unbanUser(ctx context.Context, userID int) (*model.User, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.canBanUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.UserRepo.UnbanUser(ctx, userID)
+}
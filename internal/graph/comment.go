@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"slices"
+)
+
+// commentOrder returns the requested order of comments, oldest first when
+// none is given.
+func commentOrder(orderBy *model.CommentOrder) model.CommentOrder {
+	if orderBy == nil {
+		return model.CommentOrderOldest
+	}
+
+	return *orderBy
+}
+
+// validateReaction makes sure the emoji is one of the allowed reactions.
+func validateReaction(allowed []string, emoji string) error {
+	if !slices.Contains(allowed, emoji) {
+		return apperror.Validation("emoji is not an allowed reaction")
+	}
+
+	return nil
+}
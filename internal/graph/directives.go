@@ -0,0 +1,54 @@
+package graph
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/middleware"
+)
+
+// NewDirectiveRoot returns the implementations of the schema directives.
+func NewDirectiveRoot() DirectiveRoot {
+	return DirectiveRoot{
+		Auth:    authDirective,
+		HasRole: hasRoleDirective,
+	}
+}
+
+// authDirective allows the field only for authenticated users.
+func authDirective(ctx context.Context, obj any, next graphql.Resolver) (any, error) {
+	_, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, apperror.Unauthenticated("authentication required")
+	}
+
+	return next(ctx)
+}
+
+// hasRoleDirective allows the field only for users having at least the given
+// role.
+func hasRoleDirective(ctx context.Context, obj any, next graphql.Resolver, role model.Role) (any, error) {
+	_, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, apperror.Unauthenticated("authentication required")
+	}
+
+	if !policy.HasRole(viewerRole(ctx), role) {
+		return nil, apperror.Forbidden("not enough permissions")
+	}
+
+	return next(ctx)
+}
+
+// viewerRole returns the role of the authenticated user, users without an
+// explicit role are regular users.
+func viewerRole(ctx context.Context) model.Role {
+	role, err := middleware.GetRole(ctx)
+	if err != nil {
+		return model.RoleUser
+	}
+
+	return model.Role(role)
+}
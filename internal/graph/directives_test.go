@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/client"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func resolved(ctx context.Context) (any, error) {
+	return "resolved", nil
+}
+
+func TestDirectives_Auth(t *testing.T) {
+	res, err := authDirective(context.Background(), nil, resolved)
+	assert.Nil(t, res)
+	assert.Equal(t, apperror.CodeUnauthenticated, apperror.CodeOf(err))
+
+	res, err = authDirective(context.WithValue(context.Background(), "userID", 1), nil, resolved)
+	assert.Nil(t, err)
+	assert.Equal(t, "resolved", res)
+}
+
+func TestDirectives_HasRole(t *testing.T) {
+	_, err := hasRoleDirective(context.Background(), nil, resolved, model.RoleUser)
+	assert.Equal(t, apperror.CodeUnauthenticated, apperror.CodeOf(err))
+
+	user := context.WithValue(context.Background(), "userID", 1)
+
+	res, err := hasRoleDirective(user, nil, resolved, model.RoleUser)
+	assert.Nil(t, err)
+	assert.Equal(t, "resolved", res)
+
+	_, err = hasRoleDirective(user, nil, resolved, model.RoleModerator)
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(err))
+
+	admin := context.WithValue(user, "role", string(model.RoleAdmin))
+
+	res, err = hasRoleDirective(admin, nil, resolved, model.RoleModerator)
+	assert.Nil(t, err)
+	assert.Equal(t, "resolved", res)
+}
+
+func TestDirectives_EnforcedBySchema(t *testing.T) {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{}, Directives: NewDirectiveRoot()}))
+	srv.SetErrorPresenter(ErrorPresenter)
+	srv.AddTransport(transport.POST{})
+
+	var codes []string
+	srv.AroundResponses(func(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+		res := next(ctx)
+		for _, err := range res.Errors {
+			code, _ := err.Extensions["code"].(string)
+			codes = append(codes, code)
+		}
+		return res
+	})
+
+	c := client.New(srv)
+
+	var resp struct {
+		CreatePost struct{ ID int }
+	}
+	err := c.Post(`mutation { createPost(req: {title: "test", body: "test", allowComments: true}) { id } }`, &resp)
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []string{string(apperror.CodeUnauthenticated)}, codes)
+}
+
+func TestDirectives_SubscriptionRequiresAuth(t *testing.T) {
+	srv := handler.New(NewExecutableSchema(Config{Resolvers: &Resolver{}, Directives: NewDirectiveRoot()}))
+	srv.SetErrorPresenter(ErrorPresenter)
+	srv.AddTransport(transport.Websocket{})
+
+	c := client.New(srv)
+
+	sub := c.Websocket(`subscription { commentAdded(postID: 1) { id } }`)
+	defer sub.Close()
+
+	var resp struct {
+		CommentAdded struct{ ID int }
+	}
+	err := sub.Next(&resp)
+
+	assert.ErrorContains(t, err, string(apperror.CodeUnauthenticated))
+}
@@ -0,0 +1,68 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/sirupsen/logrus"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"runtime/debug"
+)
+
+// ErrorPresenter converts resolver errors into GraphQL errors with a code in
+// extensions.code. Only messages of domain errors reach clients, everything
+// else is logged and reported as an internal error.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		if appErr.Code == apperror.CodeInternal || appErr.Err != nil {
+			logError(ctx, appErr.Code, err)
+		}
+
+		gqlErr := newCodeError(ctx, appErr.Message, appErr.Code)
+		for key, value := range appErr.Extensions {
+			gqlErr.Extensions[key] = value
+		}
+
+		return gqlErr
+	}
+
+	// errors produced by gqlgen itself, e.g. for arguments of a wrong type,
+	// are already meant for clients
+	var gqlErr *gqlerror.Error
+	if errors.As(err, &gqlErr) && gqlErr.Unwrap() == nil {
+		return gqlErr
+	}
+
+	logError(ctx, apperror.CodeInternal, err)
+
+	return newCodeError(ctx, "internal server error", apperror.CodeInternal)
+}
+
+// RecoverFunc turns a panic in a resolver into an internal error instead of
+// crashing the request.
+func RecoverFunc(ctx context.Context, p any) error {
+	logrus.WithField("path", graphql.GetPath(ctx).String()).
+		Errorf("panic in resolver: %v\n%s", p, debug.Stack())
+
+	return apperror.Internal(fmt.Errorf("panic: %v", p))
+}
+
+func logError(ctx context.Context, code apperror.Code, err error) {
+	logrus.WithFields(logrus.Fields{
+		"path": graphql.GetPath(ctx).String(),
+		"code": code,
+	}).Error(err)
+}
+
+func newCodeError(ctx context.Context, message string, code apperror.Code) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: message,
+		Path:    graphql.GetPath(ctx),
+		Extensions: map[string]interface{}{
+			"code": string(code),
+		},
+	}
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"testing"
+)
+
+func TestErrorPresenter_DomainError(t *testing.T) {
+	err := fmt.Errorf("get post: %w", apperror.NotFound("post not found").Wrap(sql.ErrNoRows))
+
+	gqlErr := ErrorPresenter(context.Background(), err)
+
+	assert.Equal(t, "post not found", gqlErr.Message)
+	assert.Equal(t, "NOT_FOUND", gqlErr.Extensions["code"])
+}
+
+func TestErrorPresenter_Extensions(t *testing.T) {
+	gqlErr := ErrorPresenter(context.Background(), apperror.Banned(nil, "spam"))
+
+	assert.Equal(t, "BANNED", gqlErr.Extensions["code"])
+	assert.Equal(t, "spam", gqlErr.Extensions["reason"])
+	assert.Contains(t, gqlErr.Extensions, "bannedUntil")
+}
+
+func TestErrorPresenter_HidesUnknownErrors(t *testing.T) {
+	gqlErr := ErrorPresenter(context.Background(), sql.ErrConnDone)
+
+	assert.Equal(t, "internal server error", gqlErr.Message)
+	assert.Equal(t, "INTERNAL", gqlErr.Extensions["code"])
+}
+
+func TestErrorPresenter_KeepsGraphQLErrors(t *testing.T) {
+	gqlErr := ErrorPresenter(context.Background(), gqlerror.Errorf("invalid value for argument"))
+
+	assert.Equal(t, "invalid value for argument", gqlErr.Message)
+}
+
+func TestRecoverFunc(t *testing.T) {
+	err := RecoverFunc(context.Background(), "boom")
+
+	gqlErr := ErrorPresenter(context.Background(), err)
+
+	assert.Equal(t, "internal server error", gqlErr.Message)
+	assert.Equal(t, "INTERNAL", gqlErr.Extensions["code"])
+}
@@ -0,0 +1,282 @@
+package loaders
+
+import (
+	"context"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/comment"
+	"github.com/aaanger/graphql-test/internal/repository/reaction"
+	"github.com/aaanger/graphql-test/internal/repository/user"
+	"github.com/aaanger/graphql-test/internal/repository/vote"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/middleware"
+	"github.com/vikstrous/dataloadgen"
+	"time"
+)
+
+const loadersKey = "loaders"
+
+const batchWait = 2 * time.Millisecond
+
+// ConnectionKey identifies one page of a connection that belongs to a
+// parent entity. Pagination arguments are kept by value, so equal requests
+// for different parents end up in the same batch.
+type ConnectionKey struct {
+	ID   int
+	Page Page
+}
+
+// Page holds connection arguments by value; unset First and Last are -1 and
+// unset cursors are empty.
+type Page struct {
+	First, Last   int
+	After, Before string
+	Order         model.CommentOrder
+}
+
+func NewPage(first, last *int, after, before *string, orderBy model.CommentOrder) Page {
+	page := Page{First: -1, Last: -1, Order: orderBy}
+
+	if first != nil {
+		page.First = *first
+	}
+	if last != nil {
+		page.Last = *last
+	}
+	if after != nil {
+		page.After = *after
+	}
+	if before != nil {
+		page.Before = *before
+	}
+
+	return page
+}
+
+func (p Page) args() (first, last *int, after, before *string) {
+	if p.First >= 0 {
+		first = &p.First
+	}
+	if p.Last >= 0 {
+		last = &p.Last
+	}
+	if p.After != "" {
+		after = &p.After
+	}
+	if p.Before != "" {
+		before = &p.Before
+	}
+
+	return first, last, after, before
+}
+
+// Loaders batches lookups made while resolving a single request, so nested
+// fields of a list cost one query per level instead of one query per item.
+type Loaders struct {
+	UserByID           *dataloadgen.Loader[int, *model.User]
+	EmailByUserID      *dataloadgen.Loader[int, string]
+	CommentsByPostID   *dataloadgen.Loader[ConnectionKey, *model.CommentConnection]
+	RepliesByCommentID *dataloadgen.Loader[ConnectionKey, *model.CommentConnection]
+
+	// PostVoteByID and CommentVoteByID load the votes of the authenticated
+	// user.
+	PostVoteByID    *dataloadgen.Loader[int, model.VoteValue]
+	CommentVoteByID *dataloadgen.Loader[int, model.VoteValue]
+
+	// ReactionsByCommentID marks the reactions of the authenticated user as
+	// reacted.
+	ReactionsByCommentID *dataloadgen.Loader[int, []*model.ReactionSummary]
+}
+
+func NewLoaders(userRepo user.IUserRepository, commentRepo comment.ICommentRepository, voteRepo vote.IVoteRepository, reactionRepo reaction.IReactionRepository) *Loaders {
+	return &Loaders{
+		UserByID:           dataloadgen.NewLoader(usersByIDs(userRepo), dataloadgen.WithWait(batchWait)),
+		EmailByUserID:      dataloadgen.NewLoader(emailsByIDs(userRepo), dataloadgen.WithWait(batchWait)),
+		CommentsByPostID:   dataloadgen.NewLoader(connectionsByIDs(commentRepo.GetCommentsByPostIDs), dataloadgen.WithWait(batchWait)),
+		RepliesByCommentID: dataloadgen.NewLoader(connectionsByIDs(commentRepo.GetRepliesByCommentIDs), dataloadgen.WithWait(batchWait)),
+		PostVoteByID:       dataloadgen.NewLoader(votesByIDs(voteRepo.GetPostVotes), dataloadgen.WithWait(batchWait)),
+		CommentVoteByID:    dataloadgen.NewLoader(votesByIDs(voteRepo.GetCommentVotes), dataloadgen.WithWait(batchWait)),
+
+		ReactionsByCommentID: dataloadgen.NewLoader(reactionsByIDs(reactionRepo), dataloadgen.WithWait(batchWait)),
+	}
+}
+
+// Extension puts fresh loaders into the context of every response. A query
+// or mutation has a single response, while a subscription gets one per pushed
+// payload, so cached users and votes never outlive the payload they were
+// loaded for, even though the WebSocket connection stays open.
+type Extension struct {
+	UserRepo     user.IUserRepository
+	CommentRepo  comment.ICommentRepository
+	VoteRepo     vote.IVoteRepository
+	ReactionRepo reaction.IReactionRepository
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.ResponseInterceptor
+} = Extension{}
+
+func (e Extension) ExtensionName() string {
+	return "Loaders"
+}
+
+func (e Extension) Validate(schema graphql.ExecutableSchema) error {
+	return nil
+}
+
+func (e Extension) InterceptResponse(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	return next(NewContext(ctx, NewLoaders(e.UserRepo, e.CommentRepo, e.VoteRepo, e.ReactionRepo)))
+}
+
+func NewContext(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loadersKey, loaders)
+}
+
+func For(ctx context.Context) *Loaders {
+	return ctx.Value(loadersKey).(*Loaders)
+}
+
+func usersByIDs(userRepo user.IUserRepository) func(ctx context.Context, ids []int) ([]*model.User, []error) {
+	return func(ctx context.Context, ids []int) ([]*model.User, []error) {
+		users, err := userRepo.GetUsersByIDs(ctx, ids)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		byID := make(map[int]*model.User, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+
+		result := make([]*model.User, len(ids))
+		errs := make([]error, len(ids))
+		for i, id := range ids {
+			u, ok := byID[id]
+			if !ok {
+				errs[i] = apperror.NotFound(fmt.Sprintf("user %d not found", id))
+				continue
+			}
+			result[i] = u
+		}
+
+		return result, errs
+	}
+}
+
+func emailsByIDs(userRepo user.IUserRepository) func(ctx context.Context, ids []int) ([]string, []error) {
+	return func(ctx context.Context, ids []int) ([]string, []error) {
+		emails, err := userRepo.GetEmailsByIDs(ctx, ids)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		result := make([]string, len(ids))
+		errs := make([]error, len(ids))
+		for i, id := range ids {
+			email, ok := emails[id]
+			if !ok {
+				errs[i] = apperror.NotFound(fmt.Sprintf("user %d not found", id))
+				continue
+			}
+			result[i] = email
+		}
+
+		return result, errs
+	}
+}
+
+type votesFetcher func(ctx context.Context, userID int, ids []int) (map[int]model.VoteValue, error)
+
+// votesByIDs loads the votes of the authenticated user, entities without a
+// vote resolve to NONE.
+func votesByIDs(fetch votesFetcher) func(ctx context.Context, ids []int) ([]model.VoteValue, []error) {
+	return func(ctx context.Context, ids []int) ([]model.VoteValue, []error) {
+		userID, err := middleware.GetUserID(ctx)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		votes, err := fetch(ctx, userID, ids)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		result := make([]model.VoteValue, len(ids))
+		for i, id := range ids {
+			value, ok := votes[id]
+			if !ok {
+				value = model.VoteValueNone
+			}
+			result[i] = value
+		}
+
+		return result, nil
+	}
+}
+
+// reactionsByIDs loads reaction summaries, comments without reactions
+// resolve to an empty list. Anonymous viewers haven't reacted to anything.
+func reactionsByIDs(reactionRepo reaction.IReactionRepository) func(ctx context.Context, ids []int) ([][]*model.ReactionSummary, []error) {
+	return func(ctx context.Context, ids []int) ([][]*model.ReactionSummary, []error) {
+		userID, _ := middleware.GetUserID(ctx)
+
+		reactions, err := reactionRepo.GetReactions(ctx, userID, ids)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		result := make([][]*model.ReactionSummary, len(ids))
+		for i, id := range ids {
+			result[i] = reactions[id]
+			if result[i] == nil {
+				result[i] = []*model.ReactionSummary{}
+			}
+		}
+
+		return result, nil
+	}
+}
+
+type connectionsFetcher func(ctx context.Context, ids []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error)
+
+// connectionsByIDs issues one fetch per distinct page among the keys, which
+// in practice is a single fetch since sibling fields share their arguments.
+func connectionsByIDs(fetch connectionsFetcher) func(ctx context.Context, keys []ConnectionKey) ([]*model.CommentConnection, []error) {
+	return func(ctx context.Context, keys []ConnectionKey) ([]*model.CommentConnection, []error) {
+		idsByPage := make(map[Page][]int)
+		for _, key := range keys {
+			idsByPage[key.Page] = append(idsByPage[key.Page], key.ID)
+		}
+
+		connections := make(map[ConnectionKey]*model.CommentConnection, len(keys))
+		failed := make(map[Page]error)
+
+		for page, ids := range idsByPage {
+			first, last, after, before := page.args()
+
+			result, err := fetch(ctx, ids, first, last, after, before, page.Order)
+			if err != nil {
+				failed[page] = err
+				continue
+			}
+
+			for id, connection := range result {
+				connections[ConnectionKey{ID: id, Page: page}] = connection
+			}
+		}
+
+		result := make([]*model.CommentConnection, len(keys))
+		errs := make([]error, len(keys))
+		for i, key := range keys {
+			if err, ok := failed[key.Page]; ok {
+				errs[i] = err
+				continue
+			}
+			result[i] = connections[key]
+		}
+
+		return result, errs
+	}
+}
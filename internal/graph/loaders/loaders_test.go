@@ -0,0 +1,98 @@
+package loaders
+
+import (
+	"context"
+	"errors"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	commentMocks "github.com/aaanger/graphql-test/internal/repository/comment/mocks"
+	reactionMocks "github.com/aaanger/graphql-test/internal/repository/reaction/mocks"
+	userMocks "github.com/aaanger/graphql-test/internal/repository/user/mocks"
+	voteMocks "github.com/aaanger/graphql-test/internal/repository/vote/mocks"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPage_ArgsRoundTrip(t *testing.T) {
+	first := 0
+	after := "cursor"
+
+	gotFirst, gotLast, gotAfter, gotBefore := NewPage(&first, nil, &after, nil, model.CommentOrderOldest).args()
+
+	assert.Equal(t, &first, gotFirst)
+	assert.Nil(t, gotLast)
+	assert.Equal(t, &after, gotAfter)
+	assert.Nil(t, gotBefore)
+}
+
+func TestConnectionsByIDs_OneFetchPerPage(t *testing.T) {
+	var calls [][]int
+	fetch := func(ctx context.Context, ids []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+		calls = append(calls, ids)
+
+		result := make(map[int]*model.CommentConnection)
+		for _, id := range ids {
+			result[id] = &model.CommentConnection{PageInfo: &model.PageInfo{HasNextPage: first != nil}}
+		}
+		return result, nil
+	}
+
+	one := 1
+	keys := []ConnectionKey{
+		{ID: 1, Page: NewPage(&one, nil, nil, nil, model.CommentOrderOldest)},
+		{ID: 2, Page: NewPage(&one, nil, nil, nil, model.CommentOrderOldest)},
+		{ID: 3, Page: NewPage(nil, nil, nil, nil, model.CommentOrderOldest)},
+	}
+
+	connections, errs := connectionsByIDs(fetch)(context.Background(), keys)
+
+	assert.Len(t, calls, 2)
+	assert.Equal(t, []error{nil, nil, nil}, errs)
+	assert.True(t, connections[0].PageInfo.HasNextPage)
+	assert.True(t, connections[1].PageInfo.HasNextPage)
+	assert.False(t, connections[2].PageInfo.HasNextPage)
+}
+
+func TestConnectionsByIDs_FetchError(t *testing.T) {
+	fetch := func(ctx context.Context, ids []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+		return nil, errors.New("error")
+	}
+
+	connections, errs := connectionsByIDs(fetch)(context.Background(), []ConnectionKey{{ID: 1}})
+
+	assert.Nil(t, connections[0])
+	assert.NotNil(t, errs[0])
+}
+
+func TestVotesByIDs_DefaultsToNone(t *testing.T) {
+	fetch := func(ctx context.Context, userID int, ids []int) (map[int]model.VoteValue, error) {
+		assert.Equal(t, 7, userID)
+		return map[int]model.VoteValue{2: model.VoteValueUp}, nil
+	}
+
+	ctx := context.WithValue(context.Background(), "userID", 7)
+	votes, errs := votesByIDs(fetch)(ctx, []int{1, 2})
+
+	assert.Nil(t, errs)
+	assert.Equal(t, []model.VoteValue{model.VoteValueNone, model.VoteValueUp}, votes)
+}
+
+func TestExtension_FreshLoadersPerResponse(t *testing.T) {
+	var seen []*Loaders
+	next := func(ctx context.Context) *graphql.Response {
+		seen = append(seen, For(ctx))
+		return &graphql.Response{}
+	}
+
+	ext := Extension{
+		UserRepo:     userMocks.NewIUserRepository(t),
+		CommentRepo:  commentMocks.NewICommentRepository(t),
+		VoteRepo:     voteMocks.NewIVoteRepository(t),
+		ReactionRepo: reactionMocks.NewIReactionRepository(t),
+	}
+	ext.InterceptResponse(context.Background(), next)
+	ext.InterceptResponse(context.Background(), next)
+
+	assert.Len(t, seen, 2)
+	assert.NotSame(t, seen[0], seen[1])
+}
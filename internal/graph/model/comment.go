@@ -0,0 +1,36 @@
+package model
+
+import "time"
+
+// DeletedCommentBody replaces the body of deleted comments in the API.
+const DeletedCommentBody = "[deleted]"
+
+type Comment struct {
+	ID              int        `json:"id"`
+	PostID          int        `json:"postID"`
+	UserID          int        `json:"userID"`
+	Body            string     `json:"body"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       *time.Time `json:"updatedAt"`
+	DeletedAt       *time.Time `json:"-"`
+	Upvotes         int        `json:"upvotes"`
+	Downvotes       int        `json:"downvotes"`
+	ParentCommentID *int       `json:"parentCommentID,omitempty"`
+}
+
+// IsEdited reports whether the body has been changed since the comment was
+// created.
+func (c *Comment) IsEdited() bool {
+	return c.UpdatedAt != nil
+}
+
+// IsDeleted reports whether the comment has been deleted. Deleted comments
+// stay in their thread, so their replies remain reachable.
+func (c *Comment) IsDeleted() bool {
+	return c.DeletedAt != nil
+}
+
+// Score is the number of upvotes minus the number of downvotes.
+func (c *Comment) Score() int {
+	return c.Upvotes - c.Downvotes
+}
@@ -3,22 +3,26 @@
 package model
 
 import (
+	"fmt"
+	"io"
+	"strconv"
 	"time"
 )
 
+type SearchResult interface {
+	IsSearchResult()
+}
+
 type AuthRes struct {
-	User  *User  `json:"user"`
-	Token string `json:"token"`
+	User         *User  `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
 }
 
-type Comment struct {
-	ID              int                `json:"id"`
-	PostID          int                `json:"postID"`
-	UserID          int                `json:"userID"`
-	Body            string             `json:"body"`
-	CreatedAt       time.Time          `json:"createdAt"`
-	ParentCommentID *int               `json:"parentCommentID,omitempty"`
-	Replies         *CommentConnection `json:"replies,omitempty"`
+type Ban struct {
+	BannedAt time.Time  `json:"bannedAt"`
+	Until    *time.Time `json:"until,omitempty"`
+	Reason   string     `json:"reason"`
 }
 
 type CommentConnection struct {
@@ -31,6 +35,23 @@ type CommentEdge struct {
 	Node   *Comment `json:"node"`
 }
 
+type CommentRevision struct {
+	ID        int       `json:"id"`
+	CommentID int       `json:"commentID"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CommentRevisionConnection struct {
+	Edges    []*CommentRevisionEdge `json:"edges"`
+	PageInfo *PageInfo              `json:"pageInfo"`
+}
+
+type CommentRevisionEdge struct {
+	Cursor string           `json:"cursor"`
+	Node   *CommentRevision `json:"node"`
+}
+
 type CreateCommentReq struct {
 	PostID          int    `json:"postID"`
 	ParentCommentID *int   `json:"parentCommentID,omitempty"`
@@ -38,9 +59,11 @@ type CreateCommentReq struct {
 }
 
 type CreatePostReq struct {
-	Title         string `json:"title"`
-	Body          string `json:"body"`
-	AllowComments bool   `json:"allowComments"`
+	Title         string      `json:"title"`
+	Body          string      `json:"body"`
+	AllowComments bool        `json:"allowComments"`
+	Status        *PostStatus `json:"status,omitempty"`
+	PublishAt     *time.Time  `json:"publishAt,omitempty"`
 }
 
 type LoginReq struct {
@@ -48,6 +71,11 @@ type LoginReq struct {
 	Password string `json:"password"`
 }
 
+type ModeratePostReq struct {
+	IsLocked      *bool `json:"isLocked,omitempty"`
+	AllowComments *bool `json:"allowComments,omitempty"`
+}
+
 type Mutation struct {
 }
 
@@ -58,22 +86,431 @@ type PageInfo struct {
 	HasPrevPage bool    `json:"hasPrevPage"`
 }
 
+type PostConnection struct {
+	Edges      []*PostEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type PostEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Post  `json:"node"`
+}
+
+type PostRevision struct {
+	ID        int       `json:"id"`
+	PostID    int       `json:"postID"`
+	Title     string    `json:"title"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type PostRevisionConnection struct {
+	Edges    []*PostRevisionEdge `json:"edges"`
+	PageInfo *PageInfo           `json:"pageInfo"`
+}
+
+type PostRevisionEdge struct {
+	Cursor string        `json:"cursor"`
+	Node   *PostRevision `json:"node"`
+}
+
 type Query struct {
 }
 
+type ReactionSummary struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	Reacted bool   `json:"reacted"`
+}
+
 type RegisterReq struct {
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
 
+type ReportConnection struct {
+	Edges    []*ReportEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type ReportEdge struct {
+	Cursor string  `json:"cursor"`
+	Node   *Report `json:"node"`
+}
+
+type SearchConnection struct {
+	Edges    []*SearchEdge `json:"edges"`
+	PageInfo *PageInfo     `json:"pageInfo"`
+}
+
+type SearchEdge struct {
+	Cursor  string       `json:"cursor"`
+	Node    SearchResult `json:"node"`
+	Snippet string       `json:"snippet"`
+}
+
+type Subscription struct {
+}
+
 type UpdateCommentReq struct {
 	ID   int    `json:"id"`
 	Body string `json:"body"`
 }
 
 type UpdatePostReq struct {
-	Title         *string `json:"title,omitempty"`
-	Body          *string `json:"body,omitempty"`
-	AllowComments *bool   `json:"allowComments,omitempty"`
+	Title         *string     `json:"title,omitempty"`
+	Body          *string     `json:"body,omitempty"`
+	AllowComments *bool       `json:"allowComments,omitempty"`
+	Status        *PostStatus `json:"status,omitempty"`
+	PublishAt     *time.Time  `json:"publishAt,omitempty"`
+}
+
+type CommentOrder string
+
+const (
+	CommentOrderNewest         CommentOrder = "NEWEST"
+	CommentOrderOldest         CommentOrder = "OLDEST"
+	CommentOrderMostReplies    CommentOrder = "MOST_REPLIES"
+	CommentOrderRecentActivity CommentOrder = "RECENT_ACTIVITY"
+)
+
+var AllCommentOrder = []CommentOrder{
+	CommentOrderNewest,
+	CommentOrderOldest,
+	CommentOrderMostReplies,
+	CommentOrderRecentActivity,
+}
+
+func (e CommentOrder) IsValid() bool {
+	switch e {
+	case CommentOrderNewest, CommentOrderOldest, CommentOrderMostReplies, CommentOrderRecentActivity:
+		return true
+	}
+	return false
+}
+
+func (e CommentOrder) String() string {
+	return string(e)
+}
+
+func (e *CommentOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CommentOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CommentOrder", str)
+	}
+	return nil
+}
+
+func (e CommentOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PostOrder string
+
+const (
+	PostOrderNewest        PostOrder = "NEWEST"
+	PostOrderOldest        PostOrder = "OLDEST"
+	PostOrderMostCommented PostOrder = "MOST_COMMENTED"
+)
+
+var AllPostOrder = []PostOrder{
+	PostOrderNewest,
+	PostOrderOldest,
+	PostOrderMostCommented,
+}
+
+func (e PostOrder) IsValid() bool {
+	switch e {
+	case PostOrderNewest, PostOrderOldest, PostOrderMostCommented:
+		return true
+	}
+	return false
+}
+
+func (e PostOrder) String() string {
+	return string(e)
+}
+
+func (e *PostOrder) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostOrder(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostOrder", str)
+	}
+	return nil
+}
+
+func (e PostOrder) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type PostStatus string
+
+const (
+	PostStatusDraft     PostStatus = "DRAFT"
+	PostStatusPublished PostStatus = "PUBLISHED"
+)
+
+var AllPostStatus = []PostStatus{
+	PostStatusDraft,
+	PostStatusPublished,
+}
+
+func (e PostStatus) IsValid() bool {
+	switch e {
+	case PostStatusDraft, PostStatusPublished:
+		return true
+	}
+	return false
+}
+
+func (e PostStatus) String() string {
+	return string(e)
+}
+
+func (e *PostStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = PostStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid PostStatus", str)
+	}
+	return nil
+}
+
+func (e PostStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReportAction string
+
+const (
+	ReportActionDismiss   ReportAction = "DISMISS"
+	ReportActionHide      ReportAction = "HIDE"
+	ReportActionDelete    ReportAction = "DELETE"
+	ReportActionBanAuthor ReportAction = "BAN_AUTHOR"
+)
+
+var AllReportAction = []ReportAction{
+	ReportActionDismiss,
+	ReportActionHide,
+	ReportActionDelete,
+	ReportActionBanAuthor,
+}
+
+func (e ReportAction) IsValid() bool {
+	switch e {
+	case ReportActionDismiss, ReportActionHide, ReportActionDelete, ReportActionBanAuthor:
+		return true
+	}
+	return false
+}
+
+func (e ReportAction) String() string {
+	return string(e)
+}
+
+func (e *ReportAction) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportAction(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportAction", str)
+	}
+	return nil
+}
+
+func (e ReportAction) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReportReason string
+
+const (
+	ReportReasonSpam       ReportReason = "SPAM"
+	ReportReasonHarassment ReportReason = "HARASSMENT"
+	ReportReasonHateSpeech ReportReason = "HATE_SPEECH"
+	ReportReasonViolence   ReportReason = "VIOLENCE"
+	ReportReasonOther      ReportReason = "OTHER"
+)
+
+var AllReportReason = []ReportReason{
+	ReportReasonSpam,
+	ReportReasonHarassment,
+	ReportReasonHateSpeech,
+	ReportReasonViolence,
+	ReportReasonOther,
+}
+
+func (e ReportReason) IsValid() bool {
+	switch e {
+	case ReportReasonSpam, ReportReasonHarassment, ReportReasonHateSpeech, ReportReasonViolence, ReportReasonOther:
+		return true
+	}
+	return false
+}
+
+func (e ReportReason) String() string {
+	return string(e)
+}
+
+func (e *ReportReason) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportReason(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportReason", str)
+	}
+	return nil
+}
+
+func (e ReportReason) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusOpen     ReportStatus = "OPEN"
+	ReportStatusResolved ReportStatus = "RESOLVED"
+)
+
+var AllReportStatus = []ReportStatus{
+	ReportStatusOpen,
+	ReportStatusResolved,
+}
+
+func (e ReportStatus) IsValid() bool {
+	switch e {
+	case ReportStatusOpen, ReportStatusResolved:
+		return true
+	}
+	return false
+}
+
+func (e ReportStatus) String() string {
+	return string(e)
+}
+
+func (e *ReportStatus) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ReportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ReportStatus", str)
+	}
+	return nil
+}
+
+func (e ReportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type Role string
+
+const (
+	RoleUser      Role = "USER"
+	RoleModerator Role = "MODERATOR"
+	RoleAdmin     Role = "ADMIN"
+)
+
+var AllRole = []Role{
+	RoleUser,
+	RoleModerator,
+	RoleAdmin,
+}
+
+func (e Role) IsValid() bool {
+	switch e {
+	case RoleUser, RoleModerator, RoleAdmin:
+		return true
+	}
+	return false
+}
+
+func (e Role) String() string {
+	return string(e)
+}
+
+func (e *Role) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = Role(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid Role", str)
+	}
+	return nil
+}
+
+func (e Role) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type VoteValue string
+
+const (
+	VoteValueUp   VoteValue = "UP"
+	VoteValueDown VoteValue = "DOWN"
+	VoteValueNone VoteValue = "NONE"
+)
+
+var AllVoteValue = []VoteValue{
+	VoteValueUp,
+	VoteValueDown,
+	VoteValueNone,
+}
+
+func (e VoteValue) IsValid() bool {
+	switch e {
+	case VoteValueUp, VoteValueDown, VoteValueNone:
+		return true
+	}
+	return false
+}
+
+func (e VoteValue) String() string {
+	return string(e)
+}
+
+func (e *VoteValue) UnmarshalGQL(v any) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = VoteValue(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid VoteValue", str)
+	}
+	return nil
+}
+
+func (e VoteValue) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
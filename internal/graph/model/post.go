@@ -3,12 +3,37 @@ package model
 import "time"
 
 type Post struct {
-	ID            int                `json:"id"`
-	UserID        int                `json:"-"`
-	User          *User              `json:"user"`
-	Title         string             `json:"title"`
-	Body          string             `json:"body"`
-	AllowComments bool               `json:"allowComments"`
-	CreatedAt     time.Time          `json:"createdAt"`
-	Comments      *CommentConnection `json:"comments,omitempty"`
+	ID            int        `json:"id"`
+	UserID        int        `json:"-"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	AllowComments bool       `json:"allowComments"`
+	IsLocked      bool       `json:"isLocked"`
+	Status        PostStatus `json:"status"`
+	PublishAt     *time.Time `json:"publishAt"`
+	Upvotes       int        `json:"upvotes"`
+	Downvotes     int        `json:"downvotes"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     *time.Time `json:"updatedAt"`
+	DeletedAt     *time.Time `json:"-"`
+}
+
+// Score is the number of upvotes minus the number of downvotes.
+func (p *Post) Score() int {
+	return p.Upvotes - p.Downvotes
+}
+
+// InitialStatus returns the status a new post is saved with. Posts scheduled
+// with PublishAt stay drafts until then, other posts are published unless
+// they are saved as drafts.
+func (req *CreatePostReq) InitialStatus() PostStatus {
+	if req.Status != nil {
+		return *req.Status
+	}
+
+	if req.PublishAt != nil {
+		return PostStatusDraft
+	}
+
+	return PostStatusPublished
 }
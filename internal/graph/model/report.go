@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+type Report struct {
+	ID           int           `json:"id"`
+	ReporterID   int           `json:"-"`
+	PostID       *int          `json:"postID"`
+	CommentID    *int          `json:"commentID"`
+	Reason       ReportReason  `json:"reason"`
+	Note         *string       `json:"note"`
+	Status       ReportStatus  `json:"status"`
+	Action       *ReportAction `json:"action"`
+	ResolvedByID *int          `json:"-"`
+	ResolvedAt   *time.Time    `json:"resolvedAt"`
+	CreatedAt    time.Time     `json:"createdAt"`
+}
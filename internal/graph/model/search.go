@@ -0,0 +1,7 @@
+package model
+
+// IsSearchResult marks posts as results of search.
+func (Post) IsSearchResult() {}
+
+// IsSearchResult marks comments as results of search.
+func (Comment) IsSearchResult() {}
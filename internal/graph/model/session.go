@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Session struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userID"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	CreatedAt time.Time `json:"createdAt"`
+}
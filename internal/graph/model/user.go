@@ -1,8 +1,28 @@
 package model
 
+import "time"
+
 type User struct {
 	ID       int    `json:"id"`
 	Email    string `json:"email"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	Role     Role   `json:"role"`
+
+	// BannedAt is set while the user is banned, until BannedUntil or for
+	// good when BannedUntil is nil.
+	BannedAt    *time.Time `json:"-"`
+	BannedUntil *time.Time `json:"-"`
+	BanReason   string     `json:"-"`
+
+	// FailedLoginAttempts counts wrong passwords since the last successful
+	// login, from MaxFailedLogins on the account is locked until LockedUntil.
+	FailedLoginAttempts int        `json:"failedLoginAttempts"`
+	LockedUntil         *time.Time `json:"lockedUntil"`
+}
+
+// IsBanned reports whether the ban of the user still lasts at the given
+// time.
+func (u *User) IsBanned(now time.Time) bool {
+	return u.BannedAt != nil && (u.BannedUntil == nil || u.BannedUntil.After(now))
 }
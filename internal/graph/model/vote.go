@@ -0,0 +1,42 @@
+package model
+
+// Number returns the value a vote is stored with: 1 for UP, -1 for DOWN and
+// 0 for NONE.
+func (v VoteValue) Number() int {
+	switch v {
+	case VoteValueUp:
+		return 1
+	case VoteValueDown:
+		return -1
+	default:
+		return 0
+	}
+}
+
+// VoteValueOf is the inverse of VoteValue.Number.
+func VoteValueOf(n int) VoteValue {
+	switch {
+	case n > 0:
+		return VoteValueUp
+	case n < 0:
+		return VoteValueDown
+	default:
+		return VoteValueNone
+	}
+}
+
+// VoteDelta returns how the upvote and downvote counters change when a vote
+// is replaced by another one.
+func VoteDelta(previous, value VoteValue) (upvotes, downvotes int) {
+	count := func(v, want VoteValue) int {
+		if v == want {
+			return 1
+		}
+		return 0
+	}
+
+	upvotes = count(value, VoteValueUp) - count(previous, VoteValueUp)
+	downvotes = count(value, VoteValueDown) - count(previous, VoteValueDown)
+
+	return upvotes, downvotes
+}
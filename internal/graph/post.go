@@ -0,0 +1,17 @@
+package graph
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"time"
+)
+
+// validatePostSchedule rejects publishAt for posts that are published right
+// away, only drafts can be scheduled.
+func validatePostSchedule(status *model.PostStatus, publishAt *time.Time) error {
+	if publishAt != nil && status != nil && *status == model.PostStatusPublished {
+		return apperror.Validation("publishAt can only be set for drafts")
+	}
+
+	return nil
+}
@@ -0,0 +1,26 @@
+package graph
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+)
+
+// validateReportNote makes sure the optional note of a report fits the
+// reports table.
+func validateReportNote(note *string) error {
+	if note != nil && len(*note) > 500 {
+		return apperror.Validation("note must be less than 500 chars")
+	}
+
+	return nil
+}
+
+// reportStatus returns the requested status of the moderation queue, open
+// reports when none is given.
+func reportStatus(status *model.ReportStatus) model.ReportStatus {
+	if status == nil {
+		return model.ReportStatusOpen
+	}
+
+	return *status
+}
@@ -1,9 +1,18 @@
 package graph
 
 import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
 	"github.com/aaanger/graphql-test/internal/repository/comment"
 	"github.com/aaanger/graphql-test/internal/repository/post"
+	"github.com/aaanger/graphql-test/internal/repository/reaction"
+	"github.com/aaanger/graphql-test/internal/repository/report"
+	"github.com/aaanger/graphql-test/internal/repository/search"
+	"github.com/aaanger/graphql-test/internal/repository/session"
 	"github.com/aaanger/graphql-test/internal/repository/user"
+	"github.com/aaanger/graphql-test/internal/repository/vote"
+	"github.com/aaanger/graphql-test/pkg/jwt"
+	"github.com/aaanger/graphql-test/pkg/pubsub"
+	"time"
 )
 
 // This file will not be regenerated automatically.
@@ -11,7 +20,24 @@ import (
 // It serves as dependency injection for your app, add any dependencies you require here.
 
 type Resolver struct {
-	UserRepo    user.IUserRepository
-	PostRepo    post.IPostRepository
-	CommentRepo comment.ICommentRepository
+	UserRepo     user.IUserRepository
+	PostRepo     post.IPostRepository
+	CommentRepo  comment.ICommentRepository
+	SessionRepo  session.ISessionRepository
+	VoteRepo     vote.IVoteRepository
+	ReactionRepo reaction.IReactionRepository
+	SearchRepo   search.ISearchRepository
+	ReportRepo   report.IReportRepository
+	Tokens       *jwt.Manager
+
+	// CommentHub delivers newly created comments to commentAdded
+	// subscribers, keyed by post ID.
+	CommentHub *pubsub.Hub[int, *model.Comment]
+
+	// PostRestoreWindow is how long after deletion the author can still
+	// restore a post.
+	PostRestoreWindow time.Duration
+
+	// Reactions lists the emoji comments can be reacted with.
+	Reactions []string
 }
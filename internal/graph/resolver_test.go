@@ -4,46 +4,84 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"github.com/aaanger/graphql-test/internal/graph/loaders"
 	model2 "github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
 	commentMocks "github.com/aaanger/graphql-test/internal/repository/comment/mocks"
 	postMocks "github.com/aaanger/graphql-test/internal/repository/post/mocks"
+	reactionMocks "github.com/aaanger/graphql-test/internal/repository/reaction/mocks"
+	reportMocks "github.com/aaanger/graphql-test/internal/repository/report/mocks"
+	searchMocks "github.com/aaanger/graphql-test/internal/repository/search/mocks"
+	"github.com/aaanger/graphql-test/internal/repository/session"
+	sessionMocks "github.com/aaanger/graphql-test/internal/repository/session/mocks"
 	userMocks "github.com/aaanger/graphql-test/internal/repository/user/mocks"
+	voteMocks "github.com/aaanger/graphql-test/internal/repository/vote/mocks"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"github.com/aaanger/graphql-test/pkg/jwt"
+	"github.com/aaanger/graphql-test/pkg/pubsub"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"math/rand"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
 
 type SchemaResolverSuite struct {
 	suite.Suite
-	userMock         *userMocks.IUserRepository
-	postMock         *postMocks.IPostRepository
-	commentMock      *commentMocks.ICommentRepository
-	mutationResolver MutationResolver
-	queryResolver    QueryResolver
+	userMock             *userMocks.IUserRepository
+	postMock             *postMocks.IPostRepository
+	commentMock          *commentMocks.ICommentRepository
+	sessionMock          *sessionMocks.ISessionRepository
+	voteMock             *voteMocks.IVoteRepository
+	reactionMock         *reactionMocks.IReactionRepository
+	searchMock           *searchMocks.ISearchRepository
+	reportMock           *reportMocks.IReportRepository
+	resolver             *Resolver
+	mutationResolver     MutationResolver
+	queryResolver        QueryResolver
+	subscriptionResolver SubscriptionResolver
 }
 
 func (suite *SchemaResolverSuite) SetupTest() {
+	tokens, err := jwt.NewManager(jwt.NewHMACKey("test", []byte("secret")))
+	suite.Require().NoError(err)
+
 	suite.userMock = userMocks.NewIUserRepository(suite.T())
 	suite.postMock = postMocks.NewIPostRepository(suite.T())
 	suite.commentMock = commentMocks.NewICommentRepository(suite.T())
+	suite.sessionMock = sessionMocks.NewISessionRepository(suite.T())
+	suite.voteMock = voteMocks.NewIVoteRepository(suite.T())
+	suite.reactionMock = reactionMocks.NewIReactionRepository(suite.T())
+	suite.searchMock = searchMocks.NewISearchRepository(suite.T())
+	suite.reportMock = reportMocks.NewIReportRepository(suite.T())
+
+	suite.resolver = &Resolver{
+		UserRepo:     suite.userMock,
+		PostRepo:     suite.postMock,
+		CommentRepo:  suite.commentMock,
+		SessionRepo:  suite.sessionMock,
+		VoteRepo:     suite.voteMock,
+		ReactionRepo: suite.reactionMock,
+		SearchRepo:   suite.searchMock,
+		ReportRepo:   suite.reportMock,
+		Tokens:       tokens,
+		CommentHub:   pubsub.NewHub[int, *model2.Comment](1),
+		Reactions:    []string{"👍", "🎉"},
+	}
 
 	suite.mutationResolver = &mutationResolver{
-		Resolver: &Resolver{
-			UserRepo:    suite.userMock,
-			PostRepo:    suite.postMock,
-			CommentRepo: suite.commentMock,
-		},
+		Resolver: suite.resolver,
 	}
 
 	suite.queryResolver = &queryResolver{
-		Resolver: &Resolver{
-			UserRepo:    suite.userMock,
-			PostRepo:    suite.postMock,
-			CommentRepo: suite.commentMock,
-		},
+		Resolver: suite.resolver,
+	}
+
+	suite.subscriptionResolver = &subscriptionResolver{
+		Resolver: suite.resolver,
 	}
 }
 
@@ -65,12 +103,15 @@ func (suite *SchemaResolverSuite) TestResolver_RegisterSuccess() {
 		Email:    "test",
 		Username: "test",
 		Password: "test",
-	}, "token", nil)
+	}, nil)
+	suite.sessionMock.On("CreateSession", mock.Anything, 1, mock.Anything, mock.Anything).
+		Return(&model2.Session{ID: 7, UserID: 1}, nil)
 
 	res, err := suite.mutationResolver.Register(context.Background(), req)
 
 	suite.NotNil(res.User)
 	suite.NotEmpty(res.Token)
+	suite.NotEmpty(res.RefreshToken)
 	suite.Nil(err)
 }
 
@@ -81,7 +122,7 @@ func (suite *SchemaResolverSuite) TestResolver_RegisterFailure() {
 		Password: "test",
 	}
 
-	suite.userMock.On("Register", mock.Anything, &req).Return(nil, "", errors.New("error"))
+	suite.userMock.On("Register", mock.Anything, &req).Return(nil, errors.New("error"))
 
 	res, err := suite.mutationResolver.Register(context.Background(), req)
 
@@ -95,17 +136,20 @@ func (suite *SchemaResolverSuite) TestResolver_LoginSuccess() {
 		Password: "test",
 	}
 
-	suite.userMock.On("Login", mock.Anything, &req).Return(&model2.User{
+	suite.userMock.On("Login", mock.Anything, &req, "").Return(&model2.User{
 		ID:       1,
 		Email:    "test",
 		Username: "test",
 		Password: "test",
-	}, "token", nil)
+	}, nil)
+	suite.sessionMock.On("CreateSession", mock.Anything, 1, mock.Anything, mock.Anything).
+		Return(&model2.Session{ID: 7, UserID: 1}, nil)
 
 	res, err := suite.mutationResolver.Login(context.Background(), req)
 
 	suite.NotNil(res.User)
 	suite.NotEmpty(res.Token)
+	suite.NotEmpty(res.RefreshToken)
 	suite.Nil(err)
 }
 
@@ -115,7 +159,7 @@ func (suite *SchemaResolverSuite) TestResolver_LoginFailure() {
 		Password: "test",
 	}
 
-	suite.userMock.On("Login", mock.Anything, &req).Return(nil, "", errors.New("error"))
+	suite.userMock.On("Login", mock.Anything, &req, "").Return(nil, errors.New("error"))
 
 	res, err := suite.mutationResolver.Login(context.Background(), req)
 
@@ -123,6 +167,83 @@ func (suite *SchemaResolverSuite) TestResolver_LoginFailure() {
 	suite.NotNil(err)
 }
 
+func (suite *SchemaResolverSuite) TestResolver_LoginTokenCarriesSession() {
+	req := model2.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	suite.userMock.On("Login", mock.Anything, &req, "").Return(&model2.User{ID: 1}, nil)
+	suite.sessionMock.On("CreateSession", mock.Anything, 1, mock.Anything, mock.Anything).
+		Return(&model2.Session{ID: 7, UserID: 1}, nil)
+
+	res, err := suite.mutationResolver.Login(context.Background(), req)
+	suite.Nil(err)
+
+	claims, err := suite.resolver.Tokens.ParseToken(res.Token)
+	suite.Nil(err)
+	suite.Equal(1, claims.UserID)
+	suite.Equal(7, claims.SessionID)
+	suite.sessionMock.AssertCalled(suite.T(), "CreateSession", mock.Anything, 1, jwt.HashRefreshToken(res.RefreshToken), mock.Anything)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RefreshTokenSuccess() {
+	suite.sessionMock.On("RotateSession", mock.Anything, jwt.HashRefreshToken("old"), mock.Anything, mock.Anything).
+		Return(&model2.Session{ID: 7, UserID: 1}, nil)
+	suite.userMock.On("GetUsersByIDs", mock.Anything, []int{1}).
+		Return([]*model2.User{{ID: 1, Username: "test", Role: model2.RoleModerator}}, nil)
+
+	res, err := suite.mutationResolver.RefreshToken(context.Background(), "old")
+
+	suite.Nil(err)
+	suite.Equal("test", res.User.Username)
+	suite.NotEqual("old", res.RefreshToken)
+
+	claims, err := suite.resolver.Tokens.ParseToken(res.Token)
+	suite.Nil(err)
+	suite.Equal(7, claims.SessionID)
+	suite.Equal("MODERATOR", claims.Role)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RefreshTokenInvalid() {
+	suite.sessionMock.On("RotateSession", mock.Anything, jwt.HashRefreshToken("old"), mock.Anything, mock.Anything).
+		Return(nil, session.ErrInvalidRefreshToken)
+
+	res, err := suite.mutationResolver.RefreshToken(context.Background(), "old")
+
+	suite.Nil(res)
+	suite.ErrorIs(err, session.ErrInvalidRefreshToken)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_LogoutSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "sessionID", 7)
+
+	suite.sessionMock.On("RevokeSession", mock.Anything, 1, 7).Return(nil)
+
+	ok, err := suite.mutationResolver.Logout(ctx)
+
+	suite.True(ok)
+	suite.Nil(err)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_LogoutUnauthorized() {
+	ok, err := suite.mutationResolver.Logout(context.Background())
+
+	suite.False(ok)
+	suite.NotNil(err)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_LogoutAllSessionsSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.sessionMock.On("RevokeAllSessions", mock.Anything, 1).Return(nil)
+
+	ok, err := suite.mutationResolver.LogoutAllSessions(ctx)
+
+	suite.True(ok)
+	suite.Nil(err)
+}
+
 // ===============================================================
 
 func (suite *SchemaResolverSuite) TestResolver_CreatePostSuccess() {
@@ -134,12 +255,11 @@ func (suite *SchemaResolverSuite) TestResolver_CreatePostSuccess() {
 
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.postMock.On("CreatePost", ctx, 1, &req).
 		Return(&model2.Post{
-			ID: 1,
-			User: &model2.User{
-				ID: 1,
-			},
+			ID:            1,
+			UserID:        1,
 			Title:         req.Title,
 			Body:          req.Body,
 			AllowComments: req.AllowComments,
@@ -174,6 +294,7 @@ func (suite *SchemaResolverSuite) TestResolver_CreatePostFailure() {
 
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.postMock.On("CreatePost", ctx, 1, &req).
 		Return(nil, errors.New("error"))
 
@@ -194,15 +315,10 @@ func (suite *SchemaResolverSuite) TestResolver_UpdatePostSuccess() {
 
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
-	suite.postMock.On("UpdatePost", ctx, 1, 1, &req).
-		Return(nil)
-
-	suite.postMock.On("GetPostByID", ctx, 1).
+	suite.postMock.On("UpdatePost", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1, &req).
 		Return(&model2.Post{
-			ID: 1,
-			User: &model2.User{
-				ID: 1,
-			},
+			ID:            1,
+			UserID:        1,
 			Title:         "test",
 			Body:          "test",
 			AllowComments: true,
@@ -237,13 +353,46 @@ func (suite *SchemaResolverSuite) TestResolver_UpdatePostFailure() {
 
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
-	suite.postMock.On("UpdatePost", ctx, 1, 1, &req).
-		Return(errors.New("error"))
+	suite.postMock.On("UpdatePost", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1, &req).
+		Return(nil, apperror.Forbidden("post belongs to another user"))
 
 	res, err := suite.mutationResolver.UpdatePost(ctx, 1, req)
 
 	suite.Nil(res)
-	suite.NotNil(err)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+// ==================================================================
+
+func (suite *SchemaResolverSuite) TestResolver_RevertPostSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.postMock.On("RevertPost", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1, 2).
+		Return(&model2.Post{ID: 1, UserID: 1, Title: "old", Body: "old"}, nil)
+
+	post, err := suite.mutationResolver.RevertPost(ctx, 1, 2)
+
+	suite.Nil(err)
+	suite.Equal("old", post.Title)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RevertPostUnauthorized() {
+	post, err := suite.mutationResolver.RevertPost(context.Background(), 1, 2)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeUnauthenticated, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RevertPostRevisionNotFound() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.postMock.On("RevertPost", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1, 2).
+		Return(nil, apperror.NotFound("revision not found"))
+
+	post, err := suite.mutationResolver.RevertPost(ctx, 1, 2)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
 }
 
 // ========================================================
@@ -262,7 +411,7 @@ func (suite *SchemaResolverSuite) TestResolver_DeletePostSuccess() {
 func (suite *SchemaResolverSuite) TestResolver_DeletePostUnauthorized() {
 	status, err := suite.mutationResolver.DeletePost(context.Background(), 1)
 
-	suite.Equal("Unauthorized", status)
+	suite.Empty(status)
 	suite.NotNil(err)
 }
 
@@ -273,22 +422,111 @@ func (suite *SchemaResolverSuite) TestResolver_DeletePostFailure() {
 
 	status, err := suite.mutationResolver.DeletePost(ctx, 1)
 
-	suite.Equal("Failed to delete post", status)
+	suite.Empty(status)
 	suite.NotNil(err)
 }
 
 // ==============================================================
 
+func (suite *SchemaResolverSuite) TestResolver_RestorePostWithinWindow() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	suite.resolver.PostRestoreWindow = time.Hour
+
+	suite.postMock.On("RestorePost", ctx, 1, 1, mock.MatchedBy(func(deletedAfter time.Time) bool {
+		return time.Since(deletedAfter) >= time.Hour && time.Since(deletedAfter) < time.Hour+time.Minute
+	})).Return(&model2.Post{ID: 1, UserID: 1}, nil)
+
+	post, err := suite.mutationResolver.RestorePost(ctx, 1)
+
+	suite.Nil(err)
+	suite.Equal(1, post.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RestorePostCutoffInUTC() {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	defer func() { time.Local = local }()
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	suite.resolver.PostRestoreWindow = time.Hour
+
+	suite.postMock.On("RestorePost", ctx, 1, 1, mock.MatchedBy(func(deletedAfter time.Time) bool {
+		return deletedAfter.Location() == time.UTC
+	})).Return(&model2.Post{ID: 1, UserID: 1}, nil)
+
+	_, err := suite.mutationResolver.RestorePost(ctx, 1)
+
+	suite.Nil(err)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RestorePostUnauthorized() {
+	post, err := suite.mutationResolver.RestorePost(context.Background(), 1)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeUnauthenticated, apperror.CodeOf(err))
+}
+
+// ==============================================================
+
+func (suite *SchemaResolverSuite) TestResolver_CreatePostPublishedWithPublishAt() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	published := model2.PostStatusPublished
+	publishAt := time.Now().Add(time.Hour)
+
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
+
+	post, err := suite.mutationResolver.CreatePost(ctx, model2.CreatePostReq{
+		Title:     "test",
+		Body:      "test",
+		Status:    &published,
+		PublishAt: &publishAt,
+	})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PublishPostSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.postMock.On("PublishPost", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1).
+		Return(&model2.Post{ID: 1, UserID: 1, Status: model2.PostStatusPublished}, nil)
+
+	post, err := suite.mutationResolver.PublishPost(ctx, 1)
+
+	suite.Nil(err)
+	suite.Equal(model2.PostStatusPublished, post.Status)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_MyDraftsSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.postMock.On("GetDraftsByUserID", ctx, 1).
+		Return([]*model2.Post{{ID: 1, UserID: 1, Status: model2.PostStatusDraft}}, nil)
+
+	posts, err := suite.queryResolver.MyDrafts(ctx)
+
+	suite.Nil(err)
+	suite.Len(posts, 1)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_MyDraftsUnauthorized() {
+	posts, err := suite.queryResolver.MyDrafts(context.Background())
+
+	suite.Nil(posts)
+	suite.Equal(apperror.CodeUnauthenticated, apperror.CodeOf(err))
+}
+
+// ==============================================================
+
 func (suite *SchemaResolverSuite) TestResolver_GetPostsByUserIDSuccess() {
 	post1 := &model2.Post{
 		ID:            1,
 		Title:         "test1",
 		Body:          "test1",
 		AllowComments: true,
-		User: &model2.User{
-			ID:       5,
-			Username: "test",
-		},
+		UserID:        5,
 	}
 
 	post2 := &model2.Post{
@@ -296,28 +534,23 @@ func (suite *SchemaResolverSuite) TestResolver_GetPostsByUserIDSuccess() {
 		Title:         "test2",
 		Body:          "test2",
 		AllowComments: true,
-		User: &model2.User{
-			ID:       5,
-			Username: "test",
-		},
+		UserID:        5,
 	}
 
 	suite.postMock.On("GetAllPostsByUserID", mock.Anything, 5).
 		Return([]*model2.Post{post1, post2}, nil)
 
-	posts, err := suite.queryResolver.GetPosts(context.Background(), 5)
+	posts, err := suite.queryResolver.GetPostsByUserID(context.Background(), 5)
 
 	suite.NotNil(posts)
 
 	suite.Equal("test1", posts[0].Title)
 	suite.Equal("test1", posts[0].Body)
-	suite.Equal(5, posts[0].User.ID)
-	suite.Equal("test", posts[0].User.Username)
+	suite.Equal(5, posts[0].UserID)
 
 	suite.Equal("test2", posts[1].Title)
 	suite.Equal("test2", posts[1].Body)
-	suite.Equal(5, posts[1].User.ID)
-	suite.Equal("test", posts[1].User.Username)
+	suite.Equal(5, posts[1].UserID)
 
 	suite.Nil(err)
 }
@@ -326,7 +559,39 @@ func (suite *SchemaResolverSuite) TestResolver_GetPostsByUserIDFailure() {
 	suite.postMock.On("GetAllPostsByUserID", mock.Anything, 5).
 		Return(nil, errors.New("error"))
 
-	posts, err := suite.queryResolver.GetPosts(context.Background(), 5)
+	posts, err := suite.queryResolver.GetPostsByUserID(context.Background(), 5)
+
+	suite.Nil(posts)
+	suite.NotNil(err)
+}
+
+// ==============================================================
+
+func (suite *SchemaResolverSuite) TestResolver_PostsDefaultOrder() {
+	first := 10
+	connection := &model2.PostConnection{
+		Edges:      []*model2.PostEdge{{Cursor: "c1", Node: &model2.Post{ID: 1}}},
+		PageInfo:   &model2.PageInfo{},
+		TotalCount: 1,
+	}
+
+	suite.postMock.On("GetPosts", mock.Anything, &first, (*int)(nil), (*string)(nil), (*string)(nil), model2.PostOrderNewest).
+		Return(connection, nil)
+
+	posts, err := suite.queryResolver.Posts(context.Background(), &first, nil, nil, nil, nil)
+
+	suite.Nil(err)
+	suite.Equal(1, posts.TotalCount)
+	suite.Equal(1, posts.Edges[0].Node.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PostsFailure() {
+	orderBy := model2.PostOrderMostCommented
+
+	suite.postMock.On("GetPosts", mock.Anything, (*int)(nil), (*int)(nil), (*string)(nil), (*string)(nil), orderBy).
+		Return(nil, errors.New("error"))
+
+	posts, err := suite.queryResolver.Posts(context.Background(), nil, nil, nil, nil, &orderBy)
 
 	suite.Nil(posts)
 	suite.NotNil(err)
@@ -341,10 +606,7 @@ func (suite *SchemaResolverSuite) TestResolver_GetPostByIDSuccess() {
 			Title:         "test1",
 			Body:          "test1",
 			AllowComments: true,
-			User: &model2.User{
-				ID:       5,
-				Username: "test",
-			},
+			UserID:        5,
 		}, nil)
 
 	post, err := suite.queryResolver.GetPostByID(context.Background(), 1)
@@ -381,6 +643,7 @@ func (suite *SchemaResolverSuite) TestResolver_CreateCommentSuccess() {
 		Body:            "test",
 	}
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.commentMock.On("IsCommentsAllowed", ctx, 1).Return(true, nil)
 	suite.commentMock.On("CreateComment", ctx, 1, &req).
 		Return(&model2.Comment{
@@ -397,6 +660,19 @@ func (suite *SchemaResolverSuite) TestResolver_CreateCommentSuccess() {
 	suite.Nil(err)
 }
 
+func (suite *SchemaResolverSuite) TestResolver_CreateCommentPostNotFound() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
+	suite.commentMock.On("IsCommentsAllowed", mock.Anything, 10).
+		Return(false, apperror.NotFound("post not found"))
+
+	comment, err := suite.mutationResolver.CreateComment(ctx, model2.CreateCommentReq{PostID: 10, Body: "test"})
+
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
 func (suite *SchemaResolverSuite) TestResolver_CreateCommentUnauthorized() {
 	req := model2.CreateCommentReq{
 		PostID:          1,
@@ -419,6 +695,7 @@ func (suite *SchemaResolverSuite) TestResolver_CreateCommentNotAllowed() {
 		Body:            "test",
 	}
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.commentMock.On("IsCommentsAllowed", ctx, 1).Return(false, nil)
 
 	comment, err := suite.mutationResolver.CreateComment(ctx, req)
@@ -436,6 +713,7 @@ func (suite *SchemaResolverSuite) TestResolver_CreateCommentMoreThan2000Chars()
 		Body:            generateStringWith2000Chars(),
 	}
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.commentMock.On("IsCommentsAllowed", ctx, 1).Return(true, nil)
 
 	comment, err := suite.mutationResolver.CreateComment(ctx, req)
@@ -453,6 +731,7 @@ func (suite *SchemaResolverSuite) TestResolver_CreateCommentFailure() {
 		Body:            "test",
 	}
 
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
 	suite.commentMock.On("IsCommentsAllowed", ctx, 1).Return(true, nil)
 	suite.commentMock.On("CreateComment", ctx, 1, &req).
 		Return(nil, errors.New("error"))
@@ -473,9 +752,7 @@ func (suite *SchemaResolverSuite) TestResolver_UpdateCommentSuccess() {
 		Body: "test",
 	}
 
-	suite.commentMock.On("UpdateComment", ctx, 1, &req).Return(nil)
-
-	suite.commentMock.On("GetCommentByID", ctx, 1).
+	suite.commentMock.On("UpdateComment", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, &req).
 		Return(&model2.Comment{
 			ID:        1,
 			PostID:    1,
@@ -524,7 +801,7 @@ func (suite *SchemaResolverSuite) TestResolver_UpdateCommentFailure() {
 		Body: "test",
 	}
 
-	suite.commentMock.On("UpdateComment", ctx, 1, &req).Return(errors.New("error"))
+	suite.commentMock.On("UpdateComment", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, &req).Return(nil, errors.New("error"))
 
 	comment, err := suite.mutationResolver.UpdateComment(ctx, req)
 
@@ -532,7 +809,7 @@ func (suite *SchemaResolverSuite) TestResolver_UpdateCommentFailure() {
 	suite.NotNil(err)
 }
 
-func (suite *SchemaResolverSuite) TestResolver_UpdateCommentGetCommentFailure() {
+func (suite *SchemaResolverSuite) TestResolver_UpdateCommentNotFound() {
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
 	req := model2.UpdateCommentReq{
@@ -540,15 +817,12 @@ func (suite *SchemaResolverSuite) TestResolver_UpdateCommentGetCommentFailure()
 		Body: "test",
 	}
 
-	suite.commentMock.On("UpdateComment", ctx, 1, &req).Return(nil)
-
-	suite.commentMock.On("GetCommentByID", ctx, 1).
-		Return(nil, errors.New("error"))
+	suite.commentMock.On("UpdateComment", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, &req).Return(nil, apperror.NotFound("comment not found"))
 
 	comment, err := suite.mutationResolver.UpdateComment(ctx, req)
 
 	suite.Nil(comment)
-	suite.NotNil(err)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
 }
 
 // =================================================================
@@ -556,7 +830,7 @@ func (suite *SchemaResolverSuite) TestResolver_UpdateCommentGetCommentFailure()
 func (suite *SchemaResolverSuite) TestResolver_DeleteCommentSuccess() {
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
-	suite.commentMock.On("DeleteComment", ctx, 1, 1).Return(nil)
+	suite.commentMock.On("DeleteComment", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1).Return(nil)
 
 	status, err := suite.mutationResolver.DeleteComment(ctx, 1)
 
@@ -567,21 +841,290 @@ func (suite *SchemaResolverSuite) TestResolver_DeleteCommentSuccess() {
 func (suite *SchemaResolverSuite) TestResolver_DeleteCommentUnauthorized() {
 	status, err := suite.mutationResolver.DeleteComment(context.Background(), 1)
 
-	suite.Equal("Unauthorized", status)
+	suite.Empty(status)
 	suite.NotNil(err)
 }
 
 func (suite *SchemaResolverSuite) TestResolver_DeleteCommentFailure() {
 	ctx := context.WithValue(context.Background(), "userID", 1)
 
-	suite.commentMock.On("DeleteComment", ctx, 1, 1).Return(errors.New("error"))
+	suite.commentMock.On("DeleteComment", ctx, policy.Actor{UserID: 1, Role: model2.RoleUser}, 1).Return(errors.New("error"))
 
 	status, err := suite.mutationResolver.DeleteComment(ctx, 1)
 
-	suite.Equal("Failed to delete comment", status)
+	suite.Empty(status)
 	suite.NotNil(err)
 }
 
+func (suite *SchemaResolverSuite) TestResolver_PurgeCommentSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.commentMock.On("PurgeComment", ctx, 1).Return(nil)
+
+	ok, err := suite.mutationResolver.PurgeComment(ctx, 1)
+
+	suite.Nil(err)
+	suite.True(ok)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PurgeCommentNotFound() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.commentMock.On("PurgeComment", ctx, 1).Return(apperror.NotFound("comment not found"))
+
+	ok, err := suite.mutationResolver.PurgeComment(ctx, 1)
+
+	suite.False(ok)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_DeleteCommentAsModerator() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 2), "role", "MODERATOR")
+
+	suite.commentMock.On("DeleteComment", ctx, policy.Actor{UserID: 2, Role: model2.RoleModerator}, 1).Return(nil)
+
+	status, err := suite.mutationResolver.DeleteComment(ctx, 1)
+
+	suite.Nil(err)
+	suite.Equal("Deleted comment", status)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ModeratePostSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 2), "role", "MODERATOR")
+	locked := true
+	req := model2.ModeratePostReq{IsLocked: &locked}
+
+	suite.postMock.On("ModeratePost", ctx, 1, &req).Return(&model2.Post{ID: 1, UserID: 1, IsLocked: true}, nil)
+
+	post, err := suite.mutationResolver.ModeratePost(ctx, 1, req)
+
+	suite.Nil(err)
+	suite.True(post.IsLocked)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_GrantRoleSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	suite.userMock.On("SetRole", ctx, 2, model2.RoleModerator).Return(&model2.User{ID: 2, Role: model2.RoleModerator}, nil)
+
+	user, err := suite.mutationResolver.GrantRole(ctx, 2, model2.RoleModerator)
+
+	suite.Nil(err)
+	suite.Equal(model2.RoleModerator, user.Role)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_GrantRoleToSelf() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	user, err := suite.mutationResolver.GrantRole(ctx, 1, model2.RoleUser)
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RevokeRoleEndsSessions() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	suite.userMock.On("SetRole", ctx, 2, model2.RoleUser).Return(&model2.User{ID: 2, Role: model2.RoleUser}, nil)
+	suite.sessionMock.On("RevokeAllSessions", ctx, 2).Return(nil)
+
+	user, err := suite.mutationResolver.RevokeRole(ctx, 2)
+
+	suite.Nil(err)
+	suite.Equal(model2.RoleUser, user.Role)
+	suite.sessionMock.AssertCalled(suite.T(), "RevokeAllSessions", ctx, 2)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ReportPostSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+	note := "spam links"
+
+	suite.reportMock.On("ReportPost", ctx, 2, 1, model2.ReportReasonSpam, &note).
+		Return(&model2.Report{ID: 1, ReporterID: 2, Reason: model2.ReportReasonSpam, Status: model2.ReportStatusOpen}, nil)
+
+	report, err := suite.mutationResolver.ReportPost(ctx, 1, model2.ReportReasonSpam, &note)
+
+	suite.Nil(err)
+	suite.Equal(model2.ReportStatusOpen, report.Status)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ReportCommentNoteTooLong() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+	note := strings.Repeat("a", 501)
+
+	report, err := suite.mutationResolver.ReportComment(ctx, 1, model2.ReportReasonOther, &note)
+
+	suite.Nil(report)
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ResolveReportSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 3), "role", "MODERATOR")
+	action := model2.ReportActionHide
+	resolvedBy := 3
+
+	suite.reportMock.On("ResolveReport", ctx, policy.Actor{UserID: 3, Role: model2.RoleModerator}, 1, action).
+		Return(&model2.Report{ID: 1, Status: model2.ReportStatusResolved, Action: &action, ResolvedByID: &resolvedBy}, nil)
+
+	report, err := suite.mutationResolver.ResolveReport(ctx, 1, action)
+
+	suite.Nil(err)
+	suite.Equal(model2.ReportStatusResolved, report.Status)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ModerationQueueDefaultsToOpen() {
+	suite.reportMock.On("GetReports", mock.Anything, model2.ReportStatusOpen, (*int)(nil), (*string)(nil)).
+		Return(&model2.ReportConnection{Edges: []*model2.ReportEdge{{Cursor: "c", Node: &model2.Report{ID: 1}}}, PageInfo: &model2.PageInfo{}}, nil)
+
+	reports, err := suite.queryResolver.ModerationQueue(context.Background(), nil, nil, nil)
+
+	suite.Nil(err)
+	suite.Len(reports.Edges, 1)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ReportPostHidden() {
+	postID := 1
+
+	suite.postMock.On("GetPostByID", mock.Anything, 1).Return(nil, apperror.NotFound("post not found"))
+
+	post, err := suite.resolver.Report().Post(context.Background(), &model2.Report{ID: 1, PostID: &postID})
+	suite.Nil(err)
+	suite.Nil(post)
+
+	comment, err := suite.resolver.Report().Comment(context.Background(), &model2.Report{ID: 1, PostID: &postID})
+	suite.Nil(err)
+	suite.Nil(comment)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_ReportResolvedByUnresolved() {
+	user, err := suite.resolver.Report().ResolvedBy(context.Background(), &model2.Report{ID: 1})
+
+	suite.Nil(err)
+	suite.Nil(user)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_BanUserSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+	until := time.Now().Add(24 * time.Hour).UTC()
+	bannedAt := time.Now()
+
+	suite.userMock.On("GetUsersByIDs", ctx, []int{2}).Return([]*model2.User{{ID: 2, Role: model2.RoleModerator}}, nil)
+	suite.userMock.On("BanUser", ctx, 2, &until, "spam").
+		Return(&model2.User{ID: 2, BannedAt: &bannedAt, BannedUntil: &until, BanReason: "spam"}, nil)
+
+	// the ban ends at the same instant whatever offset the client sent
+	local := until.In(time.FixedZone("UTC+3", 3*60*60))
+	user, err := suite.mutationResolver.BanUser(ctx, 2, &local, "  spam ")
+	suite.Require().NoError(err)
+
+	ban, err := suite.resolver.User().Ban(ctx, user)
+	suite.Nil(err)
+	suite.Equal(&model2.Ban{BannedAt: bannedAt, Until: &until, Reason: "spam"}, ban)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_BanUserValidation() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+	past := time.Now().Add(-time.Hour)
+
+	suite.userMock.On("GetUsersByIDs", ctx, []int{1}).Return([]*model2.User{{ID: 1, Role: model2.RoleAdmin}}, nil)
+	suite.userMock.On("GetUsersByIDs", ctx, []int{2}).Return([]*model2.User{{ID: 2, Role: model2.RoleUser}}, nil)
+
+	_, err := suite.mutationResolver.BanUser(ctx, 2, nil, "   ")
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+
+	_, err = suite.mutationResolver.BanUser(ctx, 2, &past, "spam")
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+
+	_, err = suite.mutationResolver.BanUser(ctx, 1, nil, "spam")
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_BanUserAdmin() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	suite.userMock.On("GetUsersByIDs", ctx, []int{2}).Return([]*model2.User{{ID: 2, Role: model2.RoleAdmin}}, nil)
+
+	user, err := suite.mutationResolver.BanUser(ctx, 2, nil, "spam")
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_BanUserNotFound() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	suite.userMock.On("GetUsersByIDs", ctx, []int{2}).Return([]*model2.User{}, nil)
+
+	user, err := suite.mutationResolver.BanUser(ctx, 2, nil, "spam")
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_UnbanUserSuccess() {
+	ctx := context.WithValue(context.WithValue(context.Background(), "userID", 1), "role", "ADMIN")
+
+	suite.userMock.On("GetUsersByIDs", ctx, []int{2}).Return([]*model2.User{{ID: 2, Role: model2.RoleUser}}, nil)
+
+	suite.userMock.On("UnbanUser", ctx, 2).Return(&model2.User{ID: 2}, nil)
+
+	user, err := suite.mutationResolver.UnbanUser(ctx, 2)
+	suite.Require().NoError(err)
+
+	ban, err := suite.resolver.User().Ban(ctx, user)
+	suite.Nil(err)
+	suite.Nil(ban)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CreatePostBanned() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	until := time.Now().Add(time.Hour)
+
+	suite.userMock.On("CheckBan", ctx, 1).Return(apperror.Banned(&until, "spam"))
+
+	post, err := suite.mutationResolver.CreatePost(ctx, model2.CreatePostReq{Title: "test", Body: "test"})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CreateCommentBanned() {
+	ctx := context.WithValue(context.Background(), "userID", 1)
+
+	suite.userMock.On("CheckBan", ctx, 1).Return(apperror.Banned(nil, "spam"))
+
+	comment, err := suite.mutationResolver.CreateComment(ctx, model2.CreateCommentReq{PostID: 1, Body: "test"})
+
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RefreshTokenBanned() {
+	bannedAt := time.Now()
+
+	suite.sessionMock.On("RotateSession", mock.Anything, jwt.HashRefreshToken("old"), mock.Anything, mock.Anything).
+		Return(&model2.Session{ID: 7, UserID: 1}, nil)
+	suite.userMock.On("GetUsersByIDs", mock.Anything, []int{1}).
+		Return([]*model2.User{{ID: 1, BannedAt: &bannedAt}}, nil)
+
+	res, err := suite.mutationResolver.RefreshToken(context.Background(), "old")
+
+	suite.Nil(res)
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentBodyOfDeletedComment() {
+	deletedAt := time.Now()
+
+	body, err := suite.resolver.Comment().Body(context.Background(), &model2.Comment{ID: 1, Body: "test"})
+	suite.Nil(err)
+	suite.Equal("test", body)
+
+	body, err = suite.resolver.Comment().Body(context.Background(), &model2.Comment{ID: 1, Body: "test", DeletedAt: &deletedAt})
+	suite.Nil(err)
+	suite.Equal(model2.DeletedCommentBody, body)
+}
+
 // ====================================================
 
 func (suite *SchemaResolverSuite) TestResolver_GetCommentsSuccess() {
@@ -618,10 +1161,10 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsSuccess() {
 		},
 	}
 
-	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before).
+	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before, model2.CommentOrderOldest).
 		Return(comments, nil)
 
-	result, err := suite.queryResolver.GetComments(context.Background(), postID, &first, &last, after, before)
+	result, err := suite.queryResolver.GetCommentsByPostID(context.Background(), postID, &first, &last, after, before, nil)
 
 	suite.NotNil(result)
 
@@ -638,8 +1181,8 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsWithPagination() {
 	var last *int
 	after := time.Now().Add(-time.Hour)
 	before := time.Now().Add(time.Hour)
-	afterStr := after.Format(time.RFC3339)
-	beforeStr := before.Format(time.RFC3339)
+	afterStr := cursor.New(after, 1).Encode()
+	beforeStr := cursor.New(before, 100).Encode()
 
 	var commentsList []*model2.CommentEdge
 	for i := 1; i <= 15; i++ {
@@ -656,8 +1199,8 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsWithPagination() {
 
 	expectedComments := commentsList[:10]
 
-	startCursor := expectedComments[0].Node.CreatedAt.Format(time.RFC3339)
-	endCursor := expectedComments[len(expectedComments)-1].Node.CreatedAt.Format(time.RFC3339)
+	startCursor := cursor.New(expectedComments[0].Node.CreatedAt, expectedComments[0].Node.ID).Encode()
+	endCursor := cursor.New(expectedComments[9].Node.CreatedAt, expectedComments[9].Node.ID).Encode()
 
 	comments := &model2.CommentConnection{
 		Edges: expectedComments,
@@ -669,10 +1212,10 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsWithPagination() {
 		},
 	}
 
-	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, last, mock.Anything, mock.Anything).
+	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, last, mock.Anything, mock.Anything, model2.CommentOrderOldest).
 		Return(comments, nil)
 
-	result, err := suite.queryResolver.GetComments(context.Background(), postID, &first, last, &afterStr, &beforeStr)
+	result, err := suite.queryResolver.GetCommentsByPostID(context.Background(), postID, &first, last, &afterStr, &beforeStr, nil)
 
 	suite.NotNil(result)
 	suite.Nil(err)
@@ -694,15 +1237,29 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsFailure() {
 	var after *string
 	var before *string
 
-	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before).
+	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before, model2.CommentOrderOldest).
 		Return(nil, errors.New("error"))
 
-	result, err := suite.queryResolver.GetComments(context.Background(), postID, &first, &last, after, before)
+	result, err := suite.queryResolver.GetCommentsByPostID(context.Background(), postID, &first, &last, after, before, nil)
 
 	suite.Nil(result)
 	suite.NotNil(err)
 }
 
+func (suite *SchemaResolverSuite) TestResolver_GetCommentsOrderBy() {
+	postID := 1
+	first := 2
+	orderBy := model2.CommentOrderRecentActivity
+
+	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, (*int)(nil), (*string)(nil), (*string)(nil), model2.CommentOrderRecentActivity).
+		Return(&model2.CommentConnection{Edges: []*model2.CommentEdge{}, PageInfo: &model2.PageInfo{}}, nil)
+
+	result, err := suite.queryResolver.GetCommentsByPostID(context.Background(), postID, &first, nil, nil, nil, &orderBy)
+
+	suite.Nil(err)
+	suite.Empty(result.Edges)
+}
+
 func (suite *SchemaResolverSuite) TestResolver_GetCommentsEmpty() {
 	postID := 1
 	first := 2
@@ -720,16 +1277,362 @@ func (suite *SchemaResolverSuite) TestResolver_GetCommentsEmpty() {
 		},
 	}
 
-	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before).
+	suite.commentMock.On("GetCommentsByPostID", mock.Anything, postID, &first, &last, after, before, model2.CommentOrderOldest).
 		Return(comments, nil)
 
-	result, err := suite.queryResolver.GetComments(context.Background(), postID, &first, &last, after, before)
+	result, err := suite.queryResolver.GetCommentsByPostID(context.Background(), postID, &first, &last, after, before, nil)
 
 	suite.NotNil(result)
 	suite.Equal(0, len(result.Edges))
 	suite.Nil(err)
 }
 
+func (suite *SchemaResolverSuite) TestResolver_VotePostSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	suite.voteMock.On("VotePost", ctx, 2, 1, model2.VoteValueUp).Return(nil)
+	suite.postMock.On("GetPostByID", ctx, 1).Return(&model2.Post{ID: 1, UserID: 1, Upvotes: 1}, nil)
+
+	post, err := suite.mutationResolver.VotePost(ctx, 1, model2.VoteValueUp)
+
+	suite.Nil(err)
+	suite.Equal(1, post.Score())
+}
+
+func (suite *SchemaResolverSuite) TestResolver_VotePostUnauthorized() {
+	post, err := suite.mutationResolver.VotePost(context.Background(), 1, model2.VoteValueUp)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeUnauthenticated, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_VoteCommentNotFound() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	suite.voteMock.On("VoteComment", ctx, 2, 5, model2.VoteValueDown).Return(apperror.NotFound("comment not found"))
+
+	comment, err := suite.mutationResolver.VoteComment(ctx, 5, model2.VoteValueDown)
+
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PostMyVote() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+	ctx = loaders.NewContext(ctx, loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.voteMock.On("GetPostVotes", mock.Anything, 2, []int{1}).
+		Return(map[int]model2.VoteValue{1: model2.VoteValueDown}, nil)
+
+	vote, err := suite.resolver.Post().MyVote(ctx, &model2.Post{ID: 1})
+
+	suite.Nil(err)
+	suite.Equal(model2.VoteValueDown, vote)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentMyVoteAnonymous() {
+	vote, err := suite.resolver.Comment().MyVote(context.Background(), &model2.Comment{ID: 1})
+
+	suite.Nil(err)
+	suite.Equal(model2.VoteValueNone, vote)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_AddReactionSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	suite.reactionMock.On("AddReaction", ctx, 2, 1, "🎉").Return(nil)
+	suite.commentMock.On("GetCommentByID", ctx, 1).Return(&model2.Comment{ID: 1, PostID: 1}, nil)
+
+	comment, err := suite.mutationResolver.AddReaction(ctx, 1, "🎉")
+
+	suite.Nil(err)
+	suite.Equal(1, comment.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_AddReactionNotAllowed() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	comment, err := suite.mutationResolver.AddReaction(ctx, 1, "🦄")
+
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_RemoveReactionSuccess() {
+	ctx := context.WithValue(context.Background(), "userID", 2)
+
+	suite.reactionMock.On("RemoveReaction", ctx, 2, 1, "👍").Return(nil)
+	suite.commentMock.On("GetCommentByID", ctx, 1).Return(&model2.Comment{ID: 1, PostID: 1}, nil)
+
+	comment, err := suite.mutationResolver.RemoveReaction(ctx, 1, "👍")
+
+	suite.Nil(err)
+	suite.Equal(1, comment.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentReactions() {
+	ctx := loaders.NewContext(context.Background(), loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.reactionMock.On("GetReactions", mock.Anything, 0, []int{1}).
+		Return(map[int][]*model2.ReactionSummary{1: {{Emoji: "👍", Count: 2}}}, nil)
+
+	reactions, err := suite.resolver.Comment().Reactions(ctx, &model2.Comment{ID: 1})
+
+	suite.Nil(err)
+	suite.Len(reactions, 1)
+	suite.Equal(2, reactions[0].Count)
+	suite.False(reactions[0].Reacted)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_SearchSuccess() {
+	first := 10
+
+	suite.searchMock.On("Search", mock.Anything, "graphql", &first, (*string)(nil)).
+		Return(&model2.SearchConnection{
+			Edges: []*model2.SearchEdge{
+				{Cursor: "c", Node: &model2.Post{ID: 1}, Snippet: "<b>graphql</b>"},
+			},
+			PageInfo: &model2.PageInfo{},
+		}, nil)
+
+	results, err := suite.queryResolver.Search(context.Background(), "  graphql ", &first, nil)
+
+	suite.Nil(err)
+	suite.Len(results.Edges, 1)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_SearchEmptyQuery() {
+	results, err := suite.queryResolver.Search(context.Background(), "   ", nil, nil)
+
+	suite.Nil(results)
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PostCommentsSuccess() {
+	first := 1
+	ctx := loaders.NewContext(context.Background(), loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.commentMock.On("GetCommentsByPostIDs", mock.Anything, []int{1}, &first, (*int)(nil), (*string)(nil), (*string)(nil), model2.CommentOrderOldest).
+		Return(map[int]*model2.CommentConnection{
+			1: {
+				Edges: []*model2.CommentEdge{
+					{Node: &model2.Comment{ID: 1, PostID: 1, Body: "test"}},
+				},
+				PageInfo: &model2.PageInfo{HasNextPage: true},
+			},
+		}, nil)
+
+	result, err := suite.resolver.Post().Comments(ctx, &model2.Post{ID: 1}, &first, nil, nil, nil, nil)
+
+	suite.Nil(err)
+	suite.Equal(1, len(result.Edges))
+	suite.True(result.PageInfo.HasNextPage)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PostRevisionsSuccess() {
+	first := 1
+
+	suite.postMock.On("GetPostRevisions", context.Background(), 1, &first, (*string)(nil)).
+		Return(&model2.PostRevisionConnection{
+			Edges: []*model2.PostRevisionEdge{
+				{Node: &model2.PostRevision{ID: 1, PostID: 1, Title: "old"}},
+			},
+			PageInfo: &model2.PageInfo{},
+		}, nil)
+
+	result, err := suite.resolver.Post().Revisions(context.Background(), &model2.Post{ID: 1}, &first, nil)
+
+	suite.Nil(err)
+	suite.Equal(1, len(result.Edges))
+	suite.Equal("old", result.Edges[0].Node.Title)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_PostUserBatched() {
+	ctx := loaders.NewContext(context.Background(), loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.userMock.On("GetUsersByIDs", mock.Anything, mock.MatchedBy(func(ids []int) bool {
+		return len(ids) == 2
+	})).Return([]*model2.User{
+		{ID: 1, Username: "first"},
+		{ID: 2, Username: "second"},
+	}, nil).Once()
+
+	posts := []*model2.Post{{ID: 1, UserID: 1}, {ID: 2, UserID: 2}, {ID: 3, UserID: 1}}
+	users := make([]*model2.User, len(posts))
+	errs := make([]error, len(posts))
+
+	var wg sync.WaitGroup
+	for i, post := range posts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			users[i], errs[i] = suite.resolver.Post().User(ctx, post)
+		}()
+	}
+	wg.Wait()
+
+	suite.Equal([]error{nil, nil, nil}, errs)
+	suite.Equal("first", users[0].Username)
+	suite.Equal("second", users[1].Username)
+	suite.Equal("first", users[2].Username)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentRepliesSuccess() {
+	first := 1
+	parentID := 1
+	ctx := loaders.NewContext(context.Background(), loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.commentMock.On("GetRepliesByCommentIDs", mock.Anything, []int{parentID}, &first, (*int)(nil), (*string)(nil), (*string)(nil), model2.CommentOrderOldest).
+		Return(map[int]*model2.CommentConnection{
+			parentID: {
+				Edges: []*model2.CommentEdge{
+					{Node: &model2.Comment{ID: 2, PostID: 1, ParentCommentID: &parentID, Body: "reply"}},
+				},
+				PageInfo: &model2.PageInfo{},
+			},
+		}, nil)
+
+	result, err := suite.resolver.Comment().Replies(ctx, &model2.Comment{ID: parentID, PostID: 1}, &first, nil, nil, nil, nil)
+
+	suite.Nil(err)
+	suite.Equal("reply", result.Edges[0].Node.Body)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentRevisionsSuccess() {
+	suite.commentMock.On("GetCommentRevisions", context.Background(), 1, (*int)(nil), (*string)(nil)).
+		Return(&model2.CommentRevisionConnection{
+			Edges: []*model2.CommentRevisionEdge{
+				{Node: &model2.CommentRevision{ID: 1, CommentID: 1, Body: "before"}},
+			},
+			PageInfo: &model2.PageInfo{},
+		}, nil)
+
+	result, err := suite.resolver.Comment().Revisions(context.Background(), &model2.Comment{ID: 1}, nil, nil)
+
+	suite.Nil(err)
+	suite.Equal("before", result.Edges[0].Node.Body)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentRepliesFailure() {
+	ctx := loaders.NewContext(context.Background(), loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+
+	suite.commentMock.On("GetRepliesByCommentIDs", mock.Anything, []int{1}, (*int)(nil), (*int)(nil), (*string)(nil), (*string)(nil), model2.CommentOrderOldest).
+		Return(nil, errors.New("error"))
+
+	result, err := suite.resolver.Comment().Replies(ctx, &model2.Comment{ID: 1}, nil, nil, nil, nil, nil)
+
+	suite.Nil(result)
+	suite.NotNil(err)
+}
+
+// Users
+// ====================================================
+
+func (suite *SchemaResolverSuite) TestResolver_UserEmailHiddenFromOthers() {
+	author := &model2.User{ID: 1, Username: "author"}
+
+	email, err := suite.resolver.User().Email(context.Background(), author)
+	suite.Nil(err)
+	suite.Nil(email)
+
+	other := context.WithValue(context.Background(), "userID", 2)
+	email, err = suite.resolver.User().Email(other, author)
+	suite.Nil(err)
+	suite.Nil(email)
+
+	moderator := context.WithValue(other, "role", "MODERATOR")
+	email, err = suite.resolver.User().Email(moderator, author)
+	suite.Nil(err)
+	suite.Nil(email)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_UserEmailVisibleToOwnerAndAdmin() {
+	author := &model2.User{ID: 1, Username: "author"}
+
+	suite.userMock.On("GetEmailsByIDs", mock.Anything, []int{1}).Return(map[int]string{1: "author@mail.com"}, nil)
+
+	owner := context.WithValue(context.Background(), "userID", 1)
+	owner = loaders.NewContext(owner, loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+	email, err := suite.resolver.User().Email(owner, author)
+	suite.Nil(err)
+	suite.Equal("author@mail.com", *email)
+
+	admin := context.WithValue(context.WithValue(context.Background(), "userID", 2), "role", "ADMIN")
+	admin = loaders.NewContext(admin, loaders.NewLoaders(suite.userMock, suite.commentMock, suite.voteMock, suite.reactionMock))
+	email, err = suite.resolver.User().Email(admin, author)
+	suite.Nil(err)
+	suite.Equal("author@mail.com", *email)
+}
+
+// Subscriptions
+// ====================================================
+
+func (suite *SchemaResolverSuite) TestResolver_CommentAddedReceivesCreatedComment() {
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.userMock.On("CheckBan", mock.Anything, 1).Return(nil)
+	suite.postMock.On("GetPostByID", subCtx, 1).Return(&model2.Post{ID: 1}, nil)
+
+	ch, err := suite.subscriptionResolver.CommentAdded(subCtx, 1)
+	suite.Nil(err)
+
+	suite.commentMock.On("IsCommentsAllowed", subCtx, 1).Return(true, nil)
+
+	ctx := context.WithValue(context.Background(), "userID", 1)
+	req := model2.CreateCommentReq{
+		PostID: 1,
+		Body:   "test",
+	}
+
+	suite.commentMock.On("IsCommentsAllowed", ctx, 1).Return(true, nil)
+	suite.commentMock.On("CreateComment", ctx, 1, &req).
+		Return(&model2.Comment{
+			ID:     1,
+			PostID: 1,
+			UserID: 1,
+			Body:   "test",
+		}, nil)
+
+	_, err = suite.mutationResolver.CreateComment(ctx, req)
+	suite.Nil(err)
+
+	comment := <-ch
+	suite.Equal(1, comment.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentAddedSkipsCommentsOfHiddenPost() {
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	suite.postMock.On("GetPostByID", subCtx, 1).Return(&model2.Post{ID: 1}, nil)
+
+	ch, err := suite.subscriptionResolver.CommentAdded(subCtx, 1)
+	suite.Nil(err)
+
+	checked := make(chan struct{})
+	suite.commentMock.On("IsCommentsAllowed", subCtx, 1).
+		Return(false, apperror.NotFound("post not found")).Once().
+		Run(func(args mock.Arguments) { close(checked) })
+	suite.commentMock.On("IsCommentsAllowed", subCtx, 1).Return(true, nil).Once()
+
+	suite.resolver.CommentHub.Publish(1, &model2.Comment{ID: 1, PostID: 1})
+	<-checked
+	suite.resolver.CommentHub.Publish(1, &model2.Comment{ID: 2, PostID: 1})
+
+	comment := <-ch
+	suite.Equal(2, comment.ID)
+}
+
+func (suite *SchemaResolverSuite) TestResolver_CommentAddedUnknownPost() {
+	suite.postMock.On("GetPostByID", mock.Anything, 1).Return(nil, errors.New("error"))
+
+	ch, err := suite.subscriptionResolver.CommentAdded(context.Background(), 1)
+
+	suite.Nil(ch)
+	suite.NotNil(err)
+}
+
 func generateStringWith2000Chars() string {
 	charset := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 	var sb strings.Builder
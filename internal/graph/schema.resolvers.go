@@ -6,39 +6,154 @@ package graph
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	model2 "github.com/aaanger/graphql-test/internal/graph/model"
+	"strings"
+	"time"
 
+	"github.com/aaanger/graphql-test/internal/graph/loaders"
+	model2 "github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/jwt"
 	"github.com/aaanger/graphql-test/pkg/middleware"
 )
 
+// Body is the resolver for the body field.
+func (r *commentResolver) Body(ctx context.Context, obj *model2.Comment) (string, error) {
+	if obj.IsDeleted() {
+		return model2.DeletedCommentBody, nil
+	}
+
+	return obj.Body, nil
+}
+
+// MyVote is the resolver for the myVote field.
+func (r *commentResolver) MyVote(ctx context.Context, obj *model2.Comment) (model2.VoteValue, error) {
+	if _, err := middleware.GetUserID(ctx); err != nil {
+		return model2.VoteValueNone, nil
+	}
+
+	return loaders.For(ctx).CommentVoteByID.Load(ctx, obj.ID)
+}
+
+// Reactions is the resolver for the reactions field.
+func (r *commentResolver) Reactions(ctx context.Context, obj *model2.Comment) ([]*model2.ReactionSummary, error) {
+	return loaders.For(ctx).ReactionsByCommentID.Load(ctx, obj.ID)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *commentResolver) Revisions(ctx context.Context, obj *model2.Comment, first *int, after *string) (*model2.CommentRevisionConnection, error) {
+	revisions, err := r.CommentRepo.GetCommentRevisions(ctx, obj.ID, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Replies is the resolver for the replies field.
+func (r *commentResolver) Replies(ctx context.Context, obj *model2.Comment, first *int, last *int, after *string, before *string, orderBy *model2.CommentOrder) (*model2.CommentConnection, error) {
+	replies, err := loaders.For(ctx).RepliesByCommentID.Load(ctx, loaders.ConnectionKey{
+		ID:   obj.ID,
+		Page: loaders.NewPage(first, last, after, before, commentOrder(orderBy)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
 // Register is the resolver for the register field.
 func (r *mutationResolver) Register(ctx context.Context, req model2.RegisterReq) (*model2.AuthRes, error) {
-	user, accessToken, err := r.UserRepo.Register(ctx, &req)
+	user, err := r.UserRepo.Register(ctx, &req)
 	if err != nil {
 		return nil, err
 	}
 
-	return &model2.AuthRes{
-		User:  user,
-		Token: accessToken,
-	}, nil
+	return r.startSession(ctx, user)
 }
 
 // Login is the resolver for the login field.
 func (r *mutationResolver) Login(ctx context.Context, req model2.LoginReq) (*model2.AuthRes, error) {
-	user, accessToken, err := r.UserRepo.Login(ctx, &req)
+	user, err := r.UserRepo.Login(ctx, &req, middleware.GetClientIP(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return r.startSession(ctx, user)
+}
+
+// RefreshToken is the resolver for the refreshToken field.
+func (r *mutationResolver) RefreshToken(ctx context.Context, refreshToken string) (*model2.AuthRes, error) {
+	newRefreshToken, err := jwt.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := r.SessionRepo.RotateSession(ctx, jwt.HashRefreshToken(refreshToken), jwt.HashRefreshToken(newRefreshToken), time.Now().Add(jwt.RefreshTokenExpire))
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := r.UserRepo.GetUsersByIDs(ctx, []int{session.UserID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(users) == 0 {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	if users[0].IsBanned(time.Now()) {
+		return nil, apperror.Banned(users[0].BannedUntil, users[0].BanReason)
+	}
+
+	accessToken, err := r.Tokens.GenerateAccessToken(session.UserID, session.ID, users[0].Role.String())
 	if err != nil {
 		return nil, err
 	}
 
 	return &model2.AuthRes{
-		User:  user,
-		Token: accessToken,
+		User:         users[0],
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
 	}, nil
 }
 
+// Logout is the resolver for the logout field.
+func (r *mutationResolver) Logout(ctx context.Context) (bool, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	sessionID, err := middleware.GetSessionID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.SessionRepo.RevokeSession(ctx, userID, sessionID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// LogoutAllSessions is the resolver for the logoutAllSessions field.
+func (r *mutationResolver) LogoutAllSessions(ctx context.Context) (bool, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	err = r.SessionRepo.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
 // CreatePost is the resolver for the createPost field.
 func (r *mutationResolver) CreatePost(ctx context.Context, req model2.CreatePostReq) (*model2.Post, error) {
 	userID, err := middleware.GetUserID(ctx)
@@ -46,6 +161,16 @@ func (r *mutationResolver) CreatePost(ctx context.Context, req model2.CreatePost
 		return nil, err
 	}
 
+	err = r.UserRepo.CheckBan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validatePostSchedule(req.Status, req.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
 	post, err := r.PostRepo.CreatePost(ctx, userID, &req)
 	if err != nil {
 		return nil, err
@@ -56,17 +181,32 @@ func (r *mutationResolver) CreatePost(ctx context.Context, req model2.CreatePost
 
 // UpdatePost is the resolver for the updatePost field.
 func (r *mutationResolver) UpdatePost(ctx context.Context, postID int, req model2.UpdatePostReq) (*model2.Post, error) {
-	userID, err := middleware.GetUserID(ctx)
+	actor, err := viewer(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	err = r.PostRepo.UpdatePost(ctx, userID, postID, &req)
+	err = validatePostSchedule(req.Status, req.PublishAt)
 	if err != nil {
 		return nil, err
 	}
 
-	post, err := r.PostRepo.GetPostByID(ctx, postID)
+	post, err := r.PostRepo.UpdatePost(ctx, actor, postID, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// RevertPost is the resolver for the revertPost field.
+func (r *mutationResolver) RevertPost(ctx context.Context, postID int, revisionID int) (*model2.Post, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.PostRepo.RevertPost(ctx, actor, postID, revisionID)
 	if err != nil {
 		return nil, err
 	}
@@ -78,17 +218,62 @@ func (r *mutationResolver) UpdatePost(ctx context.Context, postID int, req model
 func (r *mutationResolver) DeletePost(ctx context.Context, postID int) (string, error) {
 	userID, err := middleware.GetUserID(ctx)
 	if err != nil {
-		return "Unauthorized", err
+		return "", err
 	}
 
 	err = r.PostRepo.DeletePost(ctx, userID, postID)
 	if err != nil {
-		return "Failed to delete post", err
+		return "", err
 	}
 
 	return "Post deleted successfully", nil
 }
 
+// RestorePost is the resolver for the restorePost field.
+func (r *mutationResolver) RestorePost(ctx context.Context, postID int) (*model2.Post, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.PostRepo.RestorePost(ctx, userID, postID, time.Now().UTC().Add(-r.PostRestoreWindow))
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// PublishPost is the resolver for the publishPost field.
+func (r *mutationResolver) PublishPost(ctx context.Context, postID int) (*model2.Post, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	post, err := r.PostRepo.PublishPost(ctx, actor, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// VotePost is the resolver for the votePost field.
+func (r *mutationResolver) VotePost(ctx context.Context, postID int, value model2.VoteValue) (*model2.Post, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.VoteRepo.VotePost(ctx, userID, postID, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.PostRepo.GetPostByID(ctx, postID)
+}
+
 // CreateComment is the resolver for the createComment field.
 func (r *mutationResolver) CreateComment(ctx context.Context, req model2.CreateCommentReq) (*model2.Comment, error) {
 	userID, err := middleware.GetUserID(ctx)
@@ -96,13 +281,22 @@ func (r *mutationResolver) CreateComment(ctx context.Context, req model2.CreateC
 		return nil, err
 	}
 
+	err = r.UserRepo.CheckBan(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
 	isAllowed, err := r.CommentRepo.IsCommentsAllowed(ctx, req.PostID)
+	if err != nil {
+		return nil, err
+	}
+
 	if !isAllowed {
-		return nil, errors.New("comments are not allowed for this post")
+		return nil, apperror.Forbidden("comments are not allowed for this post")
 	}
 
 	if len(req.Body) > 2000 {
-		return nil, errors.New("comment must be less than 2000 chars")
+		return nil, apperror.Validation("comment must be less than 2000 chars")
 	}
 
 	comment, err := r.CommentRepo.CreateComment(ctx, userID, &req)
@@ -110,51 +304,272 @@ func (r *mutationResolver) CreateComment(ctx context.Context, req model2.CreateC
 		return nil, err
 	}
 
+	r.CommentHub.Publish(comment.PostID, comment)
+
 	return comment, nil
 }
 
 // UpdateComment is the resolver for the updateComment field.
 func (r *mutationResolver) UpdateComment(ctx context.Context, req model2.UpdateCommentReq) (*model2.Comment, error) {
-	userID, err := middleware.GetUserID(ctx)
+	actor, err := viewer(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	if len(req.Body) > 2000 {
-		return nil, errors.New("comment must be less than 2000 chars")
+		return nil, apperror.Validation("comment must be less than 2000 chars")
+	}
+
+	comment, err := r.CommentRepo.UpdateComment(ctx, actor, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment is the resolver for the deleteComment field.
+func (r *mutationResolver) DeleteComment(ctx context.Context, commentID int) (string, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	err = r.CommentRepo.DeleteComment(ctx, actor, commentID)
+	if err != nil {
+		return "", err
 	}
 
-	err = r.CommentRepo.UpdateComment(ctx, userID, &req)
+	return "Deleted comment", nil
+}
+
+// VoteComment is the resolver for the voteComment field.
+func (r *mutationResolver) VoteComment(ctx context.Context, commentID int, value model2.VoteValue) (*model2.Comment, error) {
+	userID, err := middleware.GetUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	updatedComment, err := r.CommentRepo.GetCommentByID(ctx, req.ID)
+	err = r.VoteRepo.VoteComment(ctx, userID, commentID, value)
 	if err != nil {
 		return nil, err
 	}
 
-	return updatedComment, nil
+	return r.CommentRepo.GetCommentByID(ctx, commentID)
 }
 
-// DeleteComment is the resolver for the deleteComment field.
-func (r *mutationResolver) DeleteComment(ctx context.Context, commentID int) (string, error) {
+// AddReaction is the resolver for the addReaction field.
+func (r *mutationResolver) AddReaction(ctx context.Context, commentID int, emoji string) (*model2.Comment, error) {
 	userID, err := middleware.GetUserID(ctx)
 	if err != nil {
-		return "Unauthorized", err
+		return nil, err
 	}
 
-	err = r.CommentRepo.DeleteComment(ctx, userID, commentID)
+	err = validateReaction(r.Reactions, emoji)
 	if err != nil {
-		return "Failed to delete comment", err
+		return nil, err
 	}
 
-	return "Deleted comment", nil
+	err = r.ReactionRepo.AddReaction(ctx, userID, commentID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.CommentRepo.GetCommentByID(ctx, commentID)
+}
+
+// RemoveReaction is the resolver for the removeReaction field.
+func (r *mutationResolver) RemoveReaction(ctx context.Context, commentID int, emoji string) (*model2.Comment, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateReaction(r.Reactions, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	err = r.ReactionRepo.RemoveReaction(ctx, userID, commentID, emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.CommentRepo.GetCommentByID(ctx, commentID)
+}
+
+// PurgeComment is the resolver for the purgeComment field.
+func (r *mutationResolver) PurgeComment(ctx context.Context, commentID int) (bool, error) {
+	err := r.CommentRepo.PurgeComment(ctx, commentID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// ReportPost is the resolver for the reportPost field.
+func (r *mutationResolver) ReportPost(ctx context.Context, postID int, reason model2.ReportReason, note *string) (*model2.Report, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateReportNote(note)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := r.ReportRepo.ReportPost(ctx, userID, postID, reason, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ReportComment is the resolver for the reportComment field.
+func (r *mutationResolver) ReportComment(ctx context.Context, commentID int, reason model2.ReportReason, note *string) (*model2.Report, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = validateReportNote(note)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := r.ReportRepo.ReportComment(ctx, userID, commentID, reason, note)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ResolveReport is the resolver for the resolveReport field.
+func (r *mutationResolver) ResolveReport(ctx context.Context, reportID int, action model2.ReportAction) (*model2.Report, error) {
+	actor, err := viewer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := r.ReportRepo.ResolveReport(ctx, actor, reportID, action)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// ModeratePost is the resolver for the moderatePost field.
+func (r *mutationResolver) ModeratePost(ctx context.Context, postID int, req model2.ModeratePostReq) (*model2.Post, error) {
+	post, err := r.PostRepo.ModeratePost(ctx, postID, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// GrantRole is the resolver for the grantRole field.
+func (r *mutationResolver) GrantRole(ctx context.Context, userID int, role model2.Role) (*model2.User, error) {
+	return r.setRole(ctx, userID, role)
+}
+
+// RevokeRole is the resolver for the revokeRole field.
+func (r *mutationResolver) RevokeRole(ctx context.Context, userID int) (*model2.User, error) {
+	user, err := r.setRole(ctx, userID, model2.RoleUser)
+	if err != nil {
+		return nil, err
+	}
+
+	// access tokens carry the role until they expire, ending the sessions
+	// takes the revoked rights away right now
+	err = r.SessionRepo.RevokeAllSessions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// BanUser is the resolver for the banUser field.
+func (r *mutationResolver) BanUser(ctx context.Context, userID int, until *time.Time, reason string) (*model2.User, error) {
+	return r.banUser(ctx, userID, until, reason)
+}
+
+// UnbanUser is the resolver for the unbanUser field.
+func (r *mutationResolver) UnbanUser(ctx context.Context, userID int) (*model2.User, error) {
+	return r.unbanUser(ctx, userID)
+}
+
+// User is the resolver for the user field.
+func (r *postResolver) User(ctx context.Context, obj *model2.Post) (*model2.User, error) {
+	user, err := loaders.For(ctx).UserByID.Load(ctx, obj.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// MyVote is the resolver for the myVote field.
+func (r *postResolver) MyVote(ctx context.Context, obj *model2.Post) (model2.VoteValue, error) {
+	if _, err := middleware.GetUserID(ctx); err != nil {
+		return model2.VoteValueNone, nil
+	}
+
+	return loaders.For(ctx).PostVoteByID.Load(ctx, obj.ID)
+}
+
+// Revisions is the resolver for the revisions field.
+func (r *postResolver) Revisions(ctx context.Context, obj *model2.Post, first *int, after *string) (*model2.PostRevisionConnection, error) {
+	revisions, err := r.PostRepo.GetPostRevisions(ctx, obj.ID, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *postResolver) Comments(ctx context.Context, obj *model2.Post, first *int, last *int, after *string, before *string, orderBy *model2.CommentOrder) (*model2.CommentConnection, error) {
+	comments, err := loaders.For(ctx).CommentsByPostID.Load(ctx, loaders.ConnectionKey{
+		ID:   obj.ID,
+		Page: loaders.NewPage(first, last, after, before, commentOrder(orderBy)),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+// Posts is the resolver for the posts field.
+func (r *queryResolver) Posts(ctx context.Context, first *int, after *string, last *int, before *string, orderBy *model2.PostOrder) (*model2.PostConnection, error) {
+	order := model2.PostOrderNewest
+	if orderBy != nil {
+		order = *orderBy
+	}
+
+	posts, err := r.PostRepo.GetPosts(ctx, first, last, after, before, order)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
 }
 
 // GetPostsByUserID is the resolver for the getPostsByUserID field.
 func (r *queryResolver) GetPostsByUserID(ctx context.Context, userID int) ([]*model2.Post, error) {
-	panic(fmt.Errorf("not implemented: GetPostsByUserID - getPostsByUserID"))
+	posts, err := r.PostRepo.GetAllPostsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
 }
 
 // GetPostByID is the resolver for the getPostByID field.
@@ -167,41 +582,203 @@ func (r *queryResolver) GetPostByID(ctx context.Context, id int) (*model2.Post,
 	return post, nil
 }
 
-// GetCommentsByPostID is the resolver for the getCommentsByPostID field.
-func (r *queryResolver) GetCommentsByPostID(ctx context.Context, postID int, first *int, last *int, after *string, before *string) (*model2.CommentConnection, error) {
-	panic(fmt.Errorf("not implemented: GetCommentsByPostID - getCommentsByPostID"))
+// MyDrafts is the resolver for the myDrafts field.
+func (r *queryResolver) MyDrafts(ctx context.Context) ([]*model2.Post, error) {
+	userID, err := middleware.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, err := r.PostRepo.GetDraftsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
 }
 
-// Mutation returns MutationResolver implementation.
-func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+// Search is the resolver for the search field.
+func (r *queryResolver) Search(ctx context.Context, query string, first *int, after *string) (*model2.SearchConnection, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, apperror.Validation("search query must not be empty")
+	}
 
-// Query returns QueryResolver implementation.
-func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+	results, err := r.SearchRepo.Search(ctx, query, first, after)
+	if err != nil {
+		return nil, err
+	}
 
-type mutationResolver struct{ *Resolver }
-type queryResolver struct{ *Resolver }
+	return results, nil
+}
 
-// !!! WARNING !!!
-// The code below was going to be deleted when updating resolvers. It has been copied here so you have
-// one last chance to move it out of harms way if you want. There are two reasons this happens:
-//  - When renaming or deleting a resolver the old code will be put in here. You can safely delete
-//    it when you're done.
-//  - You have helper methods in this file. Move them out to keep these resolver files clean.
-/*
-	func (r *queryResolver) GetPosts(ctx context.Context, userID int) ([]*model.Post, error) {
-	posts, err := r.PostRepo.GetAllPostsByUserID(ctx, userID)
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, status *model2.ReportStatus, first *int, after *string) (*model2.ReportConnection, error) {
+	reports, err := r.ReportRepo.GetReports(ctx, reportStatus(status), first, after)
 	if err != nil {
 		return nil, err
 	}
 
-	return posts, nil
+	return reports, nil
 }
-func (r *queryResolver) GetComments(ctx context.Context, postID int, first *int, last *int, after *string, before *string) (*model.CommentConnection, error) {
-	comments, err := r.CommentRepo.GetCommentsByPostID(ctx, postID, first, last, after, before)
+
+// GetCommentsByPostID is the resolver for the getCommentsByPostID field.
+func (r *queryResolver) GetCommentsByPostID(ctx context.Context, postID int, first *int, last *int, after *string, before *string, orderBy *model2.CommentOrder) (*model2.CommentConnection, error) {
+	comments, err := r.CommentRepo.GetCommentsByPostID(ctx, postID, first, last, after, before, commentOrder(orderBy))
 	if err != nil {
 		return nil, err
 	}
 
 	return comments, nil
 }
-*/
+
+// Reporter is the resolver for the reporter field.
+func (r *reportResolver) Reporter(ctx context.Context, obj *model2.Report) (*model2.User, error) {
+	user, err := loaders.For(ctx).UserByID.Load(ctx, obj.ReporterID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// Post is the resolver for the post field.
+func (r *reportResolver) Post(ctx context.Context, obj *model2.Report) (*model2.Post, error) {
+	if obj.PostID == nil {
+		return nil, nil
+	}
+
+	post, err := r.PostRepo.GetPostByID(ctx, *obj.PostID)
+	if apperror.CodeOf(err) == apperror.CodeNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// Comment is the resolver for the comment field.
+func (r *reportResolver) Comment(ctx context.Context, obj *model2.Report) (*model2.Comment, error) {
+	if obj.CommentID == nil {
+		return nil, nil
+	}
+
+	comment, err := r.CommentRepo.GetCommentByID(ctx, *obj.CommentID)
+	if apperror.CodeOf(err) == apperror.CodeNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ResolvedBy is the resolver for the resolvedBy field.
+func (r *reportResolver) ResolvedBy(ctx context.Context, obj *model2.Report) (*model2.User, error) {
+	if obj.ResolvedByID == nil {
+		return nil, nil
+	}
+
+	user, err := loaders.For(ctx).UserByID.Load(ctx, *obj.ResolvedByID)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// CommentAdded is the resolver for the commentAdded field.
+func (r *subscriptionResolver) CommentAdded(ctx context.Context, postID int) (<-chan *model2.Comment, error) {
+	_, err := r.PostRepo.GetPostByID(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := r.CommentHub.Subscribe(ctx, postID)
+	visible := make(chan *model2.Comment)
+
+	go func() {
+		defer close(visible)
+
+		for comment := range comments {
+			// The post may have been deleted, hidden or closed for comments
+			// since the subscription started.
+			isAllowed, err := r.CommentRepo.IsCommentsAllowed(ctx, postID)
+			if err != nil || !isAllowed {
+				continue
+			}
+
+			select {
+			case visible <- comment:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return visible, nil
+}
+
+// Email is the resolver for the email field.
+func (r *userResolver) Email(ctx context.Context, obj *model2.User) (*string, error) {
+	if !canSeeEmail(ctx, obj) {
+		return nil, nil
+	}
+
+	// Profiles loaded for posts and comments come without the email.
+	if obj.Email != "" {
+		return &obj.Email, nil
+	}
+
+	email, err := loaders.For(ctx).EmailByUserID.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &email, nil
+}
+
+// Ban is the resolver for the ban field.
+func (r *userResolver) Ban(ctx context.Context, obj *model2.User) (*model2.Ban, error) {
+	if !obj.IsBanned(time.Now()) {
+		return nil, nil
+	}
+
+	return &model2.Ban{
+		BannedAt: *obj.BannedAt,
+		Until:    obj.BannedUntil,
+		Reason:   obj.BanReason,
+	}, nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Post returns PostResolver implementation.
+func (r *Resolver) Post() PostResolver { return &postResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Report returns ReportResolver implementation.
+func (r *Resolver) Report() ReportResolver { return &reportResolver{r} }
+
+// Subscription returns SubscriptionResolver implementation.
+func (r *Resolver) Subscription() SubscriptionResolver { return &subscriptionResolver{r} }
+
+// User returns UserResolver implementation.
+func (r *Resolver) User() UserResolver { return &userResolver{r} }
+
+type commentResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type postResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type reportResolver struct{ *Resolver }
+type subscriptionResolver struct{ *Resolver }
+type userResolver struct{ *Resolver }
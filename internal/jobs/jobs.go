@@ -0,0 +1,23 @@
+// Package jobs contains the background jobs running next to the API server.
+package jobs
+
+import (
+	"context"
+	"time"
+)
+
+// runEvery calls job right away and then every interval until ctx is done.
+func runEvery(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		job(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
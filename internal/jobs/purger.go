@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/repository/post"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// PostPurger permanently removes deleted posts once their restore window has
+// passed.
+type PostPurger struct {
+	posts    post.IPostRepository
+	window   time.Duration
+	interval time.Duration
+}
+
+func NewPostPurger(posts post.IPostRepository, window, interval time.Duration) *PostPurger {
+	return &PostPurger{
+		posts:    posts,
+		window:   window,
+		interval: interval,
+	}
+}
+
+// Run purges expired posts right away and then every interval until ctx is
+// done.
+func (p *PostPurger) Run(ctx context.Context) {
+	runEvery(ctx, p.interval, p.Purge)
+}
+
+// Purge removes the posts deleted longer than the restore window ago. The
+// cutoff is in UTC like the deleted_at it is compared with.
+func (p *PostPurger) Purge(ctx context.Context) {
+	count, err := p.posts.PurgeDeletedPosts(ctx, time.Now().UTC().Add(-p.window))
+	if err != nil {
+		logrus.Errorf("Error purging deleted posts: %s", err)
+		return
+	}
+
+	if count > 0 {
+		logrus.Infof("Purged %d deleted posts", count)
+	}
+}
@@ -0,0 +1,55 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	postMocks "github.com/aaanger/graphql-test/internal/repository/post/mocks"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestPostPurger_PurgesPostsOutsideWindow(t *testing.T) {
+	posts := postMocks.NewIPostRepository(t)
+
+	start := time.Now()
+	posts.On("PurgeDeletedPosts", mock.Anything, mock.MatchedBy(func(deletedBefore time.Time) bool {
+		cutoff := start.Add(-time.Hour)
+		return !deletedBefore.Before(cutoff) && deletedBefore.Sub(cutoff) < time.Minute
+	})).Return(2, nil)
+
+	NewPostPurger(posts, time.Hour, time.Minute).Purge(context.Background())
+}
+
+func TestPostPurger_CutoffInUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	defer func() { time.Local = local }()
+
+	posts := postMocks.NewIPostRepository(t)
+	posts.On("PurgeDeletedPosts", mock.Anything, mock.MatchedBy(func(deletedBefore time.Time) bool {
+		return deletedBefore.Location() == time.UTC
+	})).Return(0, nil)
+
+	NewPostPurger(posts, time.Hour, time.Minute).Purge(context.Background())
+}
+
+func TestPostPurger_RunStopsWithContext(t *testing.T) {
+	posts := postMocks.NewIPostRepository(t)
+	posts.On("PurgeDeletedPosts", mock.Anything, mock.Anything).Return(0, errors.New("error"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		NewPostPurger(posts, time.Hour, time.Hour).Run(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("purger didn't stop")
+	}
+}
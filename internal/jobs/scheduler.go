@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/repository/post"
+	"github.com/sirupsen/logrus"
+	"time"
+)
+
+// PostScheduler publishes scheduled drafts once their publishAt time has come.
+// A post is published at most interval after its publishAt.
+type PostScheduler struct {
+	posts    post.IPostRepository
+	interval time.Duration
+}
+
+func NewPostScheduler(posts post.IPostRepository, interval time.Duration) *PostScheduler {
+	return &PostScheduler{
+		posts:    posts,
+		interval: interval,
+	}
+}
+
+// Run publishes due posts right away and then every interval until ctx is
+// done.
+func (s *PostScheduler) Run(ctx context.Context) {
+	runEvery(ctx, s.interval, s.Publish)
+}
+
+// Publish publishes the drafts scheduled up to now.
+func (s *PostScheduler) Publish(ctx context.Context) {
+	count, err := s.posts.PublishScheduledPosts(ctx, time.Now())
+	if err != nil {
+		logrus.Errorf("Error publishing scheduled posts: %s", err)
+		return
+	}
+
+	if count > 0 {
+		logrus.Infof("Published %d scheduled posts", count)
+	}
+}
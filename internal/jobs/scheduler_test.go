@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+	postMocks "github.com/aaanger/graphql-test/internal/repository/post/mocks"
+	"github.com/stretchr/testify/mock"
+	"testing"
+	"time"
+)
+
+func TestPostScheduler_PublishesDuePosts(t *testing.T) {
+	posts := postMocks.NewIPostRepository(t)
+
+	start := time.Now()
+	posts.On("PublishScheduledPosts", mock.Anything, mock.MatchedBy(func(now time.Time) bool {
+		return !now.Before(start) && now.Sub(start) < time.Minute
+	})).Return(1, nil)
+
+	NewPostScheduler(posts, time.Minute).Publish(context.Background())
+}
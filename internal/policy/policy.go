@@ -0,0 +1,109 @@
+package policy
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+)
+
+// roleLevels orders roles so that a role is granted everything lower roles
+// are allowed to do.
+var roleLevels = map[model.Role]int{
+	model.RoleUser:      1,
+	model.RoleModerator: 2,
+	model.RoleAdmin:     3,
+}
+
+// Actor is the user performing an action.
+type Actor struct {
+	UserID int
+	Role   model.Role
+}
+
+// HasRole reports whether role is granted everything required is.
+func HasRole(role, required model.Role) bool {
+	return roleLevels[role] >= roleLevels[required]
+}
+
+// IsModerator reports whether the actor can moderate content of other users.
+func (a Actor) IsModerator() bool {
+	return HasRole(a.Role, model.RoleModerator)
+}
+
+// CanModifyComment allows authors to edit and delete their comments, and
+// moderators to edit and delete any comment.
+func CanModifyComment(actor Actor, ownerID int) error {
+	if actor.UserID != ownerID && !actor.IsModerator() {
+		return apperror.Forbidden("comment belongs to another user")
+	}
+
+	return nil
+}
+
+// CanEditPost allows authors to edit their posts until a moderator locks
+// them. Moderators can still edit the content of their own locked posts, but
+// the status of a locked post stays as moderation left it, whatever the role
+// of its author.
+func CanEditPost(actor Actor, ownerID int, isLocked, changesStatus bool) error {
+	if actor.UserID != ownerID {
+		return apperror.Forbidden("post belongs to another user")
+	}
+
+	if isLocked && !actor.IsModerator() {
+		return apperror.Forbidden("post is locked")
+	}
+
+	if isLocked && changesStatus {
+		return apperror.Forbidden("status of a locked post can't be changed")
+	}
+
+	return nil
+}
+
+// CanSetRole allows admins to grant and revoke roles of other users. Admins
+// can't change their own role, so there is always an admin left to undo it.
+func CanSetRole(actor Actor, userID int) error {
+	if !HasRole(actor.Role, model.RoleAdmin) {
+		return apperror.Forbidden("not enough permissions")
+	}
+
+	if actor.UserID == userID {
+		return apperror.Forbidden("admins can't change their own role")
+	}
+
+	return nil
+}
+
+// CanBanUser allows admins to ban and unban other users. Admins can't ban
+// themselves, so they can't lock themselves out by mistake, and they can't
+// ban each other: another admin has to lose the role through revokeRole
+// first.
+func CanBanUser(actor Actor, userID int, userRole model.Role) error {
+	if !HasRole(actor.Role, model.RoleAdmin) {
+		return apperror.Forbidden("not enough permissions")
+	}
+
+	if actor.UserID == userID {
+		return apperror.Forbidden("admins can't ban themselves")
+	}
+
+	if HasRole(userRole, model.RoleAdmin) {
+		return apperror.Forbidden("admins can't ban other admins")
+	}
+
+	return nil
+}
+
+// CanBanAuthor allows moderators resolving a report to ban the author of the
+// reported content. Only authors with a lower role can be banned this way, so
+// moderators can't ban admins, each other or themselves.
+func CanBanAuthor(actor Actor, authorID int, authorRole model.Role) error {
+	if actor.UserID == authorID {
+		return apperror.Forbidden("moderators can't ban themselves")
+	}
+
+	if roleLevels[actor.Role] <= roleLevels[authorRole] {
+		return apperror.Forbidden("not enough permissions to ban the author")
+	}
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package policy
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHasRole(t *testing.T) {
+	assert.True(t, HasRole(model.RoleAdmin, model.RoleModerator))
+	assert.True(t, HasRole(model.RoleModerator, model.RoleModerator))
+	assert.False(t, HasRole(model.RoleUser, model.RoleModerator))
+	assert.False(t, HasRole("", model.RoleUser))
+}
+
+func TestCanModifyComment(t *testing.T) {
+	assert.Nil(t, CanModifyComment(Actor{UserID: 1, Role: model.RoleUser}, 1))
+	assert.Nil(t, CanModifyComment(Actor{UserID: 2, Role: model.RoleModerator}, 1))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanModifyComment(Actor{UserID: 2, Role: model.RoleUser}, 1)))
+}
+
+func TestCanEditPost(t *testing.T) {
+	assert.Nil(t, CanEditPost(Actor{UserID: 1, Role: model.RoleUser}, 1, false, false))
+	assert.Nil(t, CanEditPost(Actor{UserID: 1, Role: model.RoleUser}, 1, false, true))
+	assert.Nil(t, CanEditPost(Actor{UserID: 1, Role: model.RoleModerator}, 1, true, false))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanEditPost(Actor{UserID: 1, Role: model.RoleUser}, 1, true, false)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanEditPost(Actor{UserID: 1, Role: model.RoleModerator}, 1, true, true)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanEditPost(Actor{UserID: 1, Role: model.RoleAdmin}, 1, true, true)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanEditPost(Actor{UserID: 2, Role: model.RoleAdmin}, 1, false, false)))
+}
+
+func TestCanSetRole(t *testing.T) {
+	assert.Nil(t, CanSetRole(Actor{UserID: 1, Role: model.RoleAdmin}, 2))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanSetRole(Actor{UserID: 1, Role: model.RoleAdmin}, 1)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanSetRole(Actor{UserID: 1, Role: model.RoleModerator}, 2)))
+}
+
+func TestCanBanUser(t *testing.T) {
+	assert.Nil(t, CanBanUser(Actor{UserID: 1, Role: model.RoleAdmin}, 2, model.RoleUser))
+	assert.Nil(t, CanBanUser(Actor{UserID: 1, Role: model.RoleAdmin}, 2, model.RoleModerator))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanUser(Actor{UserID: 1, Role: model.RoleAdmin}, 1, model.RoleAdmin)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanUser(Actor{UserID: 1, Role: model.RoleAdmin}, 2, model.RoleAdmin)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanUser(Actor{UserID: 1, Role: model.RoleModerator}, 2, model.RoleUser)))
+}
+
+func TestCanBanAuthor(t *testing.T) {
+	assert.Nil(t, CanBanAuthor(Actor{UserID: 1, Role: model.RoleModerator}, 2, model.RoleUser))
+	assert.Nil(t, CanBanAuthor(Actor{UserID: 1, Role: model.RoleAdmin}, 2, model.RoleModerator))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanAuthor(Actor{UserID: 1, Role: model.RoleModerator}, 2, model.RoleModerator)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanAuthor(Actor{UserID: 1, Role: model.RoleModerator}, 2, model.RoleAdmin)))
+	assert.Equal(t, apperror.CodeForbidden, apperror.CodeOf(CanBanAuthor(Actor{UserID: 1, Role: model.RoleAdmin}, 1, model.RoleAdmin)))
+}
@@ -3,8 +3,12 @@ package comment
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
 	"strings"
 	"time"
 )
@@ -14,9 +18,13 @@ import (
 type ICommentRepository interface {
 	CreateComment(ctx context.Context, userID int, req *model.CreateCommentReq) (*model.Comment, error)
 	GetCommentByID(ctx context.Context, id int) (*model.Comment, error)
-	GetCommentsByPostID(ctx context.Context, postID int, first, last *int, after, before *string) (*model.CommentConnection, error)
-	UpdateComment(ctx context.Context, userID int, req *model.UpdateCommentReq) error
-	DeleteComment(ctx context.Context, userID, commentID int) error
+	GetCommentsByPostID(ctx context.Context, postID int, first, last *int, after, before *string, orderBy model.CommentOrder) (*model.CommentConnection, error)
+	GetCommentsByPostIDs(ctx context.Context, postIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error)
+	GetRepliesByCommentIDs(ctx context.Context, commentIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error)
+	UpdateComment(ctx context.Context, actor policy.Actor, req *model.UpdateCommentReq) (*model.Comment, error)
+	GetCommentRevisions(ctx context.Context, commentID int, first *int, after *string) (*model.CommentRevisionConnection, error)
+	DeleteComment(ctx context.Context, actor policy.Actor, commentID int) error
+	PurgeComment(ctx context.Context, commentID int) error
 	IsCommentsAllowed(ctx context.Context, postID int) (bool, error)
 }
 
@@ -49,12 +57,18 @@ func (r *CommentRepository) CreateComment(ctx context.Context, userID int, req *
 	return &comment, nil
 }
 
+// GetCommentByID returns the comment if its post is visible. Comments of
+// deleted or unpublished posts are reported as NotFound errors.
 func (r *CommentRepository) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
 	var comment model.Comment
 
-	row := r.db.QueryRowContext(ctx, `SELECT id, post_id, user_id, parent_comment_id, body, created_at FROM comments WHERE id = $1;`, id)
+	row := r.db.QueryRowContext(ctx, `SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.body, c.created_at, c.updated_at, c.deleted_at, c.upvotes, c.downvotes 
+										FROM comments c `+visiblePostJoin+` WHERE c.id = $1;`, id)
 
-	err := row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt)
+	err := row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt, &comment.Upvotes, &comment.Downvotes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("comment not found")
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -62,56 +76,129 @@ func (r *CommentRepository) GetCommentByID(ctx context.Context, id int) (*model.
 	return &comment, nil
 }
 
-func (r *CommentRepository) GetCommentsByPostID(ctx context.Context, postID int, first, last *int, after, before *string) (*model.CommentConnection, error) {
-	query := `WITH RECURSIVE comment_tree AS (
-				SELECT id, post_id, user_id, parent_comment_id, body, created_at
-				FROM comments
-				WHERE post_id = $1
-				UNION ALL
-				SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.body, c.created_at
-				FROM comments c
-				INNER JOIN comment_tree ct ON c.parent_comment_id = ct.id
-				)
-				SELECT id, post_id, user_id, parent_comment_id, body, created_at FROM comment_tree`
+func (r *CommentRepository) GetCommentsByPostID(ctx context.Context, postID int, first, last *int, after, before *string, orderBy model.CommentOrder) (*model.CommentConnection, error) {
+	connections, err := r.GetCommentsByPostIDs(ctx, []int{postID}, first, last, after, before, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	return connections[postID], nil
+}
+
+// GetCommentsByPostIDs returns the same page of top-level comments for every
+// post in postIDs using a single query.
+func (r *CommentRepository) GetCommentsByPostIDs(ctx context.Context, postIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	return r.getComments(ctx, "post_id", " AND c.parent_comment_id IS NULL", postIDs, first, last, after, before, orderBy)
+}
+
+// GetRepliesByCommentIDs returns the same page of direct replies for every
+// comment in commentIDs using a single query.
+func (r *CommentRepository) GetRepliesByCommentIDs(ctx context.Context, commentIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	return r.getComments(ctx, "parent_comment_id", "", commentIDs, first, last, after, before, orderBy)
+}
+
+// commentSortKey returns the expression comments are sorted by in the given
+// order, evaluated against a comments row aliased c, and whether the order is
+// descending.
+func commentSortKey(orderBy model.CommentOrder) (string, bool) {
+	switch orderBy {
+	case model.CommentOrderNewest:
+		return "c.created_at", true
+	case model.CommentOrderMostReplies:
+		return "(SELECT COUNT(*) FROM comments r WHERE r.parent_comment_id = c.id AND r.deleted_at IS NULL)", true
+	case model.CommentOrderRecentActivity:
+		// the latest comment anywhere in the subtree, the comment itself included
+		return `(WITH RECURSIVE subtree AS (
+						SELECT c.id, c.created_at
+						UNION ALL
+						SELECT r.id, r.created_at FROM comments r JOIN subtree s ON r.parent_comment_id = s.id
+					) SELECT MAX(created_at) FROM subtree)`, true
+	default:
+		return "c.created_at", false
+	}
+}
+
+// visiblePostJoin limits a comments row aliased c to comments of posts shown
+// to everyone, so deleted and hidden posts take their threads with them.
+const visiblePostJoin = `JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'`
+
+// getComments pages through the comments of every parent in ids at once,
+// where parent is the column grouping them. Rows are numbered per parent so
+// the limit applies to each parent separately; one extra row is requested to
+// find out whether there is a page beyond the requested one.
+func (r *CommentRepository) getComments(ctx context.Context, parent, filter string, ids []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCursor, err := cursor.DecodeOptional(before)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKey, descending := commentSortKey(orderBy)
+	byCount := orderBy == model.CommentOrderMostReplies
+
+	cursorValue := func(c *cursor.Cursor) interface{} {
+		if byCount {
+			return c.Count
+		}
+		return c.Time
+	}
 
 	keys := make([]string, 0)
-	values := []interface{}{postID}
+	values := []interface{}{ids}
 	arg := 2
 
-	if after != nil {
-		keys = append(keys, fmt.Sprintf("created_at > $%d", arg))
-		parsedCursor, err := time.Parse(time.RFC3339, *after)
-		if err != nil {
-			return nil, err
-		}
-		values = append(values, parsedCursor)
-		arg++
+	// after points further along the order, before points back against it
+	afterOp, beforeOp := ">", "<"
+	if descending {
+		afterOp, beforeOp = "<", ">"
 	}
 
-	if before != nil {
-		keys = append(keys, fmt.Sprintf("created_at < $%d", arg))
-		parsedCursor, err := time.Parse(time.RFC3339, *before)
-		if err != nil {
-			return nil, err
-		}
-		values = append(values, parsedCursor)
-		arg++
+	if afterCursor != nil {
+		keys = append(keys, fmt.Sprintf("(sort_key, id) %s ($%d, $%d)", afterOp, arg, arg+1))
+		values = append(values, cursorValue(afterCursor), afterCursor.ID)
+		arg += 2
+	}
+
+	if beforeCursor != nil {
+		keys = append(keys, fmt.Sprintf("(sort_key, id) %s ($%d, $%d)", beforeOp, arg, arg+1))
+		values = append(values, cursorValue(beforeCursor), beforeCursor.ID)
+		arg += 2
+	}
+
+	limit := first
+	backwards := first == nil && last != nil
+	if backwards {
+		limit = last
+	}
+
+	order := "ASC"
+	if descending != backwards {
+		order = "DESC"
 	}
 
+	keyFilter := ""
 	if len(keys) > 0 {
-		query += " WHERE " + strings.Join(keys, " AND ")
+		keyFilter = " WHERE " + strings.Join(keys, " AND ")
 	}
 
-	if first != nil {
-		query += fmt.Sprintf(" ORDER BY created_at ASC LIMIT $%d", arg)
-		values = append(values, *first)
-		arg++
-	} else if last != nil {
-		query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", arg)
-		values = append(values, *last)
-		arg++
+	query := fmt.Sprintf(`SELECT id, post_id, user_id, parent_comment_id, body, created_at, updated_at, deleted_at, upvotes, downvotes, sort_key FROM (
+				SELECT *, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY sort_key %s, id %s) AS rn FROM (
+					SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.body, c.created_at, c.updated_at, c.deleted_at, c.upvotes, c.downvotes, %s AS sort_key
+					FROM comments c %s WHERE c.%s = ANY($1)%s
+					) c%s
+				) c`, parent, order, order, sortKey, visiblePostJoin, parent, filter, keyFilter)
+
+	if limit != nil {
+		query += fmt.Sprintf(" WHERE rn <= $%d", arg)
+		values = append(values, *limit+1)
 	}
 
+	query += " ORDER BY rn;"
+
 	rows, err := r.db.QueryContext(ctx, query, values...)
 	if err != nil {
 		return nil, err
@@ -119,92 +206,261 @@ func (r *CommentRepository) GetCommentsByPostID(ctx context.Context, postID int,
 
 	defer rows.Close()
 
-	var comments []*model.CommentEdge
-	var startCursor, endCursor *string
-	var count int
-	var hasNextPage bool
-	var hasPrevPage bool
-
-	commentMap := make(map[int]*model.Comment)
+	edges := make(map[int][]*model.CommentEdge, len(ids))
 
 	for rows.Next() {
 		var comment model.Comment
+		var sortTime time.Time
+		var sortCount int
 
-		err = rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt)
+		var sortValue interface{} = &sortTime
+		if byCount {
+			sortValue = &sortCount
+		}
+
+		err = rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt, &comment.Upvotes, &comment.Downvotes, sortValue)
 		if err != nil {
 			return nil, err
 		}
 
-		cursorStr := comment.CreatedAt.Format(time.RFC3339)
-		if count == 0 {
-			startCursor = &cursorStr
+		c := cursor.New(sortTime, comment.ID)
+		if byCount {
+			c = cursor.NewCount(sortCount, comment.ID)
 		}
-		if (first != nil && count == *first) || (last != nil && count == *last) {
-			if first != nil {
-				hasNextPage = true
-			} else {
-				hasPrevPage = true
-			}
-			break
+
+		parentID := comment.PostID
+		if parent == "parent_comment_id" {
+			parentID = *comment.ParentCommentID
 		}
-		comments = append(comments, &model.CommentEdge{
-			Cursor: cursorStr,
+
+		edges[parentID] = append(edges[parentID], &model.CommentEdge{
+			Cursor: c.Encode(),
 			Node:   &comment,
 		})
+	}
 
-		commentMap[comment.ID] = &comment
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
 
-		if comment.ParentCommentID != nil {
-			parentComment := commentMap[*comment.ParentCommentID]
-			if parentComment != nil {
-				parentComment.Replies = append(parentComment.Replies, &comment)
-			}
-		}
-		endCursor = &cursorStr
-		count++
+	connections := make(map[int]*model.CommentConnection, len(ids))
+	for _, id := range ids {
+		connections[id] = newCommentConnection(edges[id], limit, backwards)
 	}
 
-	if last != nil {
-		for i, j := 0, len(comments)-1; i < j; i, j = i+1, j-1 {
-			comments[i], comments[j] = comments[j], comments[i]
+	return connections, nil
+}
+
+// newCommentConnection builds a page out of edges fetched with one row over
+// the limit. Edges fetched backwards are returned in natural order.
+func newCommentConnection(edges []*model.CommentEdge, limit *int, backwards bool) *model.CommentConnection {
+	hasMore := limit != nil && len(edges) > *limit
+	if hasMore {
+		edges = edges[:*limit]
+	}
+
+	if backwards {
+		for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j], edges[i]
 		}
 	}
 
+	pageInfo := &model.PageInfo{
+		HasNextPage: !backwards && hasMore,
+		HasPrevPage: backwards && hasMore,
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	if edges == nil {
+		edges = []*model.CommentEdge{}
+	}
+
 	return &model.CommentConnection{
-		Edges: comments,
-		PageInfo: &model.PageInfo{
-			StartCursor: startCursor,
-			EndCursor:   endCursor,
-			HasNextPage: first != nil && hasNextPage,
-			HasPrevPage: last != nil && hasPrevPage,
-		},
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
+
+// UpdateComment updates the comment and returns the updated comment. The
+// previous body is kept as a revision when the update changes it. Comments that don't exist are reported
+// as NotFound errors, comments the actor may not modify as Forbidden errors.
+func (r *CommentRepository) UpdateComment(ctx context.Context, actor policy.Actor, req *model.UpdateCommentReq) (*model.Comment, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	err = checkCommentAccess(ctx, tx, actor, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO comment_revisions (comment_id, body) 
+						SELECT id, body FROM comments WHERE id = $1 AND body <> $2;`, req.ID, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var comment model.Comment
+
+	row := tx.QueryRowContext(ctx, `UPDATE comments SET body = $1, updated_at = NOW() WHERE id = $2 
+						RETURNING id, post_id, user_id, parent_comment_id, body, created_at, updated_at, deleted_at, upvotes, downvotes;`, req.Body, req.ID)
+	err = row.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt, &comment.Upvotes, &comment.Downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
+
+// GetCommentRevisions returns the previous bodies of the comment, newest
+// first.
+func (r *CommentRepository) GetCommentRevisions(ctx context.Context, commentID int, first *int, after *string) (*model.CommentRevisionConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, comment_id, body, created_at FROM comment_revisions WHERE comment_id = $1`
+	values := []interface{}{commentID}
+
+	if afterCursor != nil {
+		query += ` AND (created_at, id) < ($2, $3)`
+		values = append(values, afterCursor.Time, afterCursor.ID)
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	if first != nil {
+		query += fmt.Sprintf(` LIMIT $%d`, len(values)+1)
+		values = append(values, *first+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+";", values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	edges := make([]*model.CommentRevisionEdge, 0)
+
+	for rows.Next() {
+		var revision model.CommentRevision
+
+		err = rows.Scan(&revision.ID, &revision.CommentID, &revision.Body, &revision.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, &model.CommentRevisionEdge{
+			Cursor: cursor.New(revision.CreatedAt, revision.ID).Encode(),
+			Node:   &revision,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(edges) > *first {
+		edges = edges[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.CommentRevisionConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
 	}, nil
 }
 
-func (r *CommentRepository) UpdateComment(ctx context.Context, userID int, req *model.UpdateCommentReq) error {
-	_, err := r.db.ExecContext(ctx, `UPDATE comments SET body = $1 WHERE user_id = $2 AND id = $3;`, req.Body, userID, req.ID)
+// DeleteComment marks the comment as deleted. The comment stays in its thread,
+// so replies to it remain reachable.
+func (r *CommentRepository) DeleteComment(ctx context.Context, actor policy.Actor, commentID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	return err
+	defer tx.Rollback()
+
+	err = checkCommentAccess(ctx, tx, actor, commentID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE comments SET deleted_at = NOW() WHERE id = $1;`, commentID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
-func (r *CommentRepository) DeleteComment(ctx context.Context, userID, commentID int) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE user_id = $1 AND id = $2;`, userID, commentID)
+// PurgeComment permanently removes the comment together with all replies
+// under it.
+func (r *CommentRepository) PurgeComment(ctx context.Context, commentID int) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM comments WHERE id = $1;`, commentID)
 	if err != nil {
 		return err
 	}
 
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return apperror.NotFound("comment not found")
+	}
+
 	return nil
 }
 
+// checkCommentAccess locks the comment until the end of tx and makes sure the
+// actor may modify it. Deleted comments are reported as not found.
+func checkCommentAccess(ctx context.Context, tx *sql.Tx, actor policy.Actor, commentID int) error {
+	var ownerID int
+
+	row := tx.QueryRowContext(ctx, `SELECT user_id FROM comments WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, commentID)
+	err := row.Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("comment not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return policy.CanModifyComment(actor, ownerID)
+}
+
 func (r *CommentRepository) IsCommentsAllowed(ctx context.Context, postID int) (bool, error) {
 	var allowComments bool
 
-	row := r.db.QueryRowContext(ctx, `SELECT allow_comments FROM posts WHERE id = $1;`, postID)
+	row := r.db.QueryRowContext(ctx, `SELECT allow_comments AND NOT locked FROM posts WHERE id = $1 AND deleted_at IS NULL AND status = 'PUBLISHED';`, postID)
 
 	err := row.Scan(&allowComments)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, apperror.NotFound("post not found")
+	}
 	if err != nil {
 		return false, err
 	}
@@ -6,6 +6,10 @@ import (
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/internal/repository/repotest"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -21,7 +25,7 @@ type CommentRepositorySuite struct {
 
 func (suite *CommentRepositorySuite) SetupTest() {
 	var err error
-	suite.db, suite.mock, err = sqlmock.New()
+	suite.db, suite.mock, err = sqlmock.New(sqlmock.ValueConverterOption(repotest.SliceConverter{}))
 	assert.NoError(suite.T(), err)
 	suite.repo = NewCommentRepository(suite.db)
 }
@@ -69,67 +73,183 @@ func (suite *CommentRepositorySuite) TestRepository_CreateCommentEmptyFields() {
 // ================================================================
 
 func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDSuccess() {
-	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at"}).
-		AddRow(1, 1, 1, nil, "test1", time.Now()).
-		AddRow(2, 1, 2, 1, "reply", time.Now().Add(time.Minute))
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(1, 1, 1, nil, "test1", time.Now(), nil, nil, 0, 0, time.Now()).
+		AddRow(2, 1, 2, nil, "test2", time.Now().Add(time.Minute), nil, nil, 0, 0, time.Now().Add(time.Minute)).
+		AddRow(3, 1, 2, nil, "test3", time.Now().Add(2*time.Minute), nil, nil, 0, 0, time.Now().Add(2*time.Minute))
 
 	first := 2
-	suite.mock.ExpectQuery("WITH RECURSIVE comment_tree").
-		WithArgs(1, &first).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`PARTITION BY post_id ORDER BY sort_key ASC, id ASC(.+)c.created_at AS sort_key(.+)JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED' WHERE c.post_id = ANY\(\$1\) AND c.parent_comment_id IS NULL(.+)WHERE rn <= \$2 ORDER BY rn`).
+		WithArgs([]int{1}, first+1).WillReturnRows(rows)
 
-	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, nil, nil)
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, nil, nil, model.CommentOrderOldest)
 
-	suite.NotNil(comments)
 	suite.Nil(err)
+	suite.Len(comments.Edges, 2)
+	suite.Equal(1, comments.Edges[0].Node.ID)
+	suite.Equal(2, comments.Edges[1].Node.ID)
+	suite.Equal(comments.Edges[1].Cursor, *comments.PageInfo.EndCursor)
+	suite.True(comments.PageInfo.HasNextPage)
+	suite.False(comments.PageInfo.HasPrevPage)
 }
 
 func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDWithCursorsSuccess() {
-	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at"}).
-		AddRow(1, 1, 1, nil, "test1", time.Now()).
-		AddRow(2, 1, 2, 1, "reply", time.Now().Add(time.Minute)).
-		AddRow(3, 1, 3, nil, "third comment", time.Now().Add(time.Hour)).
-		AddRow(4, 1, 3, 1, "second reply", time.Now().Add(3*time.Hour))
+	createdAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(3, 1, 3, nil, "third comment", createdAt, nil, nil, 0, 0, createdAt)
 
 	first := 2
-	after := time.Now().Format(time.RFC3339)
-	before := time.Now().Add(2 * time.Hour).Format(time.RFC3339)
+	after := cursor.New(createdAt, 2).Encode()
+	before := cursor.New(createdAt.Add(time.Hour), 7).Encode()
 
-	afterTime, _ := time.Parse(time.RFC3339, after)
-	beforeTime, _ := time.Parse(time.RFC3339, before)
+	suite.mock.ExpectQuery(`c WHERE \(sort_key, id\) > \(\$2, \$3\) AND \(sort_key, id\) < \(\$4, \$5\)(.+)WHERE rn <= \$6`).
+		WithArgs([]int{1}, sqlmock.AnyArg(), 2, sqlmock.AnyArg(), 7, first+1).WillReturnRows(rows)
 
-	suite.mock.ExpectQuery("WITH RECURSIVE comment_tree").
-		WithArgs(1, afterTime, beforeTime, first).WillReturnRows(rows)
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, &before, model.CommentOrderOldest)
 
-	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, &before)
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+	suite.False(comments.PageInfo.HasNextPage)
 
-	suite.NotNil(comments)
+	endCursor, err := cursor.Decode(*comments.PageInfo.EndCursor)
 	suite.Nil(err)
+	suite.Equal(3, endCursor.ID)
+	suite.True(createdAt.Equal(endCursor.Time))
 }
 
-func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDFailure() {
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDLast() {
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(3, 1, 1, nil, "test3", time.Now().Add(2*time.Minute), nil, nil, 0, 0, time.Now().Add(2*time.Minute)).
+		AddRow(2, 1, 1, nil, "test2", time.Now().Add(time.Minute), nil, nil, 0, 0, time.Now().Add(time.Minute)).
+		AddRow(1, 1, 1, nil, "test1", time.Now(), nil, nil, 0, 0, time.Now())
+
+	last := 2
+	suite.mock.ExpectQuery(`ORDER BY sort_key DESC, id DESC(.+)WHERE rn <= \$2`).
+		WithArgs([]int{1}, last+1).WillReturnRows(rows)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, nil, &last, nil, nil, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(comments.Edges, 2)
+	suite.Equal(2, comments.Edges[0].Node.ID)
+	suite.Equal(3, comments.Edges[1].Node.ID)
+	suite.False(comments.PageInfo.HasNextPage)
+	suite.True(comments.PageInfo.HasPrevPage)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDInvalidCursor() {
 	first := 2
-	after := time.Now().Format(time.RFC3339)
-	before := time.Now().Add(2 + time.Hour).Format(time.RFC3339)
+	after := "invalid"
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, nil, model.CommentOrderOldest)
+
+	suite.Nil(comments)
+	suite.NotNil(err)
+}
 
-	afterTime, _ := time.Parse(time.RFC3339, after)
-	beforeTime, _ := time.Parse(time.RFC3339, before)
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDFailure() {
+	first := 2
+	after := cursor.New(time.Now(), 1).Encode()
 
-	suite.mock.ExpectQuery("WITH RECURSIVE comment_tree").
-		WithArgs(1, afterTime, beforeTime, first).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectQuery("SELECT (.+) FROM comments WHERE (.+)").
+		WithArgs([]int{1}, sqlmock.AnyArg(), 1, first+1).WillReturnError(sql.ErrNoRows)
 
-	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, &before)
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, nil, model.CommentOrderOldest)
 
 	suite.Nil(comments)
 	suite.NotNil(err)
 }
 
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDMostReplies() {
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(4, 1, 1, nil, "test4", time.Now(), nil, nil, 0, 0, 2).
+		AddRow(5, 1, 1, nil, "test5", time.Now(), nil, nil, 0, 0, 1)
+
+	first := 2
+	after := cursor.NewCount(3, 2).Encode()
+
+	suite.mock.ExpectQuery(`PARTITION BY post_id ORDER BY sort_key DESC, id DESC(.+)SELECT COUNT\(\*\) FROM comments r WHERE r.parent_comment_id = c.id(.+)c WHERE \(sort_key, id\) < \(\$2, \$3\)`).
+		WithArgs([]int{1}, 3, 2, first+1).WillReturnRows(rows)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, &first, nil, &after, nil, model.CommentOrderMostReplies)
+
+	suite.Nil(err)
+	suite.Len(comments.Edges, 2)
+	suite.False(comments.PageInfo.HasNextPage)
+
+	endCursor, err := cursor.Decode(*comments.PageInfo.EndCursor)
+	suite.Nil(err)
+	suite.Equal(5, endCursor.ID)
+	suite.Equal(1, endCursor.Count)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDRecentActivity() {
+	activity := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(1, 1, 1, nil, "test1", activity.Add(-time.Hour), nil, nil, 0, 0, activity)
+
+	suite.mock.ExpectQuery(`ORDER BY sort_key DESC, id DESC(.+)WITH RECURSIVE subtree(.+)SELECT MAX\(created_at\) FROM subtree\) AS sort_key`).
+		WithArgs([]int{1}).WillReturnRows(rows)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), 1, nil, nil, nil, nil, model.CommentOrderRecentActivity)
+
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+
+	endCursor, err := cursor.Decode(*comments.PageInfo.EndCursor)
+	suite.Nil(err)
+	suite.True(activity.Equal(endCursor.Time))
+}
+
+// GetCommentsByPostIDs
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDsSinglePageEach() {
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(1, 1, 1, nil, "post 1", time.Now(), nil, nil, 0, 0, time.Now()).
+		AddRow(3, 2, 1, nil, "post 2", time.Now(), nil, nil, 0, 0, time.Now()).
+		AddRow(2, 1, 1, nil, "post 1 again", time.Now(), nil, nil, 0, 0, time.Now())
+
+	first := 1
+	suite.mock.ExpectQuery(`PARTITION BY post_id(.+)WHERE c.post_id = ANY\(\$1\)(.+)WHERE rn <= \$2`).
+		WithArgs([]int{1, 2, 3}, first+1).WillReturnRows(rows)
+
+	connections, err := suite.repo.GetCommentsByPostIDs(context.Background(), []int{1, 2, 3}, &first, nil, nil, nil, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(connections, 3)
+	suite.Equal(1, connections[1].Edges[0].Node.ID)
+	suite.True(connections[1].PageInfo.HasNextPage)
+	suite.Equal(3, connections[2].Edges[0].Node.ID)
+	suite.False(connections[2].PageInfo.HasNextPage)
+	suite.Empty(connections[3].Edges)
+}
+
+// GetRepliesByCommentIDs
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_GetRepliesByCommentIDsSuccess() {
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes", "sort_key"}).
+		AddRow(2, 1, 2, 1, "reply", time.Now(), nil, nil, 0, 0, time.Now())
+
+	suite.mock.ExpectQuery(`PARTITION BY parent_comment_id(.+)WHERE c.parent_comment_id = ANY\(\$1\)\s+\) c\s+\) c ORDER BY rn`).
+		WithArgs([]int{1}).WillReturnRows(rows)
+
+	replies, err := suite.repo.GetRepliesByCommentIDs(context.Background(), []int{1}, nil, nil, nil, nil, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(replies[1].Edges, 1)
+	suite.Equal(1, *replies[1].Edges[0].Node.ParentCommentID)
+	suite.False(replies[1].PageInfo.HasNextPage)
+}
+
 // GetCommentByID
 // ================================================================
 
 func (suite *CommentRepositorySuite) TestRepository_GetCommentByIDSuccess() {
-	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "body", "created_at", "parent_comment_id"}).
-		AddRow(1, 1, 1, nil, "test", time.Now())
-	suite.mock.ExpectQuery("SELECT (.+) FROM comments WHERE (.+)").
+	rows := sqlmock.NewRows([]string{"id", "post_id", "user_id", "body", "created_at", "parent_comment_id", "updated_at", "deleted_at", "upvotes", "downvotes"}).
+		AddRow(1, 1, 1, nil, "test", time.Now(), nil, nil, 0, 0)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM comments c JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED' WHERE c.id = \$1;`).
 		WithArgs(1).WillReturnRows(rows)
 
 	comment, err := suite.repo.GetCommentByID(context.Background(), 1)
@@ -157,44 +277,158 @@ func (suite *CommentRepositorySuite) TestRepository_UpdateCommentSuccess() {
 		Body: "test",
 	}
 
-	suite.mock.ExpectExec("UPDATE comments SET (.+) WHERE (.+)").
-		WithArgs("test", 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	suite.mock.ExpectExec(`INSERT INTO comment_revisions \(comment_id, body\)\s+SELECT id, body FROM comments WHERE id = \$1 AND body <> \$2;`).
+		WithArgs(1, "test").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectQuery(`UPDATE comments SET body = \$1, updated_at = NOW\(\) WHERE id = \$2\s+RETURNING (.+)`).
+		WithArgs("test", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, 1, nil, "test", time.Now(), time.Now(), nil, 0, 0))
+	suite.mock.ExpectCommit()
 
-	err := suite.repo.UpdateComment(context.Background(), 1, req)
+	comment, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, req)
 
 	suite.Nil(err)
+	suite.Equal("test", comment.Body)
+	suite.True(comment.IsEdited())
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
-func (suite *CommentRepositorySuite) TestRepository_UpdateCommentEmptyBody() {
-	req := &model.UpdateCommentReq{
-		ID: 1,
-	}
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	comment, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: 1, Body: "test"})
+
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
 
-	suite.mock.ExpectExec("UPDATE comments SET (.+) WHERE (.+)").
-		WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentForbidden() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.UpdateComment(context.Background(), 1, req)
+	comment, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: 1, Body: "test"})
 
-	suite.NotNil(err)
+	suite.Nil(comment)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentByModerator() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+	suite.mock.ExpectExec(`INSERT INTO comment_revisions`).
+		WithArgs(1, "test").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectQuery(`UPDATE comments SET body = \$1(.+)`).
+		WithArgs("test", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, 2, nil, "test", time.Now(), time.Now(), nil, 0, 0))
+	suite.mock.ExpectCommit()
+
+	comment, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1, Role: model.RoleModerator}, &model.UpdateCommentReq{ID: 1, Body: "test"})
+
+	suite.Nil(err)
+	suite.Equal(2, comment.UserID)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// GetCommentRevisions
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentRevisionsSuccess() {
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "comment_id", "body", "created_at"}).
+		AddRow(2, 1, "second", createdAt).
+		AddRow(1, 1, "first", createdAt)
+	suite.mock.ExpectQuery(`SELECT id, comment_id, body, created_at FROM comment_revisions WHERE comment_id = \$1 ORDER BY created_at DESC, id DESC LIMIT \$2;`).
+		WithArgs(1, 2).WillReturnRows(rows)
+
+	first := 1
+	revisions, err := suite.repo.GetCommentRevisions(context.Background(), 1, &first, nil)
+
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 1)
+	suite.Equal("second", revisions.Edges[0].Node.Body)
+	suite.True(revisions.PageInfo.HasNextPage)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentRevisionsInvalidCursor() {
+	after := "invalid"
+
+	revisions, err := suite.repo.GetCommentRevisions(context.Background(), 1, nil, &after)
+
+	suite.Nil(revisions)
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
 }
 
 // DeleteComment
 // ========================================================================================
 
 func (suite *CommentRepositorySuite) TestRepository_DeleteCommentSuccess() {
-	suite.mock.ExpectExec("DELETE FROM comments WHERE (.+)").
-		WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	suite.mock.ExpectExec(`UPDATE comments SET deleted_at = NOW\(\) WHERE id = \$1;`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
 
-	err := suite.repo.DeleteComment(context.Background(), 1, 1)
+	err := suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, 1)
 
 	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
-func (suite *CommentRepositorySuite) TestRepository_DeleteCommentFailure() {
-	suite.mock.ExpectExec("DELETE FROM comments WHERE (.+)").
-		WithArgs(1, 1).WillReturnError(sql.ErrNoRows)
+func (suite *CommentRepositorySuite) TestRepository_DeleteCommentNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.DeleteComment(context.Background(), 1, 1)
+	err := suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, 1)
 
-	suite.NotNil(err)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *CommentRepositorySuite) TestRepository_DeleteCommentForbidden() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM comments (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, 1)
+
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// PurgeComment
+// ========================================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_PurgeCommentSuccess() {
+	suite.mock.ExpectExec(`DELETE FROM comments WHERE id = \$1;`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := suite.repo.PurgeComment(context.Background(), 1)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *CommentRepositorySuite) TestRepository_PurgeCommentNotFound() {
+	suite.mock.ExpectExec(`DELETE FROM comments WHERE id = \$1;`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.PurgeComment(context.Background(), 1)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
 }
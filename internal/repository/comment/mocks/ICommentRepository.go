@@ -4,9 +4,11 @@ package mocks
 
 import (
 	context "context"
-	"github.com/aaanger/graphql-test/internal/graph/model"
 
+	model "github.com/aaanger/graphql-test/internal/graph/model"
 	mock "github.com/stretchr/testify/mock"
+
+	policy "github.com/aaanger/graphql-test/internal/policy"
 )
 
 // ICommentRepository is an autogenerated mock type for the ICommentRepository type
@@ -44,17 +46,17 @@ func (_m *ICommentRepository) CreateComment(ctx context.Context, userID int, req
 	return r0, r1
 }
 
-// DeleteComment provides a mock function with given fields: ctx, userID, commentID
-func (_m *ICommentRepository) DeleteComment(ctx context.Context, userID int, commentID int) error {
-	ret := _m.Called(ctx, userID, commentID)
+// DeleteComment provides a mock function with given fields: ctx, actor, commentID
+func (_m *ICommentRepository) DeleteComment(ctx context.Context, actor policy.Actor, commentID int) error {
+	ret := _m.Called(ctx, actor, commentID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for DeleteComment")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int) error); ok {
-		r0 = rf(ctx, userID, commentID)
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int) error); ok {
+		r0 = rf(ctx, actor, commentID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -92,9 +94,39 @@ func (_m *ICommentRepository) GetCommentByID(ctx context.Context, id int) (*mode
 	return r0, r1
 }
 
-// GetCommentsByPostID provides a mock function with given fields: ctx, postID, first, last, after, before
-func (_m *ICommentRepository) GetCommentsByPostID(ctx context.Context, postID int, first *int, last *int, after *string, before *string) (*model.CommentConnection, error) {
-	ret := _m.Called(ctx, postID, first, last, after, before)
+// GetCommentRevisions provides a mock function with given fields: ctx, commentID, first, after
+func (_m *ICommentRepository) GetCommentRevisions(ctx context.Context, commentID int, first *int, after *string) (*model.CommentRevisionConnection, error) {
+	ret := _m.Called(ctx, commentID, first, after)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommentRevisions")
+	}
+
+	var r0 *model.CommentRevisionConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *string) (*model.CommentRevisionConnection, error)); ok {
+		return rf(ctx, commentID, first, after)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *string) *model.CommentRevisionConnection); ok {
+		r0 = rf(ctx, commentID, first, after)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.CommentRevisionConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *int, *string) error); ok {
+		r1 = rf(ctx, commentID, first, after)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCommentsByPostID provides a mock function with given fields: ctx, postID, first, last, after, before, orderBy
+func (_m *ICommentRepository) GetCommentsByPostID(ctx context.Context, postID int, first *int, last *int, after *string, before *string, orderBy model.CommentOrder) (*model.CommentConnection, error) {
+	ret := _m.Called(ctx, postID, first, last, after, before, orderBy)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetCommentsByPostID")
@@ -102,19 +134,79 @@ func (_m *ICommentRepository) GetCommentsByPostID(ctx context.Context, postID in
 
 	var r0 *model.CommentConnection
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *int, *string, *string) (*model.CommentConnection, error)); ok {
-		return rf(ctx, postID, first, last, after, before)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *int, *string, *string, model.CommentOrder) (*model.CommentConnection, error)); ok {
+		return rf(ctx, postID, first, last, after, before, orderBy)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *int, *string, *string) *model.CommentConnection); ok {
-		r0 = rf(ctx, postID, first, last, after, before)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *int, *string, *string, model.CommentOrder) *model.CommentConnection); ok {
+		r0 = rf(ctx, postID, first, last, after, before, orderBy)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*model.CommentConnection)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, *int, *int, *string, *string) error); ok {
-		r1 = rf(ctx, postID, first, last, after, before)
+	if rf, ok := ret.Get(1).(func(context.Context, int, *int, *int, *string, *string, model.CommentOrder) error); ok {
+		r1 = rf(ctx, postID, first, last, after, before, orderBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCommentsByPostIDs provides a mock function with given fields: ctx, postIDs, first, last, after, before, orderBy
+func (_m *ICommentRepository) GetCommentsByPostIDs(ctx context.Context, postIDs []int, first *int, last *int, after *string, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	ret := _m.Called(ctx, postIDs, first, last, after, before, orderBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetCommentsByPostIDs")
+	}
+
+	var r0 map[int]*model.CommentConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) (map[int]*model.CommentConnection, error)); ok {
+		return rf(ctx, postIDs, first, last, after, before, orderBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) map[int]*model.CommentConnection); ok {
+		r0 = rf(ctx, postIDs, first, last, after, before, orderBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int]*model.CommentConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) error); ok {
+		r1 = rf(ctx, postIDs, first, last, after, before, orderBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRepliesByCommentIDs provides a mock function with given fields: ctx, commentIDs, first, last, after, before, orderBy
+func (_m *ICommentRepository) GetRepliesByCommentIDs(ctx context.Context, commentIDs []int, first *int, last *int, after *string, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	ret := _m.Called(ctx, commentIDs, first, last, after, before, orderBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRepliesByCommentIDs")
+	}
+
+	var r0 map[int]*model.CommentConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) (map[int]*model.CommentConnection, error)); ok {
+		return rf(ctx, commentIDs, first, last, after, before, orderBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) map[int]*model.CommentConnection); ok {
+		r0 = rf(ctx, commentIDs, first, last, after, before, orderBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int]*model.CommentConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int, *int, *int, *string, *string, model.CommentOrder) error); ok {
+		r1 = rf(ctx, commentIDs, first, last, after, before, orderBy)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -150,17 +242,17 @@ func (_m *ICommentRepository) IsCommentsAllowed(ctx context.Context, postID int)
 	return r0, r1
 }
 
-// UpdateComment provides a mock function with given fields: ctx, userID, req
-func (_m *ICommentRepository) UpdateComment(ctx context.Context, userID int, req *model.UpdateCommentReq) error {
-	ret := _m.Called(ctx, userID, req)
+// PurgeComment provides a mock function with given fields: ctx, commentID
+func (_m *ICommentRepository) PurgeComment(ctx context.Context, commentID int) error {
+	ret := _m.Called(ctx, commentID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for UpdateComment")
+		panic("no return value specified for PurgeComment")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, *model.UpdateCommentReq) error); ok {
-		r0 = rf(ctx, userID, req)
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, commentID)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -168,6 +260,36 @@ func (_m *ICommentRepository) UpdateComment(ctx context.Context, userID int, req
 	return r0
 }
 
+// UpdateComment provides a mock function with given fields: ctx, actor, req
+func (_m *ICommentRepository) UpdateComment(ctx context.Context, actor policy.Actor, req *model.UpdateCommentReq) (*model.Comment, error) {
+	ret := _m.Called(ctx, actor, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateComment")
+	}
+
+	var r0 *model.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, *model.UpdateCommentReq) (*model.Comment, error)); ok {
+		return rf(ctx, actor, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, *model.UpdateCommentReq) *model.Comment); ok {
+		r0 = rf(ctx, actor, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Actor, *model.UpdateCommentReq) error); ok {
+		r1 = rf(ctx, actor, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewICommentRepository creates a new instance of ICommentRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewICommentRepository(t interface {
@@ -0,0 +1,421 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"sort"
+	"time"
+)
+
+const maxCommentLength = 2000
+
+type CommentRepository struct {
+	s *Storage
+}
+
+func NewCommentRepository(s *Storage) *CommentRepository {
+	return &CommentRepository{
+		s: s,
+	}
+}
+
+func (r *CommentRepository) CreateComment(ctx context.Context, userID int, req *model.CreateCommentReq) (*model.Comment, error) {
+	if len(req.Body) > maxCommentLength {
+		return nil, apperror.Validation("comment must be less than 2000 chars")
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	if post, ok := r.s.posts[req.PostID]; !ok || !isVisible(post) {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	if req.ParentCommentID != nil {
+		if _, ok := r.s.comments[*req.ParentCommentID]; !ok {
+			return nil, apperror.NotFound("parent comment not found")
+		}
+	}
+
+	r.s.lastCommentID++
+	comment := &model.Comment{
+		ID:              r.s.lastCommentID,
+		PostID:          req.PostID,
+		UserID:          userID,
+		ParentCommentID: req.ParentCommentID,
+		Body:            req.Body,
+		CreatedAt:       time.Now(),
+	}
+	r.s.comments[comment.ID] = comment
+
+	view := *comment
+
+	return &view, nil
+}
+
+func (r *CommentRepository) GetCommentByID(ctx context.Context, id int) (*model.Comment, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	comment, ok := r.s.comments[id]
+	if !ok || !r.s.isCommentVisible(comment) {
+		return nil, apperror.NotFound("comment not found")
+	}
+
+	view := *comment
+
+	return &view, nil
+}
+
+func (r *CommentRepository) GetCommentsByPostID(ctx context.Context, postID int, first, last *int, after, before *string, orderBy model.CommentOrder) (*model.CommentConnection, error) {
+	return r.getComments(func(c *model.Comment) bool {
+		return c.PostID == postID && c.ParentCommentID == nil
+	}, first, last, after, before, orderBy)
+}
+
+func (r *CommentRepository) GetCommentsByPostIDs(ctx context.Context, postIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	connections := make(map[int]*model.CommentConnection, len(postIDs))
+
+	for _, postID := range postIDs {
+		connection, err := r.GetCommentsByPostID(ctx, postID, first, last, after, before, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		connections[postID] = connection
+	}
+
+	return connections, nil
+}
+
+func (r *CommentRepository) GetRepliesByCommentIDs(ctx context.Context, commentIDs []int, first, last *int, after, before *string, orderBy model.CommentOrder) (map[int]*model.CommentConnection, error) {
+	connections := make(map[int]*model.CommentConnection, len(commentIDs))
+
+	for _, commentID := range commentIDs {
+		connection, err := r.getComments(func(c *model.Comment) bool {
+			return c.ParentCommentID != nil && *c.ParentCommentID == commentID
+		}, first, last, after, before, orderBy)
+		if err != nil {
+			return nil, err
+		}
+		connections[commentID] = connection
+	}
+
+	return connections, nil
+}
+
+func (r *CommentRepository) getComments(match func(c *model.Comment) bool, first, last *int, after, before *string, orderBy model.CommentOrder) (*model.CommentConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCursor, err := cursor.DecodeOptional(before)
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+
+	replies := make(map[int][]*model.Comment)
+	for _, comment := range r.s.comments {
+		if comment.ParentCommentID != nil {
+			replies[*comment.ParentCommentID] = append(replies[*comment.ParentCommentID], comment)
+		}
+	}
+
+	// position places a comment in the requested order, so that sorting by
+	// it and comparing against cursors share the same key
+	position := func(comment *model.Comment) cursor.Cursor {
+		switch orderBy {
+		case model.CommentOrderMostReplies:
+			count := 0
+			for _, reply := range replies[comment.ID] {
+				if !reply.IsDeleted() {
+					count++
+				}
+			}
+			return cursor.NewCount(count, comment.ID)
+		case model.CommentOrderRecentActivity:
+			return cursor.New(latestActivity(comment, replies), comment.ID)
+		default:
+			return cursor.New(comment.CreatedAt, comment.ID)
+		}
+	}
+
+	less := func(a, b cursor.Cursor) bool {
+		if orderBy == model.CommentOrderOldest {
+			return a.Before(b)
+		}
+		return b.Before(a)
+	}
+
+	positions := make(map[int]cursor.Cursor)
+
+	var comments []*model.Comment
+	for _, comment := range r.s.comments {
+		if !match(comment) || !r.s.isCommentVisible(comment) {
+			continue
+		}
+		p := position(comment)
+		if afterCursor != nil && !less(*afterCursor, p) {
+			continue
+		}
+		if beforeCursor != nil && !less(p, *beforeCursor) {
+			continue
+		}
+		positions[comment.ID] = p
+		view := *comment
+		comments = append(comments, &view)
+	}
+
+	r.s.mu.RUnlock()
+
+	sort.Slice(comments, func(i, j int) bool {
+		return less(positions[comments[i].ID], positions[comments[j].ID])
+	})
+
+	var hasNextPage, hasPrevPage bool
+
+	if first != nil {
+		if len(comments) > *first {
+			comments = comments[:*first]
+			hasNextPage = true
+		}
+	} else if last != nil {
+		if len(comments) > *last {
+			comments = comments[len(comments)-*last:]
+			hasPrevPage = true
+		}
+	}
+
+	edges := make([]*model.CommentEdge, 0, len(comments))
+	var startCursor, endCursor *string
+
+	for i, comment := range comments {
+		cursorStr := positions[comment.ID].Encode()
+		if i == 0 {
+			startCursor = &cursorStr
+		}
+		endCursor = &cursorStr
+
+		edges = append(edges, &model.CommentEdge{
+			Cursor: cursorStr,
+			Node:   comment,
+		})
+	}
+
+	return &model.CommentConnection{
+		Edges: edges,
+		PageInfo: &model.PageInfo{
+			StartCursor: startCursor,
+			EndCursor:   endCursor,
+			HasNextPage: hasNextPage,
+			HasPrevPage: hasPrevPage,
+		},
+	}, nil
+}
+
+// latestActivity returns the creation time of the newest comment in the
+// subtree of comment, the comment itself included.
+func latestActivity(comment *model.Comment, replies map[int][]*model.Comment) time.Time {
+	latest := comment.CreatedAt
+
+	for _, reply := range replies[comment.ID] {
+		if t := latestActivity(reply, replies); t.After(latest) {
+			latest = t
+		}
+	}
+
+	return latest
+}
+
+func (r *CommentRepository) UpdateComment(ctx context.Context, actor policy.Actor, req *model.UpdateCommentReq) (*model.Comment, error) {
+	if len(req.Body) > maxCommentLength {
+		return nil, apperror.Validation("comment must be less than 2000 chars")
+	}
+
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, err := r.s.modifiableComment(actor, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Body != comment.Body {
+		r.s.saveCommentRevision(comment)
+	}
+
+	now := time.Now()
+	comment.Body = req.Body
+	comment.UpdatedAt = &now
+
+	view := *comment
+
+	return &view, nil
+}
+
+func (r *CommentRepository) DeleteComment(ctx context.Context, actor policy.Actor, commentID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, err := r.s.modifiableComment(actor, commentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	comment.DeletedAt = &now
+
+	return nil
+}
+
+func (r *CommentRepository) PurgeComment(ctx context.Context, commentID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.comments[commentID]; !ok {
+		return apperror.NotFound("comment not found")
+	}
+
+	r.s.purgeComment(commentID)
+
+	return nil
+}
+
+func (r *CommentRepository) GetCommentRevisions(ctx context.Context, commentID int, first *int, after *string) (*model.CommentRevisionConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+
+	var revisions []*model.CommentRevision
+	for _, revision := range r.s.commentRevisions {
+		if revision.CommentID != commentID {
+			continue
+		}
+		if afterCursor != nil && !cursor.New(revision.CreatedAt, revision.ID).Before(*afterCursor) {
+			continue
+		}
+
+		view := *revision
+		revisions = append(revisions, &view)
+	}
+
+	r.s.mu.RUnlock()
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return cursor.New(revisions[j].CreatedAt, revisions[j].ID).Before(cursor.New(revisions[i].CreatedAt, revisions[i].ID))
+	})
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(revisions) > *first {
+		revisions = revisions[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	edges := make([]*model.CommentRevisionEdge, 0, len(revisions))
+
+	for i, revision := range revisions {
+		cursorStr := cursor.New(revision.CreatedAt, revision.ID).Encode()
+		if i == 0 {
+			pageInfo.StartCursor = &cursorStr
+		}
+		pageInfo.EndCursor = &cursorStr
+
+		edges = append(edges, &model.CommentRevisionEdge{
+			Cursor: cursorStr,
+			Node:   revision,
+		})
+	}
+
+	return &model.CommentRevisionConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (r *CommentRepository) IsCommentsAllowed(ctx context.Context, postID int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok || !isVisible(post) {
+		return false, apperror.NotFound("post not found")
+	}
+
+	return post.AllowComments && !post.IsLocked, nil
+}
+
+// purgeComment removes the comment and all replies under it. The caller must
+// hold s.mu.
+func (s *Storage) purgeComment(commentID int) {
+	delete(s.comments, commentID)
+	s.deleteCommentRevisions(commentID)
+	deleteVotes(s.commentVotes, commentID)
+	s.deleteReactions(commentID)
+	s.detachReports(0, commentID)
+
+	for id, comment := range s.comments {
+		if comment.ParentCommentID != nil && *comment.ParentCommentID == commentID {
+			s.purgeComment(id)
+		}
+	}
+}
+
+// isCommentVisible reports whether the post of the comment is visible, so
+// deleted and hidden posts take their threads with them. The caller must
+// hold s.mu.
+func (s *Storage) isCommentVisible(comment *model.Comment) bool {
+	post, ok := s.posts[comment.PostID]
+
+	return ok && isVisible(post)
+}
+
+// saveCommentRevision keeps the current body of the comment before it is
+// overwritten. The caller must hold s.mu.
+func (s *Storage) saveCommentRevision(comment *model.Comment) {
+	s.lastCommentRevisionID++
+	s.commentRevisions[s.lastCommentRevisionID] = &model.CommentRevision{
+		ID:        s.lastCommentRevisionID,
+		CommentID: comment.ID,
+		Body:      comment.Body,
+		CreatedAt: time.Now(),
+	}
+}
+
+// deleteCommentRevisions removes the revisions of a deleted comment. The
+// caller must hold s.mu.
+func (s *Storage) deleteCommentRevisions(commentID int) {
+	for id, revision := range s.commentRevisions {
+		if revision.CommentID == commentID {
+			delete(s.commentRevisions, id)
+		}
+	}
+}
+
+// modifiableComment returns the stored comment if the actor may modify it.
+// Deleted comments are reported as not found. The caller must hold s.mu.
+func (s *Storage) modifiableComment(actor policy.Actor, commentID int) (*model.Comment, error) {
+	comment, ok := s.comments[commentID]
+	if !ok || comment.IsDeleted() {
+		return nil, apperror.NotFound("comment not found")
+	}
+
+	err := policy.CanModifyComment(actor, comment.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
@@ -0,0 +1,422 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type CommentRepositorySuite struct {
+	suite.Suite
+	storage *Storage
+	repo    *CommentRepository
+	postID  int
+}
+
+func (suite *CommentRepositorySuite) SetupTest() {
+	suite.storage = NewStorage()
+	suite.repo = NewCommentRepository(suite.storage)
+
+	_, err := NewUserRepository(suite.storage).Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+
+	post, err := NewPostRepository(suite.storage).CreatePost(context.Background(), 1, &model.CreatePostReq{
+		Title:         "test",
+		Body:          "test",
+		AllowComments: true,
+	})
+	suite.Require().NoError(err)
+
+	suite.postID = post.ID
+}
+
+func TestCommentRepositorySuite(t *testing.T) {
+	suite.Run(t, new(CommentRepositorySuite))
+}
+
+// CreateComment
+// =====================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_CreateCommentUnknownParent() {
+	parentID := 10
+
+	comment, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{
+		PostID:          suite.postID,
+		ParentCommentID: &parentID,
+		Body:            "test",
+	})
+
+	suite.Nil(comment)
+	suite.NotNil(err)
+}
+
+// GetComments
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDPagination() {
+	base := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		comment, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{
+			PostID: suite.postID,
+			Body:   "test",
+		})
+		suite.Require().NoError(err)
+		suite.storage.comments[comment.ID].CreatedAt = base.Add(time.Duration(i) * time.Minute)
+	}
+
+	first := 2
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, &first, nil, nil, nil, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(comments.Edges, 2)
+	suite.Equal(1, comments.Edges[0].Node.ID)
+	suite.True(comments.PageInfo.HasNextPage)
+
+	comments, err = suite.repo.GetCommentsByPostID(context.Background(), suite.postID, &first, nil, comments.PageInfo.EndCursor, nil, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+	suite.Equal(3, comments.Edges[0].Node.ID)
+	suite.False(comments.PageInfo.HasNextPage)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsSplitsTopLevelAndReplies() {
+	parent, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "parent"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{
+		PostID:          suite.postID,
+		ParentCommentID: &parent.ID,
+		Body:            "reply",
+	})
+	suite.Require().NoError(err)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+	suite.Equal("parent", comments.Edges[0].Node.Body)
+
+	replies, err := suite.repo.GetRepliesByCommentIDs(context.Background(), []int{parent.ID}, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Len(replies[parent.ID].Edges, 1)
+	suite.Equal("reply", replies[parent.ID].Edges[0].Node.Body)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDSameTimestamp() {
+	createdAt := time.Now()
+	for i := 0; i < 4; i++ {
+		comment, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{
+			PostID: suite.postID,
+			Body:   "test",
+		})
+		suite.Require().NoError(err)
+		suite.storage.comments[comment.ID].CreatedAt = createdAt
+	}
+
+	var ids []int
+	first := 1
+	var after *string
+	for {
+		comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, &first, nil, after, nil, model.CommentOrderOldest)
+		suite.Require().NoError(err)
+
+		for _, edge := range comments.Edges {
+			ids = append(ids, edge.Node.ID)
+		}
+		if !comments.PageInfo.HasNextPage {
+			break
+		}
+		after = comments.PageInfo.EndCursor
+	}
+
+	suite.Equal([]int{1, 2, 3, 4}, ids)
+
+	last := 2
+	before := cursor.New(createdAt, 4).Encode()
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, &last, nil, &before, model.CommentOrderOldest)
+
+	suite.Nil(err)
+	suite.Equal(2, comments.Edges[0].Node.ID)
+	suite.Equal(3, comments.Edges[1].Node.ID)
+	suite.True(comments.PageInfo.HasPrevPage)
+}
+
+// commentTree creates three top-level comments one minute apart. The first
+// gets two replies, the second gets one reply with a nested reply made last.
+func (suite *CommentRepositorySuite) commentTree() {
+	base := time.Now().Add(-time.Hour)
+	create := func(parentID *int, minute int) int {
+		comment, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{
+			PostID:          suite.postID,
+			ParentCommentID: parentID,
+			Body:            "test",
+		})
+		suite.Require().NoError(err)
+		suite.storage.comments[comment.ID].CreatedAt = base.Add(time.Duration(minute) * time.Minute)
+		return comment.ID
+	}
+
+	first := create(nil, 0)
+	second := create(nil, 1)
+	create(nil, 2)
+	create(&first, 3)
+	create(&first, 4)
+	reply := create(&second, 5)
+	create(&reply, 6)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDOrders() {
+	suite.commentTree()
+
+	tests := map[model.CommentOrder][]int{
+		model.CommentOrderOldest:         {1, 2, 3},
+		model.CommentOrderNewest:         {3, 2, 1},
+		model.CommentOrderMostReplies:    {1, 2, 3},
+		model.CommentOrderRecentActivity: {2, 1, 3},
+	}
+
+	for order, expected := range tests {
+		var ids []int
+		first := 1
+		var after *string
+		for {
+			comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, &first, nil, after, nil, order)
+			suite.Require().NoError(err)
+
+			for _, edge := range comments.Edges {
+				ids = append(ids, edge.Node.ID)
+			}
+			if !comments.PageInfo.HasNextPage {
+				break
+			}
+			after = comments.PageInfo.EndCursor
+		}
+
+		suite.Equal(expected, ids, order)
+	}
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDMostRepliesBackwards() {
+	suite.commentTree()
+
+	last := 2
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, &last, nil, nil, model.CommentOrderMostReplies)
+	suite.Nil(err)
+	suite.Equal(2, comments.Edges[0].Node.ID)
+	suite.Equal(3, comments.Edges[1].Node.ID)
+	suite.True(comments.PageInfo.HasPrevPage)
+
+	comments, err = suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, &last, nil, comments.PageInfo.StartCursor, model.CommentOrderMostReplies)
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+	suite.Equal(1, comments.Edges[0].Node.ID)
+	suite.False(comments.PageInfo.HasPrevPage)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsByPostIDInvalidCursor() {
+	first := 1
+	after := "invalid"
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, &first, nil, &after, nil, model.CommentOrderOldest)
+
+	suite.Nil(comments)
+	suite.ErrorIs(err, cursor.ErrInvalidCursor)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_GetCommentsOfDeletedPost() {
+	parent, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "parent"})
+	suite.Require().NoError(err)
+
+	reply, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, ParentCommentID: &parent.ID, Body: "reply"})
+	suite.Require().NoError(err)
+
+	err = NewPostRepository(suite.storage).DeletePost(context.Background(), 1, suite.postID)
+	suite.Require().NoError(err)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Empty(comments.Edges)
+
+	replies, err := suite.repo.GetRepliesByCommentIDs(context.Background(), []int{parent.ID}, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Empty(replies[parent.ID].Edges)
+
+	_, err = suite.repo.GetCommentByID(context.Background(), reply.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// UpdateComment
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentNotOwner() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "test"})
+	suite.Require().NoError(err)
+
+	updated, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 2}, &model.UpdateCommentReq{ID: created.ID, Body: "updated"})
+	suite.Nil(updated)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	comment, err := suite.repo.GetCommentByID(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.Equal("test", comment.Body)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentByModerator() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "test"})
+	suite.Require().NoError(err)
+
+	updated, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 2, Role: model.RoleModerator}, &model.UpdateCommentReq{ID: created.ID, Body: "updated"})
+	suite.Nil(err)
+	suite.Equal("updated", updated.Body)
+	suite.Equal(1, updated.UserID)
+
+	err = suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 2, Role: model.RoleAdmin}, created.ID)
+	suite.Nil(err)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentReturnsUpdated() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "test"})
+	suite.Require().NoError(err)
+
+	updated, err := suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: created.ID, Body: "updated"})
+	suite.Nil(err)
+	suite.Equal("updated", updated.Body)
+
+	_, err = suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: 100, Body: "updated"})
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentKeepsRevisions() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "first"})
+	suite.Require().NoError(err)
+	suite.False(created.IsEdited())
+
+	for _, body := range []string{"second", "third"} {
+		_, err = suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: created.ID, Body: body})
+		suite.Require().NoError(err)
+	}
+
+	comment, err := suite.repo.GetCommentByID(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.True(comment.IsEdited())
+
+	revisions, err := suite.repo.GetCommentRevisions(context.Background(), created.ID, nil, nil)
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 2)
+	suite.Equal("second", revisions.Edges[0].Node.Body)
+	suite.Equal("first", revisions.Edges[1].Node.Body)
+
+	err = suite.repo.PurgeComment(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.Empty(suite.storage.commentRevisions)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_UpdateCommentUnchangedBodyKeepsNoRevision() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "first"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: created.ID, Body: "first"})
+	suite.Require().NoError(err)
+
+	revisions, err := suite.repo.GetCommentRevisions(context.Background(), created.ID, nil, nil)
+	suite.Nil(err)
+	suite.Empty(revisions.Edges)
+}
+
+// DeleteComment
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_DeleteCommentNotOwner() {
+	created, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "test"})
+	suite.Require().NoError(err)
+
+	err = suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 2}, created.ID)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	err = suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, 100)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *CommentRepositorySuite) TestRepository_DeleteCommentKeepsReplies() {
+	parent, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "parent"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, ParentCommentID: &parent.ID, Body: "reply"})
+	suite.Require().NoError(err)
+
+	err = suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, parent.ID)
+	suite.Nil(err)
+
+	comments, err := suite.repo.GetCommentsByPostID(context.Background(), suite.postID, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Len(comments.Edges, 1)
+	suite.True(comments.Edges[0].Node.IsDeleted())
+
+	replies, err := suite.repo.GetRepliesByCommentIDs(context.Background(), []int{parent.ID}, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Len(replies[parent.ID].Edges, 1)
+
+	err = suite.repo.DeleteComment(context.Background(), policy.Actor{UserID: 1}, parent.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	_, err = suite.repo.UpdateComment(context.Background(), policy.Actor{UserID: 1}, &model.UpdateCommentReq{ID: parent.ID, Body: "updated"})
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// PurgeComment
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_PurgeCommentRemovesSubtree() {
+	parent, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "parent"})
+	suite.Require().NoError(err)
+
+	reply, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, ParentCommentID: &parent.ID, Body: "reply"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, ParentCommentID: &reply.ID, Body: "nested"})
+	suite.Require().NoError(err)
+
+	other, err := suite.repo.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: suite.postID, Body: "other"})
+	suite.Require().NoError(err)
+
+	err = suite.repo.PurgeComment(context.Background(), parent.ID)
+	suite.Nil(err)
+	suite.Len(suite.storage.comments, 1)
+	suite.Contains(suite.storage.comments, other.ID)
+
+	err = suite.repo.PurgeComment(context.Background(), parent.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// IsCommentsAllowed
+// ================================================================
+
+func (suite *CommentRepositorySuite) TestRepository_IsCommentsAllowed() {
+	allowed, err := suite.repo.IsCommentsAllowed(context.Background(), suite.postID)
+
+	suite.Nil(err)
+	suite.True(allowed)
+
+	_, err = suite.repo.IsCommentsAllowed(context.Background(), 10)
+	suite.NotNil(err)
+}
+
+func (suite *CommentRepositorySuite) TestRepository_IsCommentsAllowedLocked() {
+	locked := true
+	_, err := NewPostRepository(suite.storage).ModeratePost(context.Background(), suite.postID, &model.ModeratePostReq{IsLocked: &locked})
+	suite.Require().NoError(err)
+
+	allowed, err := suite.repo.IsCommentsAllowed(context.Background(), suite.postID)
+
+	suite.Nil(err)
+	suite.False(allowed)
+}
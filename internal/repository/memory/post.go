@@ -0,0 +1,536 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"sort"
+	"time"
+)
+
+type PostRepository struct {
+	s *Storage
+}
+
+func NewPostRepository(s *Storage) *PostRepository {
+	return &PostRepository{
+		s: s,
+	}
+}
+
+func (r *PostRepository) CreatePost(ctx context.Context, userID int, req *model.CreatePostReq) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	r.s.lastPostID++
+	post := &model.Post{
+		ID:            r.s.lastPostID,
+		UserID:        userID,
+		Title:         req.Title,
+		Body:          req.Body,
+		AllowComments: req.AllowComments,
+		Status:        req.InitialStatus(),
+		PublishAt:     utc(req.PublishAt),
+		CreatedAt:     time.Now(),
+	}
+	r.s.posts[post.ID] = post
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) GetAllPostsByUserID(ctx context.Context, userID int) ([]*model.Post, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var posts []*model.Post
+	for _, post := range r.s.posts {
+		if post.UserID == userID && isVisible(post) {
+			posts = append(posts, r.s.postView(post))
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID > posts[j].ID
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+
+	return posts, nil
+}
+
+func (r *PostRepository) GetPostByID(ctx context.Context, id int) (*model.Post, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	post, ok := r.s.posts[id]
+	if !ok || !isVisible(post) {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) GetPosts(ctx context.Context, first, last *int, after, before *string, orderBy model.PostOrder) (*model.PostConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCursor, err := cursor.DecodeOptional(before)
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+
+	commentCounts := make(map[int]int, len(r.s.posts))
+	for _, comment := range r.s.comments {
+		if !comment.IsDeleted() {
+			commentCounts[comment.PostID]++
+		}
+	}
+
+	// position places a post in the requested order, so that sorting by it
+	// and comparing against cursors share the same key
+	position := func(post *model.Post) cursor.Cursor {
+		switch orderBy {
+		case model.PostOrderMostCommented:
+			return cursor.NewCount(commentCounts[post.ID], post.ID)
+		default:
+			return cursor.New(post.CreatedAt, post.ID)
+		}
+	}
+
+	less := func(a, b cursor.Cursor) bool {
+		if orderBy == model.PostOrderOldest {
+			return a.Before(b)
+		}
+		return b.Before(a)
+	}
+
+	totalCount := 0
+
+	var posts []*model.Post
+	for _, post := range r.s.posts {
+		if !isVisible(post) {
+			continue
+		}
+		totalCount++
+
+		p := position(post)
+		if afterCursor != nil && !less(*afterCursor, p) {
+			continue
+		}
+		if beforeCursor != nil && !less(p, *beforeCursor) {
+			continue
+		}
+		posts = append(posts, r.s.postView(post))
+	}
+
+	r.s.mu.RUnlock()
+
+	sort.Slice(posts, func(i, j int) bool {
+		return less(position(posts[i]), position(posts[j]))
+	})
+
+	var hasNextPage, hasPrevPage bool
+
+	if first != nil {
+		if len(posts) > *first {
+			posts = posts[:*first]
+			hasNextPage = true
+		}
+	} else if last != nil {
+		if len(posts) > *last {
+			posts = posts[len(posts)-*last:]
+			hasPrevPage = true
+		}
+	}
+
+	edges := make([]*model.PostEdge, 0, len(posts))
+	var startCursor, endCursor *string
+
+	for i, post := range posts {
+		cursorStr := position(post).Encode()
+		if i == 0 {
+			startCursor = &cursorStr
+		}
+		endCursor = &cursorStr
+
+		edges = append(edges, &model.PostEdge{
+			Cursor: cursorStr,
+			Node:   post,
+		})
+	}
+
+	return &model.PostConnection{
+		Edges: edges,
+		PageInfo: &model.PageInfo{
+			StartCursor: startCursor,
+			EndCursor:   endCursor,
+			HasNextPage: hasNextPage,
+			HasPrevPage: hasPrevPage,
+		},
+		TotalCount: totalCount,
+	}, nil
+}
+
+func (r *PostRepository) UpdatePost(ctx context.Context, actor policy.Actor, postID int, req *model.UpdatePostReq) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, err := r.s.editablePost(actor, postID, req.Status != nil || req.PublishAt != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if (req.Title != nil && *req.Title != post.Title) || (req.Body != nil && *req.Body != post.Body) {
+		r.s.savePostRevision(post)
+	}
+
+	if req.Title != nil {
+		post.Title = *req.Title
+	}
+
+	if req.Body != nil {
+		post.Body = *req.Body
+	}
+
+	if req.AllowComments != nil {
+		post.AllowComments = *req.AllowComments
+	}
+
+	if req.Status != nil {
+		post.Status = *req.Status
+	}
+
+	if req.PublishAt != nil || post.Status == model.PostStatusPublished {
+		post.PublishAt = utc(req.PublishAt)
+	}
+
+	now := time.Now()
+	post.UpdatedAt = &now
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) GetDraftsByUserID(ctx context.Context, userID int) ([]*model.Post, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	var posts []*model.Post
+	for _, post := range r.s.posts {
+		if post.UserID == userID && post.DeletedAt == nil && post.Status == model.PostStatusDraft {
+			posts = append(posts, r.s.postView(post))
+		}
+	}
+
+	sort.Slice(posts, func(i, j int) bool {
+		if posts[i].CreatedAt.Equal(posts[j].CreatedAt) {
+			return posts[i].ID > posts[j].ID
+		}
+		return posts[i].CreatedAt.After(posts[j].CreatedAt)
+	})
+
+	return posts, nil
+}
+
+func (r *PostRepository) PublishPost(ctx context.Context, actor policy.Actor, postID int) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, err := r.s.editablePost(actor, postID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if post.Status == model.PostStatusPublished {
+		return nil, apperror.Conflict("post is already published")
+	}
+
+	post.Status = model.PostStatusPublished
+	post.PublishAt = nil
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) PublishScheduledPosts(ctx context.Context, now time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+
+	for _, post := range r.s.posts {
+		if post.Status == model.PostStatusDraft && post.DeletedAt == nil && !post.IsLocked && post.PublishAt != nil && !post.PublishAt.After(now) {
+			post.Status = model.PostStatusPublished
+			post.PublishAt = nil
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *PostRepository) GetPostRevisions(ctx context.Context, postID int, first *int, after *string) (*model.PostRevisionConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+
+	var revisions []*model.PostRevision
+	for _, revision := range r.s.postRevisions {
+		if revision.PostID != postID {
+			continue
+		}
+		if afterCursor != nil && !cursor.New(revision.CreatedAt, revision.ID).Before(*afterCursor) {
+			continue
+		}
+
+		view := *revision
+		revisions = append(revisions, &view)
+	}
+
+	r.s.mu.RUnlock()
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return cursor.New(revisions[j].CreatedAt, revisions[j].ID).Before(cursor.New(revisions[i].CreatedAt, revisions[i].ID))
+	})
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(revisions) > *first {
+		revisions = revisions[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	edges := make([]*model.PostRevisionEdge, 0, len(revisions))
+
+	for i, revision := range revisions {
+		cursorStr := cursor.New(revision.CreatedAt, revision.ID).Encode()
+		if i == 0 {
+			pageInfo.StartCursor = &cursorStr
+		}
+		pageInfo.EndCursor = &cursorStr
+
+		edges = append(edges, &model.PostRevisionEdge{
+			Cursor: cursorStr,
+			Node:   revision,
+		})
+	}
+
+	return &model.PostRevisionConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (r *PostRepository) RevertPost(ctx context.Context, actor policy.Actor, postID, revisionID int) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, err := r.s.editablePost(actor, postID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	revision, ok := r.s.postRevisions[revisionID]
+	if !ok || revision.PostID != postID {
+		return nil, apperror.NotFound("revision not found")
+	}
+
+	if revision.Title != post.Title || revision.Body != post.Body {
+		r.s.savePostRevision(post)
+	}
+
+	post.Title = revision.Title
+	post.Body = revision.Body
+
+	now := time.Now()
+	post.UpdatedAt = &now
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) DeletePost(ctx context.Context, userID, postID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, err := r.s.ownedPost(userID, postID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	post.DeletedAt = &now
+
+	return nil
+}
+
+func (r *PostRepository) RestorePost(ctx context.Context, userID, postID int, deletedAfter time.Time) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	if post.UserID != userID {
+		return nil, apperror.Forbidden("post belongs to another user")
+	}
+
+	if post.DeletedAt == nil {
+		return nil, apperror.Conflict("post is not deleted")
+	}
+
+	if post.IsLocked {
+		return nil, apperror.Forbidden("post is locked")
+	}
+
+	if !post.DeletedAt.After(deletedAfter) {
+		return nil, apperror.NotFound("post can no longer be restored")
+	}
+
+	post.DeletedAt = nil
+
+	return r.s.postView(post), nil
+}
+
+func (r *PostRepository) PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	count := 0
+
+	for id, post := range r.s.posts {
+		if post.DeletedAt != nil && !post.DeletedAt.After(deletedBefore) {
+			r.s.purgePost(id)
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (r *PostRepository) ModeratePost(ctx context.Context, postID int, req *model.ModeratePostReq) (*model.Post, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok || post.DeletedAt != nil {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	if req.IsLocked != nil {
+		post.IsLocked = *req.IsLocked
+	}
+
+	if req.AllowComments != nil {
+		post.AllowComments = *req.AllowComments
+	}
+
+	return r.s.postView(post), nil
+}
+
+// purgePost removes the post together with its revisions, votes and
+// comments. The caller must hold s.mu.
+func (s *Storage) purgePost(postID int) {
+	delete(s.posts, postID)
+	deleteVotes(s.postVotes, postID)
+	s.detachReports(postID, 0)
+
+	for id, revision := range s.postRevisions {
+		if revision.PostID == postID {
+			delete(s.postRevisions, id)
+		}
+	}
+
+	for id, comment := range s.comments {
+		if comment.PostID == postID {
+			delete(s.comments, id)
+			s.deleteCommentRevisions(id)
+			deleteVotes(s.commentVotes, id)
+			s.deleteReactions(id)
+			s.detachReports(0, id)
+		}
+	}
+}
+
+// savePostRevision keeps the current title and body of the post before they
+// are overwritten. The caller must hold s.mu.
+func (s *Storage) savePostRevision(post *model.Post) {
+	s.lastPostRevisionID++
+	s.postRevisions[s.lastPostRevisionID] = &model.PostRevision{
+		ID:        s.lastPostRevisionID,
+		PostID:    post.ID,
+		Title:     post.Title,
+		Body:      post.Body,
+		CreatedAt: time.Now(),
+	}
+}
+
+// ownedPost returns the stored post if it belongs to the user. Deleted posts
+// are reported as not found. The caller must hold s.mu.
+func (s *Storage) ownedPost(userID, postID int) (*model.Post, error) {
+	post, ok := s.posts[postID]
+	if !ok || post.DeletedAt != nil {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	if post.UserID != userID {
+		return nil, apperror.Forbidden("post belongs to another user")
+	}
+
+	return post, nil
+}
+
+// editablePost returns the stored post if the actor may edit it, changing its
+// status if changesStatus is set. Deleted posts are reported as not found. The
+// caller must hold s.mu.
+func (s *Storage) editablePost(actor policy.Actor, postID int, changesStatus bool) (*model.Post, error) {
+	post, ok := s.posts[postID]
+	if !ok || post.DeletedAt != nil {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	err := policy.CanEditPost(actor, post.UserID, post.IsLocked, changesStatus)
+	if err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// isVisible reports whether the post is shown to everyone, that is published
+// and not deleted.
+func isVisible(post *model.Post) bool {
+	return post.DeletedAt == nil && post.Status == model.PostStatusPublished
+}
+
+// utc converts t to UTC, matching how the SQL repository stores times.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+
+	return &u
+}
+
+// postView returns a copy of the stored post, so callers never share
+// memory with the storage. The caller must hold s.mu.
+func (s *Storage) postView(post *model.Post) *model.Post {
+	view := *post
+
+	return &view
+}
@@ -0,0 +1,528 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type PostRepositorySuite struct {
+	suite.Suite
+	storage *Storage
+	repo    *PostRepository
+}
+
+func (suite *PostRepositorySuite) SetupTest() {
+	suite.storage = NewStorage()
+	suite.repo = NewPostRepository(suite.storage)
+
+	users := NewUserRepository(suite.storage)
+	for _, name := range []string{"first", "second"} {
+		_, err := users.Register(context.Background(), &model.RegisterReq{
+			Email:    name + "@mail.com",
+			Username: name,
+			Password: "test",
+		})
+		suite.Require().NoError(err)
+	}
+}
+
+func TestPostRepositorySuite(t *testing.T) {
+	suite.Run(t, new(PostRepositorySuite))
+}
+
+func (suite *PostRepositorySuite) createPost(userID int, title string) *model.Post {
+	post, err := suite.repo.CreatePost(context.Background(), userID, &model.CreatePostReq{
+		Title:         title,
+		Body:          title,
+		AllowComments: true,
+	})
+	suite.Require().NoError(err)
+
+	return post
+}
+
+// CreatePost
+// ==============================================
+
+func (suite *PostRepositorySuite) TestRepository_CreatePostSuccess() {
+	post := suite.createPost(1, "test")
+
+	suite.Equal(1, post.ID)
+	suite.Equal(1, post.UserID)
+	suite.False(post.CreatedAt.IsZero())
+}
+
+func (suite *PostRepositorySuite) TestRepository_CreatePostUnknownUser() {
+	post, err := suite.repo.CreatePost(context.Background(), 10, &model.CreatePostReq{Title: "test", Body: "test"})
+
+	suite.Nil(post)
+	suite.NotNil(err)
+}
+
+// GetAllPosts
+// =======================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetAllPostsNewestFirst() {
+	suite.createPost(1, "1")
+	suite.createPost(2, "2")
+	suite.createPost(1, "3")
+
+	posts, err := suite.repo.GetAllPostsByUserID(context.Background(), 1)
+
+	suite.Nil(err)
+	suite.Len(posts, 2)
+	suite.Equal("3", posts[0].Title)
+	suite.Equal("1", posts[1].Title)
+}
+
+// GetPostByID
+// =======================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetPostByIDNotFound() {
+	post, err := suite.repo.GetPostByID(context.Background(), 1)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// GetPosts
+// =======================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsPaginatesNewest() {
+	suite.createPost(1, "1")
+	suite.createPost(2, "2")
+	suite.createPost(1, "3")
+
+	first := 2
+	page, err := suite.repo.GetPosts(context.Background(), &first, nil, nil, nil, model.PostOrderNewest)
+	suite.Nil(err)
+	suite.Equal(3, page.TotalCount)
+	suite.Len(page.Edges, 2)
+	suite.Equal("3", page.Edges[0].Node.Title)
+	suite.Equal("2", page.Edges[1].Node.Title)
+	suite.True(page.PageInfo.HasNextPage)
+
+	page, err = suite.repo.GetPosts(context.Background(), &first, nil, page.PageInfo.EndCursor, nil, model.PostOrderNewest)
+	suite.Nil(err)
+	suite.Len(page.Edges, 1)
+	suite.Equal("1", page.Edges[0].Node.Title)
+	suite.False(page.PageInfo.HasNextPage)
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsOldestLast() {
+	suite.createPost(1, "1")
+	suite.createPost(1, "2")
+	suite.createPost(1, "3")
+
+	last := 2
+	page, err := suite.repo.GetPosts(context.Background(), nil, &last, nil, nil, model.PostOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(page.Edges, 2)
+	suite.Equal("2", page.Edges[0].Node.Title)
+	suite.Equal("3", page.Edges[1].Node.Title)
+	suite.True(page.PageInfo.HasPrevPage)
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsMostCommented() {
+	quiet := suite.createPost(1, "quiet")
+	busy := suite.createPost(1, "busy")
+	suite.createPost(1, "empty")
+
+	comments := NewCommentRepository(suite.storage)
+	for _, postID := range []int{busy.ID, busy.ID, quiet.ID} {
+		_, err := comments.CreateComment(context.Background(), 2, &model.CreateCommentReq{PostID: postID, Body: "test"})
+		suite.Require().NoError(err)
+	}
+
+	first := 1
+	page, err := suite.repo.GetPosts(context.Background(), &first, nil, nil, nil, model.PostOrderMostCommented)
+	suite.Nil(err)
+	suite.Equal("busy", page.Edges[0].Node.Title)
+
+	first = 5
+	page, err = suite.repo.GetPosts(context.Background(), &first, nil, page.PageInfo.EndCursor, nil, model.PostOrderMostCommented)
+	suite.Nil(err)
+	suite.Len(page.Edges, 2)
+	suite.Equal("quiet", page.Edges[0].Node.Title)
+	suite.Equal("empty", page.Edges[1].Node.Title)
+}
+
+// UpdatePost
+// ======================================================================
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostOwner() {
+	created := suite.createPost(1, "test")
+
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{
+		Title: strPointer("updated"),
+	})
+	suite.Nil(err)
+	suite.Equal("updated", post.Title)
+	suite.Equal("test", post.Body)
+	suite.NotNil(post.UpdatedAt)
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostNotOwner() {
+	created := suite.createPost(1, "test")
+
+	updated, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 2}, created.ID, &model.UpdatePostReq{
+		Title: strPointer("updated"),
+	})
+	suite.Nil(updated)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	post, err := suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.Equal("test", post.Title)
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostNotFound() {
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 100, &model.UpdatePostReq{
+		Title: strPointer("updated"),
+	})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostLocked() {
+	created := suite.createPost(1, "test")
+
+	post, err := suite.repo.ModeratePost(context.Background(), created.ID, &model.ModeratePostReq{IsLocked: boolPointer(true)})
+	suite.Nil(err)
+	suite.True(post.IsLocked)
+
+	_, err = suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	_, err = suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, created.ID, 1)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	_, err = suite.repo.ModeratePost(context.Background(), created.ID, &model.ModeratePostReq{IsLocked: boolPointer(false)})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Nil(err)
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostLockedStatusByModerator() {
+	created := suite.createPost(1, "test")
+	moderator := policy.Actor{UserID: 1, Role: model.RoleModerator}
+
+	_, err := suite.repo.ModeratePost(context.Background(), created.ID, &model.ModeratePostReq{IsLocked: boolPointer(true)})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.UpdatePost(context.Background(), moderator, created.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Nil(err)
+
+	draft := model.PostStatusDraft
+	_, err = suite.repo.UpdatePost(context.Background(), moderator, created.ID, &model.UpdatePostReq{Status: &draft})
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	_, err = suite.repo.PublishPost(context.Background(), moderator, created.ID)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+// ModeratePost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_ModeratePost() {
+	created := suite.createPost(1, "test")
+
+	post, err := suite.repo.ModeratePost(context.Background(), created.ID, &model.ModeratePostReq{AllowComments: boolPointer(false)})
+	suite.Nil(err)
+	suite.False(post.AllowComments)
+	suite.False(post.IsLocked)
+	suite.Nil(post.UpdatedAt)
+
+	_, err = suite.repo.ModeratePost(context.Background(), 100, &model.ModeratePostReq{IsLocked: boolPointer(true)})
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// Drafts
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_DraftsVisibleOnlyToAuthor() {
+	draft := model.PostStatusDraft
+	created, err := suite.repo.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "draft", Body: "draft", Status: &draft})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	feed, err := suite.repo.GetPosts(context.Background(), nil, nil, nil, nil, model.PostOrderNewest)
+	suite.Nil(err)
+	suite.Equal(0, feed.TotalCount)
+
+	posts, err := suite.repo.GetAllPostsByUserID(context.Background(), 1)
+	suite.Nil(err)
+	suite.Empty(posts)
+
+	drafts, err := suite.repo.GetDraftsByUserID(context.Background(), 1)
+	suite.Nil(err)
+	suite.Len(drafts, 1)
+
+	drafts, err = suite.repo.GetDraftsByUserID(context.Background(), 2)
+	suite.Nil(err)
+	suite.Empty(drafts)
+
+	_, err = suite.repo.PublishPost(context.Background(), policy.Actor{UserID: 2}, created.ID)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	post, err := suite.repo.PublishPost(context.Background(), policy.Actor{UserID: 1}, created.ID)
+	suite.Nil(err)
+	suite.Equal(model.PostStatusPublished, post.Status)
+
+	_, err = suite.repo.PublishPost(context.Background(), policy.Actor{UserID: 1}, created.ID)
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+
+	_, err = suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Nil(err)
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishScheduledPosts() {
+	now := time.Now()
+	due := now.Add(-time.Minute)
+	later := now.Add(time.Hour)
+
+	duePost, err := suite.repo.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "due", Body: "due", PublishAt: &due})
+	suite.Require().NoError(err)
+	suite.Equal(model.PostStatusDraft, duePost.Status)
+
+	laterPost, err := suite.repo.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "later", Body: "later", PublishAt: &later})
+	suite.Require().NoError(err)
+
+	count, err := suite.repo.PublishScheduledPosts(context.Background(), now)
+	suite.Nil(err)
+	suite.Equal(1, count)
+
+	post, err := suite.repo.GetPostByID(context.Background(), duePost.ID)
+	suite.Nil(err)
+	suite.Nil(post.PublishAt)
+
+	_, err = suite.repo.GetPostByID(context.Background(), laterPost.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishScheduledPostsSkipsLocked() {
+	due := time.Now().Add(-time.Minute)
+
+	created, err := suite.repo.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "due", Body: "due", PublishAt: &due})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ModeratePost(context.Background(), created.ID, &model.ModeratePostReq{IsLocked: boolPointer(true)})
+	suite.Require().NoError(err)
+
+	count, err := suite.repo.PublishScheduledPosts(context.Background(), time.Now())
+	suite.Nil(err)
+	suite.Equal(0, count)
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishAtStoredInUTC() {
+	publishAt := time.Now().Add(time.Hour).In(time.FixedZone("UTC+3", 3*60*60))
+
+	created, err := suite.repo.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "later", Body: "later", PublishAt: &publishAt})
+	suite.Require().NoError(err)
+	suite.True(publishAt.Equal(*created.PublishAt))
+	suite.Equal(time.UTC, created.PublishAt.Location())
+
+	updated, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{PublishAt: &publishAt})
+	suite.Require().NoError(err)
+	suite.Equal(time.UTC, updated.PublishAt.Location())
+}
+
+// Revisions
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostKeepsRevisions() {
+	created := suite.createPost(1, "first")
+
+	for _, title := range []string{"second", "third"} {
+		_, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: &title})
+		suite.Require().NoError(err)
+	}
+
+	first := 1
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), created.ID, &first, nil)
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 1)
+	suite.Equal("second", revisions.Edges[0].Node.Title)
+	suite.True(revisions.PageInfo.HasNextPage)
+
+	revisions, err = suite.repo.GetPostRevisions(context.Background(), created.ID, nil, revisions.PageInfo.EndCursor)
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 1)
+	suite.Equal("first", revisions.Edges[0].Node.Title)
+	suite.False(revisions.PageInfo.HasNextPage)
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostUnchangedContentKeepsNoRevision() {
+	created := suite.createPost(1, "first")
+
+	_, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{AllowComments: boolPointer(false)})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("first")})
+	suite.Require().NoError(err)
+
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), created.ID, nil, nil)
+	suite.Nil(err)
+	suite.Empty(revisions.Edges)
+}
+
+func (suite *PostRepositorySuite) TestRepository_RevertPost() {
+	created := suite.createPost(1, "first")
+
+	_, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("second")})
+	suite.Require().NoError(err)
+
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), created.ID, nil, nil)
+	suite.Require().NoError(err)
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, created.ID, revisions.Edges[0].Node.ID)
+	suite.Nil(err)
+	suite.Equal("first", post.Title)
+
+	revisions, err = suite.repo.GetPostRevisions(context.Background(), created.ID, nil, nil)
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 2)
+	suite.Equal("second", revisions.Edges[0].Node.Title)
+}
+
+func (suite *PostRepositorySuite) TestRepository_RevertPostNotOwner() {
+	created := suite.createPost(1, "first")
+
+	_, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("second")})
+	suite.Require().NoError(err)
+
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), created.ID, nil, nil)
+	suite.Require().NoError(err)
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 2}, created.ID, revisions.Edges[0].Node.ID)
+	suite.Nil(post)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+}
+
+func (suite *PostRepositorySuite) TestRepository_RevertPostForeignRevision() {
+	first := suite.createPost(1, "first")
+	second := suite.createPost(1, "second")
+
+	_, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, first.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Require().NoError(err)
+
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), first.ID, nil, nil)
+	suite.Require().NoError(err)
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, second.ID, revisions.Edges[0].Node.ID)
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// DeletePost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_DeletePostNotOwner() {
+	created := suite.createPost(1, "test")
+
+	err := suite.repo.DeletePost(context.Background(), 2, created.ID)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	_, err = suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Nil(err)
+}
+
+func (suite *PostRepositorySuite) TestRepository_DeletePostHidesPost() {
+	created := suite.createPost(1, "test")
+
+	err := suite.repo.DeletePost(context.Background(), 1, created.ID)
+	suite.Nil(err)
+
+	_, err = suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	posts, err := suite.repo.GetAllPostsByUserID(context.Background(), 1)
+	suite.Nil(err)
+	suite.Empty(posts)
+
+	feed, err := suite.repo.GetPosts(context.Background(), nil, nil, nil, nil, model.PostOrderNewest)
+	suite.Nil(err)
+	suite.Empty(feed.Edges)
+	suite.Equal(0, feed.TotalCount)
+
+	_, err = suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, created.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	_, err = NewCommentRepository(suite.storage).IsCommentsAllowed(context.Background(), created.ID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// RestorePost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_RestorePost() {
+	created := suite.createPost(1, "test")
+
+	_, err := suite.repo.RestorePost(context.Background(), 1, created.ID, time.Now().Add(-time.Hour))
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+
+	err = suite.repo.DeletePost(context.Background(), 1, created.ID)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.RestorePost(context.Background(), 2, created.ID, time.Now().Add(-time.Hour))
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	_, err = suite.repo.RestorePost(context.Background(), 1, created.ID, time.Now().Add(time.Hour))
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	post, err := suite.repo.RestorePost(context.Background(), 1, created.ID, time.Now().Add(-time.Hour))
+	suite.Nil(err)
+	suite.Equal(created.ID, post.ID)
+
+	_, err = suite.repo.GetPostByID(context.Background(), created.ID)
+	suite.Nil(err)
+}
+
+// PurgeDeletedPosts
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_PurgeDeletedPostsCascades() {
+	deleted := suite.createPost(1, "deleted")
+	kept := suite.createPost(1, "kept")
+
+	comments := NewCommentRepository(suite.storage)
+	_, err := comments.CreateComment(context.Background(), 2, &model.CreateCommentReq{PostID: deleted.ID, Body: "test"})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, deleted.ID, &model.UpdatePostReq{Title: strPointer("updated")})
+	suite.Require().NoError(err)
+
+	err = suite.repo.DeletePost(context.Background(), 1, deleted.ID)
+	suite.Require().NoError(err)
+
+	count, err := suite.repo.PurgeDeletedPosts(context.Background(), time.Now().Add(-time.Hour))
+	suite.Nil(err)
+	suite.Equal(0, count)
+
+	count, err = suite.repo.PurgeDeletedPosts(context.Background(), time.Now())
+	suite.Nil(err)
+	suite.Equal(1, count)
+
+	suite.Len(suite.storage.posts, 1)
+	suite.Contains(suite.storage.posts, kept.ID)
+	suite.Empty(suite.storage.comments)
+	suite.Empty(suite.storage.postRevisions)
+}
+
+func strPointer(s string) *string {
+	return &s
+}
+
+func boolPointer(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"sort"
+	"time"
+)
+
+// reactionKey identifies the reaction of a user to a comment with one emoji.
+type reactionKey struct {
+	commentID int
+	userID    int
+	emoji     string
+}
+
+type ReactionRepository struct {
+	s *Storage
+}
+
+func NewReactionRepository(s *Storage) *ReactionRepository {
+	return &ReactionRepository{
+		s: s,
+	}
+}
+
+func (r *ReactionRepository) AddReaction(ctx context.Context, userID, commentID int, emoji string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[commentID]
+	if !ok || comment.IsDeleted() {
+		return apperror.NotFound("comment not found")
+	}
+
+	key := reactionKey{commentID: commentID, userID: userID, emoji: emoji}
+	if _, ok := r.s.reactions[key]; !ok {
+		r.s.reactions[key] = time.Now()
+	}
+
+	return nil
+}
+
+func (r *ReactionRepository) RemoveReaction(ctx context.Context, userID, commentID int, emoji string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	delete(r.s.reactions, reactionKey{commentID: commentID, userID: userID, emoji: emoji})
+
+	return nil
+}
+
+func (r *ReactionRepository) GetReactions(ctx context.Context, userID int, commentIDs []int) (map[int][]*model.ReactionSummary, error) {
+	wanted := make(map[int]bool, len(commentIDs))
+	for _, id := range commentIDs {
+		wanted[id] = true
+	}
+
+	type summaryKey struct {
+		commentID int
+		emoji     string
+	}
+
+	summaries := make(map[summaryKey]*model.ReactionSummary)
+	firstUsed := make(map[summaryKey]time.Time)
+
+	r.s.mu.RLock()
+
+	for key, createdAt := range r.s.reactions {
+		if !wanted[key.commentID] {
+			continue
+		}
+
+		k := summaryKey{commentID: key.commentID, emoji: key.emoji}
+		summary, ok := summaries[k]
+		if !ok {
+			summary = &model.ReactionSummary{Emoji: key.emoji}
+			summaries[k] = summary
+			firstUsed[k] = createdAt
+		}
+
+		summary.Count++
+		summary.Reacted = summary.Reacted || key.userID == userID
+		if createdAt.Before(firstUsed[k]) {
+			firstUsed[k] = createdAt
+		}
+	}
+
+	r.s.mu.RUnlock()
+
+	keys := make([]summaryKey, 0, len(summaries))
+	for k := range summaries {
+		keys = append(keys, k)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if !firstUsed[keys[i]].Equal(firstUsed[keys[j]]) {
+			return firstUsed[keys[i]].Before(firstUsed[keys[j]])
+		}
+		return keys[i].emoji < keys[j].emoji
+	})
+
+	reactions := make(map[int][]*model.ReactionSummary, len(commentIDs))
+	for _, k := range keys {
+		reactions[k.commentID] = append(reactions[k.commentID], summaries[k])
+	}
+
+	return reactions, nil
+}
+
+// deleteReactions removes all reactions to the comment. The caller must hold
+// s.mu.
+func (s *Storage) deleteReactions(commentID int) {
+	for key := range s.reactions {
+		if key.commentID == commentID {
+			delete(s.reactions, key)
+		}
+	}
+}
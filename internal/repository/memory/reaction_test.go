@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ReactionRepositorySuite struct {
+	suite.Suite
+	storage   *Storage
+	repo      *ReactionRepository
+	comments  *CommentRepository
+	commentID int
+}
+
+func (suite *ReactionRepositorySuite) SetupTest() {
+	suite.storage = NewStorage()
+	suite.repo = NewReactionRepository(suite.storage)
+	suite.comments = NewCommentRepository(suite.storage)
+
+	users := NewUserRepository(suite.storage)
+	for _, name := range []string{"first", "second"} {
+		_, err := users.Register(context.Background(), &model.RegisterReq{
+			Email:    name + "@mail.com",
+			Username: name,
+			Password: "test",
+		})
+		suite.Require().NoError(err)
+	}
+
+	post, err := NewPostRepository(suite.storage).CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "test", Body: "test", AllowComments: true})
+	suite.Require().NoError(err)
+
+	comment, err := suite.comments.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: post.ID, Body: "test"})
+	suite.Require().NoError(err)
+	suite.commentID = comment.ID
+}
+
+func TestReactionRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReactionRepositorySuite))
+}
+
+// Reactions
+// ==============================================
+
+func (suite *ReactionRepositorySuite) TestRepository_ReactionSummaries() {
+	suite.Require().NoError(suite.repo.AddReaction(context.Background(), 1, suite.commentID, "👍"))
+	suite.Require().NoError(suite.repo.AddReaction(context.Background(), 1, suite.commentID, "👍"))
+	suite.Require().NoError(suite.repo.AddReaction(context.Background(), 2, suite.commentID, "👍"))
+	suite.Require().NoError(suite.repo.AddReaction(context.Background(), 2, suite.commentID, "🎉"))
+
+	reactions, err := suite.repo.GetReactions(context.Background(), 1, []int{suite.commentID})
+	suite.Nil(err)
+	suite.Equal([]*model.ReactionSummary{
+		{Emoji: "👍", Count: 2, Reacted: true},
+		{Emoji: "🎉", Count: 1, Reacted: false},
+	}, reactions[suite.commentID])
+
+	suite.Require().NoError(suite.repo.RemoveReaction(context.Background(), 1, suite.commentID, "👍"))
+
+	reactions, err = suite.repo.GetReactions(context.Background(), 1, []int{suite.commentID})
+	suite.Nil(err)
+	suite.Equal(1, reactions[suite.commentID][0].Count)
+	suite.False(reactions[suite.commentID][0].Reacted)
+}
+
+func (suite *ReactionRepositorySuite) TestRepository_AddReactionDeletedComment() {
+	suite.Require().NoError(suite.comments.DeleteComment(context.Background(), policy.Actor{UserID: 1}, suite.commentID))
+
+	err := suite.repo.AddReaction(context.Background(), 2, suite.commentID, "👍")
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *ReactionRepositorySuite) TestRepository_PurgedCommentDropsReactions() {
+	suite.Require().NoError(suite.repo.AddReaction(context.Background(), 2, suite.commentID, "👍"))
+	suite.Require().NoError(suite.comments.PurgeComment(context.Background(), suite.commentID))
+
+	suite.Empty(suite.storage.reactions)
+}
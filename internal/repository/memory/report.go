@@ -0,0 +1,268 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"sort"
+	"time"
+)
+
+type ReportRepository struct {
+	s *Storage
+}
+
+func NewReportRepository(s *Storage) *ReportRepository {
+	return &ReportRepository{
+		s: s,
+	}
+}
+
+func (r *ReportRepository) ReportPost(ctx context.Context, userID, postID int, reason model.ReportReason, note *string) (*model.Report, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok || !isVisible(post) {
+		return nil, apperror.NotFound("post not found")
+	}
+
+	return r.s.addReport(&model.Report{ReporterID: userID, PostID: &postID, Reason: reason, Note: note})
+}
+
+func (r *ReportRepository) ReportComment(ctx context.Context, userID, commentID int, reason model.ReportReason, note *string) (*model.Report, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[commentID]
+	if !ok || comment.IsDeleted() {
+		return nil, apperror.NotFound("comment not found")
+	}
+
+	return r.s.addReport(&model.Report{ReporterID: userID, CommentID: &commentID, Reason: reason, Note: note})
+}
+
+func (r *ReportRepository) GetReports(ctx context.Context, status model.ReportStatus, first *int, after *string) (*model.ReportConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	reports := make([]*model.Report, 0)
+	for _, report := range r.s.reports {
+		if report.Status != status {
+			continue
+		}
+		if afterCursor != nil && !afterCursor.Before(cursor.New(report.CreatedAt, report.ID)) {
+			continue
+		}
+
+		view := *report
+		reports = append(reports, &view)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return cursor.New(reports[i].CreatedAt, reports[i].ID).Before(cursor.New(reports[j].CreatedAt, reports[j].ID))
+	})
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(reports) > *first {
+		reports = reports[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	edges := make([]*model.ReportEdge, 0, len(reports))
+	for _, report := range reports {
+		edges = append(edges, &model.ReportEdge{
+			Cursor: cursor.New(report.CreatedAt, report.ID).Encode(),
+			Node:   report,
+		})
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.ReportConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (r *ReportRepository) ResolveReport(ctx context.Context, actor policy.Actor, reportID int, action model.ReportAction) (*model.Report, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	report, ok := r.s.reports[reportID]
+	if !ok {
+		return nil, apperror.NotFound("report not found")
+	}
+
+	if report.Status != model.ReportStatusOpen {
+		return nil, apperror.Conflict("report is already resolved")
+	}
+
+	// collected first, purging a comment detaches its reports
+	resolved := []*model.Report{report}
+	for _, other := range r.s.reports {
+		if other != report && other.Status == model.ReportStatusOpen && sameContent(other, report) {
+			resolved = append(resolved, other)
+		}
+	}
+
+	if action != model.ReportActionDismiss {
+		err := r.s.applyReportAction(actor, report, action)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	for _, other := range resolved {
+		other.Status = model.ReportStatusResolved
+		other.Action = &action
+		other.ResolvedByID = &actor.UserID
+		other.ResolvedAt = &now
+	}
+
+	view := *report
+
+	return &view, nil
+}
+
+// addReport stores a new open report, unless the reporter already has an
+// open report of the same content. The caller must hold s.mu.
+func (s *Storage) addReport(report *model.Report) (*model.Report, error) {
+	for _, other := range s.reports {
+		if other.Status == model.ReportStatusOpen && other.ReporterID == report.ReporterID && sameContent(other, report) {
+			return nil, apperror.Conflict("content is already reported")
+		}
+	}
+
+	s.lastReportID++
+	report.ID = s.lastReportID
+	report.Status = model.ReportStatusOpen
+	report.CreatedAt = time.Now()
+	s.reports[report.ID] = report
+
+	view := *report
+
+	return &view, nil
+}
+
+// applyReportAction takes the reported content away the same way the SQL
+// repository does. The caller must hold s.mu.
+func (s *Storage) applyReportAction(actor policy.Actor, report *model.Report, action model.ReportAction) error {
+	authorID, err := s.reportedAuthorID(report)
+	if err != nil {
+		return err
+	}
+
+	// checked before anything changes, there is no transaction to roll back
+	if action == model.ReportActionBanAuthor {
+		author, ok := s.users[authorID]
+		if !ok {
+			return apperror.NotFound("author not found")
+		}
+
+		err = policy.CanBanAuthor(actor, authorID, author.Role)
+		if err != nil {
+			return err
+		}
+	}
+
+	if report.PostID != nil {
+		post := s.posts[*report.PostID]
+		post.IsLocked = true
+
+		if action == model.ReportActionDelete {
+			if post.DeletedAt == nil {
+				now := time.Now()
+				post.DeletedAt = &now
+			}
+		} else {
+			post.Status = model.PostStatusDraft
+			post.PublishAt = nil
+		}
+	} else {
+		comment := s.comments[*report.CommentID]
+
+		if action == model.ReportActionDelete {
+			s.purgeComment(comment.ID)
+		} else if comment.DeletedAt == nil {
+			now := time.Now()
+			comment.DeletedAt = &now
+		}
+	}
+
+	if action != model.ReportActionBanAuthor {
+		return nil
+	}
+
+	user := s.users[authorID]
+	now := time.Now()
+	user.BannedAt = &now
+	user.BannedUntil = nil
+	user.BanReason = fmt.Sprintf("reported for %s", report.Reason)
+
+	for _, session := range s.sessions {
+		if session.UserID == authorID {
+			session.revoked = true
+		}
+	}
+
+	return nil
+}
+
+// reportedAuthorID returns the author of the reported post or comment. The
+// caller must hold s.mu.
+func (s *Storage) reportedAuthorID(report *model.Report) (int, error) {
+	if report.PostID != nil {
+		if post, ok := s.posts[*report.PostID]; ok {
+			return post.UserID, nil
+		}
+	}
+
+	if report.CommentID != nil {
+		if comment, ok := s.comments[*report.CommentID]; ok {
+			return comment.UserID, nil
+		}
+	}
+
+	return 0, apperror.NotFound("reported content no longer exists")
+}
+
+// detachReports forgets the purged post or comment in its reports, like the
+// ON DELETE SET NULL of the reports table. The caller must hold s.mu.
+func (s *Storage) detachReports(postID, commentID int) {
+	for _, report := range s.reports {
+		if report.PostID != nil && *report.PostID == postID {
+			report.PostID = nil
+		}
+		if report.CommentID != nil && *report.CommentID == commentID {
+			report.CommentID = nil
+		}
+	}
+}
+
+// sameContent reports whether both reports are about the same post or
+// comment.
+func sameContent(a, b *model.Report) bool {
+	if a.PostID != nil && b.PostID != nil {
+		return *a.PostID == *b.PostID
+	}
+
+	if a.CommentID != nil && b.CommentID != nil {
+		return *a.CommentID == *b.CommentID
+	}
+
+	return false
+}
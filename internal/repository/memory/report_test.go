@@ -0,0 +1,219 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ReportRepositorySuite struct {
+	suite.Suite
+	storage   *Storage
+	repo      *ReportRepository
+	moderator policy.Actor
+	postID    int
+	commentID int
+}
+
+func (suite *ReportRepositorySuite) SetupTest() {
+	suite.storage = NewStorage()
+	suite.repo = NewReportRepository(suite.storage)
+	suite.moderator = policy.Actor{UserID: 3, Role: model.RoleModerator}
+
+	users := NewUserRepository(suite.storage)
+	for _, name := range []string{"author", "reporter", "moderator"} {
+		_, err := users.Register(context.Background(), &model.RegisterReq{
+			Email:    name + "@mail.com",
+			Username: name,
+			Password: "test",
+		})
+		suite.Require().NoError(err)
+	}
+
+	post, err := NewPostRepository(suite.storage).CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "test", Body: "test", AllowComments: true})
+	suite.Require().NoError(err)
+	suite.postID = post.ID
+
+	comment, err := NewCommentRepository(suite.storage).CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: post.ID, Body: "test"})
+	suite.Require().NoError(err)
+	suite.commentID = comment.ID
+}
+
+func TestReportRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReportRepositorySuite))
+}
+
+// Reports
+// ==============================================
+
+func (suite *ReportRepositorySuite) TestRepository_ReportPostTwice() {
+	report, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+	suite.Equal(model.ReportStatusOpen, report.Status)
+	suite.Equal(suite.postID, *report.PostID)
+
+	_, err = suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonOther, nil)
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+
+	// another reporter can still report it
+	_, err = suite.repo.ReportPost(context.Background(), 3, suite.postID, model.ReportReasonSpam, nil)
+	suite.Nil(err)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ReportPostNotFound() {
+	_, err := suite.repo.ReportPost(context.Background(), 2, 42, model.ReportReasonSpam, nil)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	_, err = suite.repo.ReportComment(context.Background(), 2, 42, model.ReportReasonSpam, nil)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *ReportRepositorySuite) TestRepository_GetReportsOldestFirst() {
+	first, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+	second, err := suite.repo.ReportComment(context.Background(), 2, suite.commentID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	limit := 1
+	page, err := suite.repo.GetReports(context.Background(), model.ReportStatusOpen, &limit, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Edges, 1)
+	suite.Equal(first.ID, page.Edges[0].Node.ID)
+	suite.True(page.PageInfo.HasNextPage)
+
+	page, err = suite.repo.GetReports(context.Background(), model.ReportStatusOpen, &limit, page.PageInfo.EndCursor)
+	suite.Require().NoError(err)
+	suite.Require().Len(page.Edges, 1)
+	suite.Equal(second.ID, page.Edges[0].Node.ID)
+	suite.False(page.PageInfo.HasNextPage)
+
+	resolved, err := suite.repo.GetReports(context.Background(), model.ReportStatusResolved, nil, nil)
+	suite.Require().NoError(err)
+	suite.Empty(resolved.Edges)
+}
+
+// ResolveReport
+// ==============================================
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportResolvesAllReportsOfContent() {
+	report, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+	_, err = suite.repo.ReportPost(context.Background(), 3, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+	_, err = suite.repo.ReportComment(context.Background(), 2, suite.commentID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	resolved, err := suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionDismiss)
+	suite.Require().NoError(err)
+	suite.Equal(model.ReportStatusResolved, resolved.Status)
+	suite.Equal(model.ReportActionDismiss, *resolved.Action)
+	suite.Equal(3, *resolved.ResolvedByID)
+
+	open, err := suite.repo.GetReports(context.Background(), model.ReportStatusOpen, nil, nil)
+	suite.Require().NoError(err)
+	suite.Require().Len(open.Edges, 1)
+	suite.Equal(suite.commentID, *open.Edges[0].Node.CommentID)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionHide)
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportHidePost() {
+	report, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionHide)
+	suite.Require().NoError(err)
+
+	post := suite.storage.posts[suite.postID]
+	suite.Equal(model.PostStatusDraft, post.Status)
+	suite.True(post.IsLocked)
+	suite.Nil(post.DeletedAt)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportHidePostHidesComments() {
+	report, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionHide)
+	suite.Require().NoError(err)
+
+	comments := NewCommentRepository(suite.storage)
+
+	connection, err := comments.GetCommentsByPostID(context.Background(), suite.postID, nil, nil, nil, nil, model.CommentOrderOldest)
+	suite.Nil(err)
+	suite.Empty(connection.Edges)
+
+	_, err = comments.GetCommentByID(context.Background(), suite.commentID)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportDeleteComment() {
+	first, err := suite.repo.ReportComment(context.Background(), 2, suite.commentID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+	second, err := suite.repo.ReportComment(context.Background(), 3, suite.commentID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	report, err := suite.repo.ResolveReport(context.Background(), suite.moderator, first.ID, model.ReportActionDelete)
+	suite.Require().NoError(err)
+	suite.Nil(report.CommentID)
+
+	suite.NotContains(suite.storage.comments, suite.commentID)
+	suite.Equal(model.ReportStatusResolved, suite.storage.reports[second.ID].Status)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportBanAuthor() {
+	session, err := NewSessionRepository(suite.storage).CreateSession(context.Background(), 1, "hash", time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	report, err := suite.repo.ReportComment(context.Background(), 2, suite.commentID, model.ReportReasonHateSpeech, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionBanAuthor)
+	suite.Require().NoError(err)
+
+	author := suite.storage.users[1]
+	suite.NotNil(author.BannedAt)
+	suite.Nil(author.BannedUntil)
+	suite.Equal("reported for HATE_SPEECH", author.BanReason)
+	suite.True(suite.storage.comments[suite.commentID].IsDeleted())
+
+	isActive, err := NewSessionRepository(suite.storage).IsSessionActive(context.Background(), session.ID)
+	suite.Nil(err)
+	suite.False(isActive)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportBanAuthorOutranked() {
+	suite.storage.users[1].Role = model.RoleAdmin
+
+	report, err := suite.repo.ReportPost(context.Background(), 2, suite.postID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionBanAuthor)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+
+	suite.Nil(suite.storage.users[1].BannedAt)
+	suite.Equal(model.PostStatusPublished, suite.storage.posts[suite.postID].Status)
+	suite.Equal(model.ReportStatusOpen, suite.storage.reports[report.ID].Status)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportBanAuthorSelf() {
+	comment, err := NewCommentRepository(suite.storage).CreateComment(context.Background(), 3, &model.CreateCommentReq{PostID: suite.postID, Body: "test"})
+	suite.Require().NoError(err)
+
+	report, err := suite.repo.ReportComment(context.Background(), 2, comment.ID, model.ReportReasonSpam, nil)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.ResolveReport(context.Background(), suite.moderator, report.ID, model.ReportActionBanAuthor)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.storage.users[3].BannedAt)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportNotFound() {
+	_, err := suite.repo.ResolveReport(context.Background(), suite.moderator, 42, model.ReportActionDismiss)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/search"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const snippetWords = 30
+
+// SearchRepository is a naive stand-in for full-text search: every word of
+// the query has to occur in the text, and results are ranked by how often
+// they do, with words of post titles counting twice.
+type SearchRepository struct {
+	s *Storage
+}
+
+func NewSearchRepository(s *Storage) *SearchRepository {
+	return &SearchRepository{
+		s: s,
+	}
+}
+
+func (r *SearchRepository) Search(ctx context.Context, query string, first *int, after *string) (*model.SearchConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make(map[string]bool)
+	for _, word := range strings.Fields(query) {
+		if term := normalizeWord(word); term != "" {
+			terms[term] = true
+		}
+	}
+
+	var edges []*model.SearchEdge
+	positions := make(map[*model.SearchEdge]cursor.Cursor)
+
+	add := func(node model.SearchResult, kind string, id int, rank float64, text string) {
+		position := cursor.NewRank(rank, kind, id)
+		if afterCursor != nil && !position.Before(*afterCursor) {
+			return
+		}
+
+		edge := &model.SearchEdge{
+			Cursor:  position.Encode(),
+			Node:    node,
+			Snippet: snippet(text, terms),
+		}
+		positions[edge] = position
+		edges = append(edges, edge)
+	}
+
+	r.s.mu.RLock()
+
+	if len(terms) > 0 {
+		for _, post := range r.s.posts {
+			if !isVisible(post) {
+				continue
+			}
+
+			text := post.Title + " " + post.Body
+			if !matchAll(terms, text) {
+				continue
+			}
+
+			rank := 2*countMatches(terms, post.Title) + countMatches(terms, post.Body)
+			add(r.s.postView(post), search.KindPost, post.ID, rank, text)
+		}
+
+		for _, comment := range r.s.comments {
+			if comment.IsDeleted() {
+				continue
+			}
+			if post, ok := r.s.posts[comment.PostID]; !ok || !isVisible(post) {
+				continue
+			}
+			if !matchAll(terms, comment.Body) {
+				continue
+			}
+
+			view := *comment
+			add(&view, search.KindComment, comment.ID, countMatches(terms, comment.Body), comment.Body)
+		}
+	}
+
+	r.s.mu.RUnlock()
+
+	sort.Slice(edges, func(i, j int) bool {
+		return positions[edges[j]].Before(positions[edges[i]])
+	})
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(edges) > *first {
+		edges = edges[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	if edges == nil {
+		edges = []*model.SearchEdge{}
+	}
+
+	return &model.SearchConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// countMatches counts the words of text that are among the terms.
+func countMatches(terms map[string]bool, text string) float64 {
+	count := 0
+	for _, word := range strings.Fields(text) {
+		if terms[normalizeWord(word)] {
+			count++
+		}
+	}
+
+	return float64(count)
+}
+
+// matchAll reports whether every term occurs in text.
+func matchAll(terms map[string]bool, text string) bool {
+	found := make(map[string]bool, len(terms))
+	for _, word := range strings.Fields(text) {
+		if word = normalizeWord(word); terms[word] {
+			found[word] = true
+		}
+	}
+
+	return len(found) == len(terms)
+}
+
+// snippet returns the words of text around the first match with matching
+// words highlighted the same way ts_headline does.
+func snippet(text string, terms map[string]bool) string {
+	words := strings.Fields(text)
+
+	start := 0
+	for i, word := range words {
+		if terms[normalizeWord(word)] {
+			start = max(0, i-snippetWords/3)
+			break
+		}
+	}
+
+	end := min(len(words), start+snippetWords)
+
+	highlighted := make([]string, 0, end-start)
+	for _, word := range words[start:end] {
+		if terms[normalizeWord(word)] {
+			word = "<b>" + word + "</b>"
+		}
+		highlighted = append(highlighted, word)
+	}
+
+	return strings.Join(highlighted, " ")
+}
+
+func normalizeWord(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
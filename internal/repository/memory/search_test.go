@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type SearchRepositorySuite struct {
+	suite.Suite
+	repo     *SearchRepository
+	posts    *PostRepository
+	comments *CommentRepository
+}
+
+func (suite *SearchRepositorySuite) SetupTest() {
+	storage := NewStorage()
+	suite.repo = NewSearchRepository(storage)
+	suite.posts = NewPostRepository(storage)
+	suite.comments = NewCommentRepository(storage)
+
+	_, err := NewUserRepository(storage).Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+}
+
+func TestSearchRepositorySuite(t *testing.T) {
+	suite.Run(t, new(SearchRepositorySuite))
+}
+
+func (suite *SearchRepositorySuite) createPost(title, body string) *model.Post {
+	post, err := suite.posts.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: title, Body: body, AllowComments: true})
+	suite.Require().NoError(err)
+
+	return post
+}
+
+// Search
+// ==============================================
+
+func (suite *SearchRepositorySuite) TestRepository_SearchRanksTitlesHigher() {
+	inBody := suite.createPost("first", "all about GraphQL")
+	inTitle := suite.createPost("GraphQL!", "a post")
+	suite.createPost("other", "nothing to see")
+
+	comment, err := suite.comments.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: inBody.ID, Body: "graphql graphql graphql"})
+	suite.Require().NoError(err)
+
+	results, err := suite.repo.Search(context.Background(), "graphql", nil, nil)
+	suite.Nil(err)
+	suite.Len(results.Edges, 3)
+
+	suite.Equal(comment.ID, results.Edges[0].Node.(*model.Comment).ID)
+	suite.Equal(inTitle.ID, results.Edges[1].Node.(*model.Post).ID)
+	suite.Equal(inBody.ID, results.Edges[2].Node.(*model.Post).ID)
+	suite.Equal("first all about <b>GraphQL</b>", results.Edges[2].Snippet)
+}
+
+func (suite *SearchRepositorySuite) TestRepository_SearchRequiresAllWords() {
+	suite.createPost("graphql", "servers in go")
+	suite.createPost("graphql", "clients")
+
+	results, err := suite.repo.Search(context.Background(), "GraphQL go", nil, nil)
+
+	suite.Nil(err)
+	suite.Len(results.Edges, 1)
+}
+
+func (suite *SearchRepositorySuite) TestRepository_SearchPagination() {
+	for i := 0; i < 3; i++ {
+		suite.createPost("graphql", "post")
+	}
+
+	var ids []int
+	first := 1
+	var after *string
+	for {
+		results, err := suite.repo.Search(context.Background(), "graphql", &first, after)
+		suite.Require().NoError(err)
+
+		for _, edge := range results.Edges {
+			ids = append(ids, edge.Node.(*model.Post).ID)
+		}
+		if !results.PageInfo.HasNextPage {
+			break
+		}
+		after = results.PageInfo.EndCursor
+	}
+
+	suite.Equal([]int{3, 2, 1}, ids)
+}
+
+func (suite *SearchRepositorySuite) TestRepository_SearchSkipsHiddenPosts() {
+	post := suite.createPost("graphql", "post")
+
+	_, err := suite.comments.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: post.ID, Body: "graphql"})
+	suite.Require().NoError(err)
+
+	suite.Require().NoError(suite.posts.DeletePost(context.Background(), 1, post.ID))
+
+	results, err := suite.repo.Search(context.Background(), "graphql", nil, nil)
+
+	suite.Nil(err)
+	suite.Empty(results.Edges)
+}
@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	sessionRepository "github.com/aaanger/graphql-test/internal/repository/session"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"time"
+)
+
+type session struct {
+	model.Session
+	refreshTokenHash string
+	revoked          bool
+}
+
+func (s *session) isActive() bool {
+	return !s.revoked && time.Now().Before(s.ExpiresAt)
+}
+
+type SessionRepository struct {
+	s *Storage
+}
+
+func NewSessionRepository(s *Storage) *SessionRepository {
+	return &SessionRepository{
+		s: s,
+	}
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, userID int, refreshTokenHash string, expiresAt time.Time) (*model.Session, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	if _, ok := r.s.users[userID]; !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	for _, s := range r.s.sessions {
+		if s.refreshTokenHash == refreshTokenHash {
+			return nil, apperror.Conflict("refresh token already exists")
+		}
+	}
+
+	r.s.lastSessionID++
+	s := &session{
+		Session: model.Session{
+			ID:        r.s.lastSessionID,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+			CreatedAt: time.Now(),
+		},
+		refreshTokenHash: refreshTokenHash,
+	}
+	r.s.sessions[s.ID] = s
+
+	view := s.Session
+
+	return &view, nil
+}
+
+func (r *SessionRepository) RotateSession(ctx context.Context, refreshTokenHash, newRefreshTokenHash string, expiresAt time.Time) (*model.Session, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, s := range r.s.sessions {
+		if s.refreshTokenHash != refreshTokenHash {
+			continue
+		}
+
+		if !s.isActive() {
+			break
+		}
+
+		s.refreshTokenHash = newRefreshTokenHash
+		s.ExpiresAt = expiresAt
+
+		view := s.Session
+
+		return &view, nil
+	}
+
+	return nil, sessionRepository.ErrInvalidRefreshToken
+}
+
+func (r *SessionRepository) IsSessionActive(ctx context.Context, sessionID int) (bool, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	s, ok := r.s.sessions[sessionID]
+
+	return ok && s.isActive(), nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	s, ok := r.s.sessions[sessionID]
+	if ok && s.UserID == userID {
+		s.revoked = true
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllSessions(ctx context.Context, userID int) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	for _, s := range r.s.sessions {
+		if s.UserID == userID {
+			s.revoked = true
+		}
+	}
+
+	return nil
+}
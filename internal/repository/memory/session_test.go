@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	sessionRepository "github.com/aaanger/graphql-test/internal/repository/session"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type SessionRepositorySuite struct {
+	suite.Suite
+	repo *SessionRepository
+}
+
+func (suite *SessionRepositorySuite) SetupTest() {
+	storage := NewStorage()
+	suite.repo = NewSessionRepository(storage)
+
+	users := NewUserRepository(storage)
+	for _, name := range []string{"first", "second"} {
+		_, err := users.Register(context.Background(), &model.RegisterReq{
+			Email:    name + "@mail.com",
+			Username: name,
+			Password: "test",
+		})
+		suite.Require().NoError(err)
+	}
+}
+
+func TestSessionRepositorySuite(t *testing.T) {
+	suite.Run(t, new(SessionRepositorySuite))
+}
+
+func (suite *SessionRepositorySuite) createSession(userID int, hash string) *model.Session {
+	session, err := suite.repo.CreateSession(context.Background(), userID, hash, time.Now().Add(time.Hour))
+	suite.Require().NoError(err)
+
+	return session
+}
+
+// RotateSession
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_RotateSessionOnlyOnce() {
+	created := suite.createSession(1, "old")
+
+	session, err := suite.repo.RotateSession(context.Background(), "old", "new", time.Now().Add(time.Hour))
+	suite.Nil(err)
+	suite.Equal(created.ID, session.ID)
+	suite.Equal(1, session.UserID)
+
+	session, err = suite.repo.RotateSession(context.Background(), "old", "newer", time.Now().Add(time.Hour))
+	suite.Nil(session)
+	suite.ErrorIs(err, sessionRepository.ErrInvalidRefreshToken)
+}
+
+func (suite *SessionRepositorySuite) TestRepository_RotateSessionExpired() {
+	_, err := suite.repo.CreateSession(context.Background(), 1, "old", time.Now().Add(-time.Minute))
+	suite.Require().NoError(err)
+
+	session, err := suite.repo.RotateSession(context.Background(), "old", "new", time.Now().Add(time.Hour))
+
+	suite.Nil(session)
+	suite.ErrorIs(err, sessionRepository.ErrInvalidRefreshToken)
+}
+
+// RevokeSession
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_RevokeSessionOwner() {
+	created := suite.createSession(1, "token")
+
+	err := suite.repo.RevokeSession(context.Background(), 2, created.ID)
+	suite.Nil(err)
+
+	isActive, err := suite.repo.IsSessionActive(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.True(isActive)
+
+	err = suite.repo.RevokeSession(context.Background(), 1, created.ID)
+	suite.Nil(err)
+
+	isActive, err = suite.repo.IsSessionActive(context.Background(), created.ID)
+	suite.Nil(err)
+	suite.False(isActive)
+
+	_, err = suite.repo.RotateSession(context.Background(), "token", "new", time.Now().Add(time.Hour))
+	suite.ErrorIs(err, sessionRepository.ErrInvalidRefreshToken)
+}
+
+func (suite *SessionRepositorySuite) TestRepository_RevokeAllSessions() {
+	first := suite.createSession(1, "first")
+	second := suite.createSession(1, "second")
+	other := suite.createSession(2, "other")
+
+	err := suite.repo.RevokeAllSessions(context.Background(), 1)
+	suite.Nil(err)
+
+	for id, expected := range map[int]bool{first.ID: false, second.ID: false, other.ID: true} {
+		isActive, err := suite.repo.IsSessionActive(context.Background(), id)
+		suite.Nil(err)
+		suite.Equal(expected, isActive)
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"sync"
+	"time"
+)
+
+// Storage keeps all entities of the in-memory backend. Repositories created
+// from the same Storage share its data, the same way SQL repositories share
+// one database.
+type Storage struct {
+	mu sync.RWMutex
+
+	users    map[int]*model.User
+	posts    map[int]*model.Post
+	comments map[int]*model.Comment
+	sessions map[int]*session
+
+	postRevisions    map[int]*model.PostRevision
+	commentRevisions map[int]*model.CommentRevision
+
+	postVotes    map[voteKey]model.VoteValue
+	commentVotes map[voteKey]model.VoteValue
+
+	reactions map[reactionKey]time.Time
+
+	reports map[int]*model.Report
+
+	loginIPs map[string]*loginIPAttempts
+
+	lastUserID    int
+	lastPostID    int
+	lastCommentID int
+	lastSessionID int
+	lastReportID  int
+
+	lastPostRevisionID    int
+	lastCommentRevisionID int
+}
+
+// loginIPAttempts counts failed logins from one IP, like the
+// login_ip_attempts table.
+type loginIPAttempts struct {
+	failed      int
+	lockedUntil time.Time
+	updatedAt   time.Time
+}
+
+func NewStorage() *Storage {
+	return &Storage{
+		users:    make(map[int]*model.User),
+		posts:    make(map[int]*model.Post),
+		comments: make(map[int]*model.Comment),
+		sessions: make(map[int]*session),
+
+		postRevisions:    make(map[int]*model.PostRevision),
+		commentRevisions: make(map[int]*model.CommentRevision),
+
+		postVotes:    make(map[voteKey]model.VoteValue),
+		commentVotes: make(map[voteKey]model.VoteValue),
+
+		reactions: make(map[reactionKey]time.Time),
+
+		reports: make(map[int]*model.Report),
+
+		loginIPs: make(map[string]*loginIPAttempts),
+	}
+}
@@ -0,0 +1,252 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	userRepository "github.com/aaanger/graphql-test/internal/repository/user"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
+	"time"
+)
+
+type UserRepository struct {
+	s *Storage
+}
+
+func NewUserRepository(s *Storage) *UserRepository {
+	return &UserRepository{
+		s: s,
+	}
+}
+
+func (r *UserRepository) Register(ctx context.Context, req *model.RegisterReq) (*model.User, error) {
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := model.User{
+		Email:    strings.ToLower(req.Email),
+		Username: req.Username,
+		Password: string(hashedBytes),
+		Role:     model.RoleUser,
+	}
+
+	r.s.mu.Lock()
+	for _, u := range r.s.users {
+		if u.Email == user.Email {
+			r.s.mu.Unlock()
+			return nil, apperror.Conflict("user with this email or username already exists")
+		}
+		if u.Username == user.Username {
+			r.s.mu.Unlock()
+			return nil, apperror.Conflict("user with this email or username already exists")
+		}
+	}
+	r.s.lastUserID++
+	user.ID = r.s.lastUserID
+	stored := user
+	r.s.users[user.ID] = &stored
+	r.s.mu.Unlock()
+
+	return &user, nil
+}
+
+func (r *UserRepository) Login(ctx context.Context, req *model.LoginReq, ip string) (*model.User, error) {
+	email := strings.ToLower(req.Email)
+	now := time.Now()
+
+	r.s.mu.RLock()
+	if a, ok := r.s.loginIPs[ip]; ok && a.lockedUntil.After(now) {
+		r.s.mu.RUnlock()
+		return nil, apperror.RateLimited(a.lockedUntil.Sub(now))
+	}
+	var user *model.User
+	for _, u := range r.s.users {
+		if u.Email == email {
+			found := *u
+			user = &found
+			break
+		}
+	}
+	r.s.mu.RUnlock()
+
+	if user == nil {
+		userRepository.CompareDummyPassword(req.Password)
+		return nil, r.failLogin(0, ip)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+
+	// locked accounts answer like unknown emails
+	if user.LockedUntil != nil && user.LockedUntil.After(now) {
+		return nil, r.failLogin(0, ip)
+	}
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, r.failLogin(user.ID, ip)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		r.s.mu.Lock()
+		if u, ok := r.s.users[user.ID]; ok {
+			u.FailedLoginAttempts = 0
+			u.LockedUntil = nil
+		}
+		r.s.mu.Unlock()
+
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+	}
+
+	if user.IsBanned(time.Now()) {
+		return nil, apperror.Banned(user.BannedUntil, user.BanReason)
+	}
+
+	return user, nil
+}
+
+// failLogin counts a failed login of the account, unless the email is
+// unknown, and of the IP, locks whichever failed too often and returns
+// ErrInvalidCredentials.
+func (r *UserRepository) failLogin(userID int, ip string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	now := time.Now()
+
+	if u, ok := r.s.users[userID]; ok {
+		u.FailedLoginAttempts++
+
+		if lockout := userRepository.Lockout(u.FailedLoginAttempts, userRepository.MaxFailedLogins); lockout > 0 {
+			lockedUntil := now.UTC().Add(lockout)
+			u.LockedUntil = &lockedUntil
+		}
+	}
+
+	if ip != "" {
+		a, ok := r.s.loginIPs[ip]
+		if !ok || now.Sub(a.updatedAt) > userRepository.IPFailureWindow {
+			a = &loginIPAttempts{}
+			r.s.loginIPs[ip] = a
+		}
+
+		a.failed++
+		a.updatedAt = now
+
+		if lockout := userRepository.Lockout(a.failed, userRepository.MaxFailedLoginsPerIP); lockout > 0 {
+			a.lockedUntil = now.Add(lockout)
+		}
+	}
+
+	return userRepository.ErrInvalidCredentials
+}
+
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]*model.User, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	users := make([]*model.User, 0, len(ids))
+	for _, id := range ids {
+		if u, ok := r.s.users[id]; ok {
+			profile := userView(u)
+			profile.Email = ""
+			users = append(users, profile)
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) GetEmailsByIDs(ctx context.Context, ids []int) (map[int]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	emails := make(map[int]string, len(ids))
+	for _, id := range ids {
+		if u, ok := r.s.users[id]; ok {
+			emails[id] = u.Email
+		}
+	}
+
+	return emails, nil
+}
+
+func (r *UserRepository) SetRole(ctx context.Context, userID int, role model.Role) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	u.Role = role
+
+	return userView(u), nil
+}
+
+func (r *UserRepository) BanUser(ctx context.Context, userID int, until *time.Time, reason string) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	now := time.Now()
+	u.BannedAt = &now
+	u.BannedUntil = until
+	u.BanReason = reason
+
+	return userView(u), nil
+}
+
+func (r *UserRepository) UnbanUser(ctx context.Context, userID int) (*model.User, error) {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	u, ok := r.s.users[userID]
+	if !ok {
+		return nil, apperror.NotFound("user not found")
+	}
+
+	u.BannedAt = nil
+	u.BannedUntil = nil
+	u.BanReason = ""
+
+	return userView(u), nil
+}
+
+func (r *UserRepository) CheckBan(ctx context.Context, userID int) error {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	u, ok := r.s.users[userID]
+	if !ok || !u.IsBanned(time.Now()) {
+		return nil
+	}
+
+	return apperror.Banned(u.BannedUntil, u.BanReason)
+}
+
+// userView returns a copy of the stored user without the password hash.
+func userView(u *model.User) *model.User {
+	return &model.User{
+		ID:                  u.ID,
+		Email:               u.Email,
+		Username:            u.Username,
+		Role:                u.Role,
+		BannedAt:            u.BannedAt,
+		BannedUntil:         u.BannedUntil,
+		BanReason:           u.BanReason,
+		FailedLoginAttempts: u.FailedLoginAttempts,
+		LockedUntil:         u.LockedUntil,
+	}
+}
@@ -0,0 +1,257 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	userRepository "github.com/aaanger/graphql-test/internal/repository/user"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type UserRepositorySuite struct {
+	suite.Suite
+	repo *UserRepository
+}
+
+func (suite *UserRepositorySuite) SetupTest() {
+	suite.repo = NewUserRepository(NewStorage())
+}
+
+func TestUserRepositorySuite(t *testing.T) {
+	suite.Run(t, new(UserRepositorySuite))
+}
+
+// Register
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_RegisterSuccess() {
+	req := &model.RegisterReq{
+		Email:    "Test@Mail.com",
+		Username: "test",
+		Password: "test",
+	}
+
+	user, err := suite.repo.Register(context.Background(), req)
+
+	suite.Nil(err)
+	suite.Equal(1, user.ID)
+	suite.Equal("test@mail.com", user.Email)
+	suite.Equal(model.RoleUser, user.Role)
+}
+
+func (suite *UserRepositorySuite) TestRepository_RegisterDuplicate() {
+	req := &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	}
+
+	_, err := suite.repo.Register(context.Background(), req)
+	suite.Nil(err)
+
+	user, err := suite.repo.Register(context.Background(), req)
+
+	suite.Nil(user)
+	suite.NotNil(err)
+}
+
+// Login
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_LoginSuccess() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Nil(err)
+
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{
+		Email:    "TEST@mail.com",
+		Password: "test",
+	}, "")
+
+	suite.Nil(err)
+	suite.Equal("test", user.Username)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginWrongPassword() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Nil(err)
+
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{
+		Email:    "test@mail.com",
+		Password: "wrong",
+	}, "")
+
+	suite.Nil(user)
+	suite.NotNil(err)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginNotFound() {
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{
+		Email:    "test@mail.com",
+		Password: "test",
+	}, "")
+
+	suite.Nil(user)
+	suite.ErrorIs(err, userRepository.ErrInvalidCredentials)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginLocksAccount() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+
+	for i := 0; i < userRepository.MaxFailedLogins; i++ {
+		_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "wrong"}, "")
+		suite.ErrorIs(err, userRepository.ErrInvalidCredentials)
+	}
+
+	// the right password doesn't help while the account is locked, and the
+	// answer is the same as for an unknown email
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "test"}, "")
+	suite.Nil(user)
+	suite.ErrorIs(err, userRepository.ErrInvalidCredentials)
+
+	_, unknownErr := suite.repo.Login(context.Background(), &model.LoginReq{Email: "unknown@mail.com", Password: "test"}, "")
+	suite.Equal(unknownErr, err)
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{1})
+	suite.Nil(err)
+	suite.Equal(userRepository.MaxFailedLogins, users[0].FailedLoginAttempts)
+	suite.Require().NotNil(users[0].LockedUntil)
+	suite.Equal(time.UTC, users[0].LockedUntil.Location())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginResetsFailures() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "wrong"}, "")
+	suite.ErrorIs(err, userRepository.ErrInvalidCredentials)
+
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "test"}, "")
+	suite.Nil(err)
+	suite.Equal(0, user.FailedLoginAttempts)
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{1})
+	suite.Nil(err)
+	suite.Equal(0, users[0].FailedLoginAttempts)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginLocksIP() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+
+	// unknown emails count against the IP too
+	for i := 0; i < userRepository.MaxFailedLoginsPerIP; i++ {
+		_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: "unknown@mail.com", Password: "test"}, "10.0.0.1")
+		suite.ErrorIs(err, userRepository.ErrInvalidCredentials)
+	}
+
+	_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "test"}, "10.0.0.1")
+	suite.Equal(apperror.CodeRateLimited, apperror.CodeOf(err))
+
+	user, err := suite.repo.Login(context.Background(), &model.LoginReq{Email: "test@mail.com", Password: "test"}, "10.0.0.2")
+	suite.Nil(err)
+	suite.Equal(1, user.ID)
+}
+
+// SetRole
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_SetRole() {
+	_, err := suite.repo.Register(context.Background(), &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	})
+	suite.Require().NoError(err)
+
+	user, err := suite.repo.SetRole(context.Background(), 1, model.RoleModerator)
+	suite.Nil(err)
+	suite.Equal(model.RoleModerator, user.Role)
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{1})
+	suite.Nil(err)
+	suite.Equal(model.RoleModerator, users[0].Role)
+	suite.Empty(users[0].Email)
+
+	emails, err := suite.repo.GetEmailsByIDs(context.Background(), []int{1, 100})
+	suite.Nil(err)
+	suite.Equal(map[int]string{1: "test@mail.com"}, emails)
+
+	_, err = suite.repo.SetRole(context.Background(), 100, model.RoleAdmin)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// Bans
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_BanUserBlocksLogin() {
+	req := &model.RegisterReq{
+		Email:    "test@mail.com",
+		Username: "test",
+		Password: "test",
+	}
+
+	user, err := suite.repo.Register(context.Background(), req)
+	suite.Require().NoError(err)
+
+	until := time.Now().Add(time.Hour)
+	banned, err := suite.repo.BanUser(context.Background(), user.ID, &until, "spam")
+	suite.Require().NoError(err)
+	suite.True(banned.IsBanned(time.Now()))
+
+	_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: req.Email, Password: req.Password}, "")
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(suite.repo.CheckBan(context.Background(), user.ID)))
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{user.ID})
+	suite.Require().NoError(err)
+	suite.Equal("spam", users[0].BanReason)
+
+	_, err = suite.repo.UnbanUser(context.Background(), user.ID)
+	suite.Require().NoError(err)
+
+	_, err = suite.repo.Login(context.Background(), &model.LoginReq{Email: req.Email, Password: req.Password}, "")
+	suite.Nil(err)
+	suite.Nil(suite.repo.CheckBan(context.Background(), user.ID))
+}
+
+func (suite *UserRepositorySuite) TestRepository_BanExpires() {
+	user, err := suite.repo.Register(context.Background(), &model.RegisterReq{Email: "test@mail.com", Username: "test", Password: "test"})
+	suite.Require().NoError(err)
+
+	until := time.Now().Add(-time.Minute)
+	_, err = suite.repo.BanUser(context.Background(), user.ID, &until, "spam")
+	suite.Require().NoError(err)
+
+	suite.Nil(suite.repo.CheckBan(context.Background(), user.ID))
+}
+
+func (suite *UserRepositorySuite) TestRepository_BanUserNotFound() {
+	_, err := suite.repo.BanUser(context.Background(), 42, nil, "spam")
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+
+	_, err = suite.repo.UnbanUser(context.Background(), 42)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
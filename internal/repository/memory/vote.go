@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+)
+
+// voteKey identifies the vote of a user on a post or a comment.
+type voteKey struct {
+	userID int
+	id     int
+}
+
+type VoteRepository struct {
+	s *Storage
+}
+
+func NewVoteRepository(s *Storage) *VoteRepository {
+	return &VoteRepository{
+		s: s,
+	}
+}
+
+func (r *VoteRepository) VotePost(ctx context.Context, userID, postID int, value model.VoteValue) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	post, ok := r.s.posts[postID]
+	if !ok || !isVisible(post) {
+		return apperror.NotFound("post not found")
+	}
+
+	upvotes, downvotes := replaceVote(r.s.postVotes, voteKey{userID: userID, id: postID}, value)
+	post.Upvotes += upvotes
+	post.Downvotes += downvotes
+
+	return nil
+}
+
+func (r *VoteRepository) VoteComment(ctx context.Context, userID, commentID int, value model.VoteValue) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+
+	comment, ok := r.s.comments[commentID]
+	if !ok || comment.IsDeleted() || !r.s.isCommentVisible(comment) {
+		return apperror.NotFound("comment not found")
+	}
+
+	upvotes, downvotes := replaceVote(r.s.commentVotes, voteKey{userID: userID, id: commentID}, value)
+	comment.Upvotes += upvotes
+	comment.Downvotes += downvotes
+
+	return nil
+}
+
+func (r *VoteRepository) GetPostVotes(ctx context.Context, userID int, postIDs []int) (map[int]model.VoteValue, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return userVotes(r.s.postVotes, userID, postIDs), nil
+}
+
+func (r *VoteRepository) GetCommentVotes(ctx context.Context, userID int, commentIDs []int) (map[int]model.VoteValue, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+
+	return userVotes(r.s.commentVotes, userID, commentIDs), nil
+}
+
+// replaceVote stores the vote and returns how the counters of the voted
+// entity change. The caller must hold s.mu.
+func replaceVote(votes map[voteKey]model.VoteValue, key voteKey, value model.VoteValue) (upvotes, downvotes int) {
+	previous, ok := votes[key]
+	if !ok {
+		previous = model.VoteValueNone
+	}
+
+	if value == model.VoteValueNone {
+		delete(votes, key)
+	} else {
+		votes[key] = value
+	}
+
+	return model.VoteDelta(previous, value)
+}
+
+// userVotes returns the votes of the user on the entities in ids. The caller
+// must hold s.mu.
+func userVotes(votes map[voteKey]model.VoteValue, userID int, ids []int) map[int]model.VoteValue {
+	result := make(map[int]model.VoteValue, len(ids))
+
+	for _, id := range ids {
+		if value, ok := votes[voteKey{userID: userID, id: id}]; ok {
+			result[id] = value
+		}
+	}
+
+	return result
+}
+
+// deleteVotes removes all votes on the entity. The caller must hold s.mu.
+func deleteVotes(votes map[voteKey]model.VoteValue, id int) {
+	for key := range votes {
+		if key.id == id {
+			delete(votes, key)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package memory
+
+import (
+	"context"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type VoteRepositorySuite struct {
+	suite.Suite
+	storage   *Storage
+	repo      *VoteRepository
+	posts     *PostRepository
+	comments  *CommentRepository
+	postID    int
+	commentID int
+}
+
+func (suite *VoteRepositorySuite) SetupTest() {
+	suite.storage = NewStorage()
+	suite.repo = NewVoteRepository(suite.storage)
+	suite.posts = NewPostRepository(suite.storage)
+	suite.comments = NewCommentRepository(suite.storage)
+
+	users := NewUserRepository(suite.storage)
+	for _, name := range []string{"first", "second"} {
+		_, err := users.Register(context.Background(), &model.RegisterReq{
+			Email:    name + "@mail.com",
+			Username: name,
+			Password: "test",
+		})
+		suite.Require().NoError(err)
+	}
+
+	post, err := suite.posts.CreatePost(context.Background(), 1, &model.CreatePostReq{Title: "test", Body: "test", AllowComments: true})
+	suite.Require().NoError(err)
+	suite.postID = post.ID
+
+	comment, err := suite.comments.CreateComment(context.Background(), 1, &model.CreateCommentReq{PostID: post.ID, Body: "test"})
+	suite.Require().NoError(err)
+	suite.commentID = comment.ID
+}
+
+func TestVoteRepositorySuite(t *testing.T) {
+	suite.Run(t, new(VoteRepositorySuite))
+}
+
+// VotePost
+// ==============================================
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostOneVotePerUser() {
+	for _, value := range []model.VoteValue{model.VoteValueUp, model.VoteValueUp, model.VoteValueDown} {
+		suite.Require().NoError(suite.repo.VotePost(context.Background(), 1, suite.postID, value))
+	}
+	suite.Require().NoError(suite.repo.VotePost(context.Background(), 2, suite.postID, model.VoteValueDown))
+
+	post, err := suite.posts.GetPostByID(context.Background(), suite.postID)
+	suite.Nil(err)
+	suite.Equal(0, post.Upvotes)
+	suite.Equal(2, post.Downvotes)
+	suite.Equal(-2, post.Score())
+
+	suite.Require().NoError(suite.repo.VotePost(context.Background(), 1, suite.postID, model.VoteValueNone))
+
+	post, err = suite.posts.GetPostByID(context.Background(), suite.postID)
+	suite.Nil(err)
+	suite.Equal(1, post.Downvotes)
+
+	votes, err := suite.repo.GetPostVotes(context.Background(), 2, []int{suite.postID})
+	suite.Nil(err)
+	suite.Equal(map[int]model.VoteValue{suite.postID: model.VoteValueDown}, votes)
+
+	votes, err = suite.repo.GetPostVotes(context.Background(), 1, []int{suite.postID})
+	suite.Nil(err)
+	suite.Empty(votes)
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostDeleted() {
+	suite.Require().NoError(suite.posts.DeletePost(context.Background(), 1, suite.postID))
+
+	err := suite.repo.VotePost(context.Background(), 2, suite.postID, model.VoteValueUp)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *VoteRepositorySuite) TestRepository_PurgedPostDropsVotes() {
+	suite.Require().NoError(suite.repo.VotePost(context.Background(), 2, suite.postID, model.VoteValueUp))
+	suite.Require().NoError(suite.repo.VoteComment(context.Background(), 2, suite.commentID, model.VoteValueUp))
+	suite.Require().NoError(suite.posts.DeletePost(context.Background(), 1, suite.postID))
+
+	_, err := suite.posts.PurgeDeletedPosts(context.Background(), time.Now().Add(time.Minute))
+	suite.Require().NoError(err)
+
+	suite.Empty(suite.storage.postVotes)
+	suite.Empty(suite.storage.commentVotes)
+}
+
+// VoteComment
+// ==============================================
+
+func (suite *VoteRepositorySuite) TestRepository_VoteComment() {
+	suite.Require().NoError(suite.repo.VoteComment(context.Background(), 1, suite.commentID, model.VoteValueUp))
+	suite.Require().NoError(suite.repo.VoteComment(context.Background(), 2, suite.commentID, model.VoteValueUp))
+
+	comment, err := suite.comments.GetCommentByID(context.Background(), suite.commentID)
+	suite.Nil(err)
+	suite.Equal(2, comment.Upvotes)
+	suite.Equal(2, comment.Score())
+
+	votes, err := suite.repo.GetCommentVotes(context.Background(), 1, []int{suite.commentID, 100})
+	suite.Nil(err)
+	suite.Equal(map[int]model.VoteValue{suite.commentID: model.VoteValueUp}, votes)
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VoteCommentDeleted() {
+	suite.Require().NoError(suite.comments.DeleteComment(context.Background(), policy.Actor{UserID: 1}, suite.commentID))
+
+	err := suite.repo.VoteComment(context.Background(), 2, suite.commentID, model.VoteValueUp)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VoteCommentOfDeletedPost() {
+	suite.Require().NoError(suite.posts.DeletePost(context.Background(), 1, suite.postID))
+
+	err := suite.repo.VoteComment(context.Background(), 2, suite.commentID, model.VoteValueUp)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
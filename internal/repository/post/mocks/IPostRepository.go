@@ -4,9 +4,13 @@ package mocks
 
 import (
 	context "context"
-	model2 "github.com/aaanger/graphql-test/internal/graph/model"
 
+	model "github.com/aaanger/graphql-test/internal/graph/model"
 	mock "github.com/stretchr/testify/mock"
+
+	policy "github.com/aaanger/graphql-test/internal/policy"
+
+	time "time"
 )
 
 // IPostRepository is an autogenerated mock type for the IPostRepository type
@@ -15,27 +19,27 @@ type IPostRepository struct {
 }
 
 // CreatePost provides a mock function with given fields: ctx, userID, req
-func (_m *IPostRepository) CreatePost(ctx context.Context, userID int, req *model2.CreatePostReq) (*model2.Post, error) {
+func (_m *IPostRepository) CreatePost(ctx context.Context, userID int, req *model.CreatePostReq) (*model.Post, error) {
 	ret := _m.Called(ctx, userID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for CreatePost")
 	}
 
-	var r0 *model2.Post
+	var r0 *model.Post
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, *model2.CreatePostReq) (*model2.Post, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int, *model.CreatePostReq) (*model.Post, error)); ok {
 		return rf(ctx, userID, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, *model2.CreatePostReq) *model2.Post); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int, *model.CreatePostReq) *model.Post); ok {
 		r0 = rf(ctx, userID, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model2.Post)
+			r0 = ret.Get(0).(*model.Post)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, *model2.CreatePostReq) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, int, *model.CreatePostReq) error); ok {
 		r1 = rf(ctx, userID, req)
 	} else {
 		r1 = ret.Error(1)
@@ -63,23 +67,53 @@ func (_m *IPostRepository) DeletePost(ctx context.Context, userID int, postID in
 }
 
 // GetAllPostsByUserID provides a mock function with given fields: ctx, userID
-func (_m *IPostRepository) GetAllPostsByUserID(ctx context.Context, userID int) ([]*model2.Post, error) {
+func (_m *IPostRepository) GetAllPostsByUserID(ctx context.Context, userID int) ([]*model.Post, error) {
 	ret := _m.Called(ctx, userID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetAllPostsByUserID")
 	}
 
-	var r0 []*model2.Post
+	var r0 []*model.Post
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*model2.Post, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*model.Post, error)); ok {
 		return rf(ctx, userID)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) []*model2.Post); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*model.Post); ok {
 		r0 = rf(ctx, userID)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*model2.Post)
+			r0 = ret.Get(0).([]*model.Post)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDraftsByUserID provides a mock function with given fields: ctx, userID
+func (_m *IPostRepository) GetDraftsByUserID(ctx context.Context, userID int) ([]*model.Post, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetDraftsByUserID")
+	}
+
+	var r0 []*model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]*model.Post, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []*model.Post); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Post)
 		}
 	}
 
@@ -93,23 +127,23 @@ func (_m *IPostRepository) GetAllPostsByUserID(ctx context.Context, userID int)
 }
 
 // GetPostByID provides a mock function with given fields: ctx, id
-func (_m *IPostRepository) GetPostByID(ctx context.Context, id int) (*model2.Post, error) {
+func (_m *IPostRepository) GetPostByID(ctx context.Context, id int) (*model.Post, error) {
 	ret := _m.Called(ctx, id)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetPostByID")
 	}
 
-	var r0 *model2.Post
+	var r0 *model.Post
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*model2.Post, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*model.Post, error)); ok {
 		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *model2.Post); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, int) *model.Post); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model2.Post)
+			r0 = ret.Get(0).(*model.Post)
 		}
 	}
 
@@ -122,22 +156,270 @@ func (_m *IPostRepository) GetPostByID(ctx context.Context, id int) (*model2.Pos
 	return r0, r1
 }
 
-// UpdatePost provides a mock function with given fields: ctx, userID, postID, req
-func (_m *IPostRepository) UpdatePost(ctx context.Context, userID int, postID int, req *model2.UpdatePostReq) error {
-	ret := _m.Called(ctx, userID, postID, req)
+// GetPostRevisions provides a mock function with given fields: ctx, postID, first, after
+func (_m *IPostRepository) GetPostRevisions(ctx context.Context, postID int, first *int, after *string) (*model.PostRevisionConnection, error) {
+	ret := _m.Called(ctx, postID, first, after)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPostRevisions")
+	}
+
+	var r0 *model.PostRevisionConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *string) (*model.PostRevisionConnection, error)); ok {
+		return rf(ctx, postID, first, after)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *int, *string) *model.PostRevisionConnection); ok {
+		r0 = rf(ctx, postID, first, after)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PostRevisionConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *int, *string) error); ok {
+		r1 = rf(ctx, postID, first, after)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPosts provides a mock function with given fields: ctx, first, last, after, before, orderBy
+func (_m *IPostRepository) GetPosts(ctx context.Context, first *int, last *int, after *string, before *string, orderBy model.PostOrder) (*model.PostConnection, error) {
+	ret := _m.Called(ctx, first, last, after, before, orderBy)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPosts")
+	}
+
+	var r0 *model.PostConnection
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, *string, *string, model.PostOrder) (*model.PostConnection, error)); ok {
+		return rf(ctx, first, last, after, before, orderBy)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *int, *int, *string, *string, model.PostOrder) *model.PostConnection); ok {
+		r0 = rf(ctx, first, last, after, before, orderBy)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.PostConnection)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *int, *int, *string, *string, model.PostOrder) error); ok {
+		r1 = rf(ctx, first, last, after, before, orderBy)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ModeratePost provides a mock function with given fields: ctx, postID, req
+func (_m *IPostRepository) ModeratePost(ctx context.Context, postID int, req *model.ModeratePostReq) (*model.Post, error) {
+	ret := _m.Called(ctx, postID, req)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ModeratePost")
+	}
+
+	var r0 *model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *model.ModeratePostReq) (*model.Post, error)); ok {
+		return rf(ctx, postID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, *model.ModeratePostReq) *model.Post); ok {
+		r0 = rf(ctx, postID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, *model.ModeratePostReq) error); ok {
+		r1 = rf(ctx, postID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishPost provides a mock function with given fields: ctx, actor, postID
+func (_m *IPostRepository) PublishPost(ctx context.Context, actor policy.Actor, postID int) (*model.Post, error) {
+	ret := _m.Called(ctx, actor, postID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishPost")
+	}
+
+	var r0 *model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int) (*model.Post, error)); ok {
+		return rf(ctx, actor, postID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int) *model.Post); ok {
+		r0 = rf(ctx, actor, postID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Actor, int) error); ok {
+		r1 = rf(ctx, actor, postID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PublishScheduledPosts provides a mock function with given fields: ctx, now
+func (_m *IPostRepository) PublishScheduledPosts(ctx context.Context, now time.Time) (int, error) {
+	ret := _m.Called(ctx, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PublishScheduledPosts")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(ctx, now)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, now)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PurgeDeletedPosts provides a mock function with given fields: ctx, deletedBefore
+func (_m *IPostRepository) PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	ret := _m.Called(ctx, deletedBefore)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PurgeDeletedPosts")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int, error)); ok {
+		return rf(ctx, deletedBefore)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int); ok {
+		r0 = rf(ctx, deletedBefore)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, deletedBefore)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestorePost provides a mock function with given fields: ctx, userID, postID, deletedAfter
+func (_m *IPostRepository) RestorePost(ctx context.Context, userID int, postID int, deletedAfter time.Time) (*model.Post, error) {
+	ret := _m.Called(ctx, userID, postID, deletedAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestorePost")
+	}
+
+	var r0 *model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, time.Time) (*model.Post, error)); ok {
+		return rf(ctx, userID, postID, deletedAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, int, time.Time) *model.Post); ok {
+		r0 = rf(ctx, userID, postID, deletedAfter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, int, time.Time) error); ok {
+		r1 = rf(ctx, userID, postID, deletedAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevertPost provides a mock function with given fields: ctx, actor, postID, revisionID
+func (_m *IPostRepository) RevertPost(ctx context.Context, actor policy.Actor, postID int, revisionID int) (*model.Post, error) {
+	ret := _m.Called(ctx, actor, postID, revisionID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevertPost")
+	}
+
+	var r0 *model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int, int) (*model.Post, error)); ok {
+		return rf(ctx, actor, postID, revisionID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int, int) *model.Post); ok {
+		r0 = rf(ctx, actor, postID, revisionID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Actor, int, int) error); ok {
+		r1 = rf(ctx, actor, postID, revisionID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePost provides a mock function with given fields: ctx, actor, postID, req
+func (_m *IPostRepository) UpdatePost(ctx context.Context, actor policy.Actor, postID int, req *model.UpdatePostReq) (*model.Post, error) {
+	ret := _m.Called(ctx, actor, postID, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for UpdatePost")
 	}
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, int, *model2.UpdatePostReq) error); ok {
-		r0 = rf(ctx, userID, postID, req)
+	var r0 *model.Post
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int, *model.UpdatePostReq) (*model.Post, error)); ok {
+		return rf(ctx, actor, postID, req)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, policy.Actor, int, *model.UpdatePostReq) *model.Post); ok {
+		r0 = rf(ctx, actor, postID, req)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Post)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, policy.Actor, int, *model.UpdatePostReq) error); ok {
+		r1 = rf(ctx, actor, postID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // NewIPostRepository creates a new instance of IPostRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -3,9 +3,14 @@ package post
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	model2 "github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
 	"strings"
+	"time"
 )
 
 //go:generate mockery --name=IPostRepository
@@ -14,8 +19,17 @@ type IPostRepository interface {
 	CreatePost(ctx context.Context, userID int, req *model2.CreatePostReq) (*model2.Post, error)
 	GetAllPostsByUserID(ctx context.Context, userID int) ([]*model2.Post, error)
 	GetPostByID(ctx context.Context, id int) (*model2.Post, error)
-	UpdatePost(ctx context.Context, userID, postID int, req *model2.UpdatePostReq) error
+	GetPosts(ctx context.Context, first, last *int, after, before *string, orderBy model2.PostOrder) (*model2.PostConnection, error)
+	UpdatePost(ctx context.Context, actor policy.Actor, postID int, req *model2.UpdatePostReq) (*model2.Post, error)
+	GetPostRevisions(ctx context.Context, postID int, first *int, after *string) (*model2.PostRevisionConnection, error)
+	RevertPost(ctx context.Context, actor policy.Actor, postID, revisionID int) (*model2.Post, error)
 	DeletePost(ctx context.Context, userID, postID int) error
+	GetDraftsByUserID(ctx context.Context, userID int) ([]*model2.Post, error)
+	PublishPost(ctx context.Context, actor policy.Actor, postID int) (*model2.Post, error)
+	PublishScheduledPosts(ctx context.Context, now time.Time) (int, error)
+	RestorePost(ctx context.Context, userID, postID int, deletedAfter time.Time) (*model2.Post, error)
+	PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time) (int, error)
+	ModeratePost(ctx context.Context, postID int, req *model2.ModeratePostReq) (*model2.Post, error)
 }
 
 type PostRepository struct {
@@ -30,50 +44,45 @@ func NewPostRepository(db *sql.DB) *PostRepository {
 
 func (r *PostRepository) CreatePost(ctx context.Context, userID int, req *model2.CreatePostReq) (*model2.Post, error) {
 	post := model2.Post{
+		UserID:        userID,
 		Title:         req.Title,
 		Body:          req.Body,
 		AllowComments: req.AllowComments,
+		Status:        req.InitialStatus(),
+		PublishAt:     utc(req.PublishAt),
 	}
 
-	row := r.db.QueryRowContext(ctx, `INSERT INTO posts (user_id, title, body, created_at, allow_comments) VALUES($1, $2, $3, current_timestamp, $4) RETURNING id;`,
-		userID, req.Title, req.Body, req.AllowComments)
+	row := r.db.QueryRowContext(ctx, `INSERT INTO posts (user_id, title, body, created_at, allow_comments, status, publish_at) 
+											VALUES($1, $2, $3, current_timestamp, $4, $5, $6) RETURNING id, created_at;`,
+		userID, req.Title, req.Body, req.AllowComments, post.Status, post.PublishAt)
 
-	err := row.Scan(&post.ID)
+	err := row.Scan(&post.ID, &post.CreatedAt)
 	if err != nil {
 		return nil, err
 	}
 
-	user := model2.User{
-		ID: userID,
-	}
-	userRow := r.db.QueryRowContext(ctx, `SELECT username FROM users WHERE id = $1;`, userID)
-	err = userRow.Scan(&user.Username)
-
-	post.User = &user
-
 	return &post, nil
 }
 
 func (r *PostRepository) GetAllPostsByUserID(ctx context.Context, userID int) ([]*model2.Post, error) {
 	var posts []*model2.Post
 
-	rows, err := r.db.QueryContext(ctx, `SELECT (p.id, p.title, p.body, p.allow_comments, p.created_at, u.id, u.username) 
-												FROM posts p INNER JOIN users u ON p.user_id = u.id 
-												WHERE p.user_id = $1 ORDER BY created_at DESC;`,
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes 
+												FROM posts WHERE user_id = $1 AND deleted_at IS NULL AND status = 'PUBLISHED' ORDER BY created_at DESC, id DESC;`,
 		userID)
 	if err != nil {
 		return nil, err
 	}
 
+	defer rows.Close()
+
 	for rows.Next() {
 		var post model2.Post
-		var user model2.User
-		err = rows.Scan(&post.ID, &post.Title, &post.Body, &post.AllowComments, &post.CreatedAt, &user.ID, &user.Username)
+		err = rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
 		if err != nil {
 			return nil, err
 		}
 
-		post.User = &user
 		posts = append(posts, &post)
 	}
 
@@ -82,64 +91,634 @@ func (r *PostRepository) GetAllPostsByUserID(ctx context.Context, userID int) ([
 
 func (r *PostRepository) GetPostByID(ctx context.Context, id int) (*model2.Post, error) {
 	var post model2.Post
-	var user model2.User
 
-	row := r.db.QueryRowContext(ctx, `SELECT (p.id, p.title, p.body, p.created_at, p.allow_comments, u.id, u.username) 
-											FROM posts p INNER JOIN users u ON p.user_id = u.id 
-											WHERE p.id = $1;`, id)
+	row := r.db.QueryRowContext(ctx, `SELECT id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes 
+											FROM posts WHERE id = $1 AND deleted_at IS NULL AND status = 'PUBLISHED';`, id)
 
-	err := row.Scan(&post.ID, &post.Title, &post.Body, &post.AllowComments, &post.CreatedAt, &user.ID, &user.Username)
+	err := row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("post not found")
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	post.User = &user
-
 	return &post, nil
 }
 
-func (r *PostRepository) UpdatePost(ctx context.Context, userID, postID int, req *model2.UpdatePostReq) error {
+// GetPosts returns one page of all posts in the given order together with the
+// total number of posts.
+func (r *PostRepository) GetPosts(ctx context.Context, first, last *int, after, before *string, orderBy model2.PostOrder) (*model2.PostConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	beforeCursor, err := cursor.DecodeOptional(before)
+	if err != nil {
+		return nil, err
+	}
+
+	sortKey := "created_at"
+	descending := true
+
+	switch orderBy {
+	case model2.PostOrderOldest:
+		descending = false
+	case model2.PostOrderMostCommented:
+		sortKey = "comment_count"
+	}
+
+	cursorValue := func(c *cursor.Cursor) interface{} {
+		if sortKey == "comment_count" {
+			return c.Count
+		}
+		return c.Time
+	}
+
 	keys := make([]string, 0)
 	values := make([]interface{}, 0)
+	arg := 1
+
+	// after points further along the order, before points back against it
+	afterOp, beforeOp := ">", "<"
+	if descending {
+		afterOp, beforeOp = "<", ">"
+	}
+
+	if afterCursor != nil {
+		keys = append(keys, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortKey, afterOp, arg, arg+1))
+		values = append(values, cursorValue(afterCursor), afterCursor.ID)
+		arg += 2
+	}
+
+	if beforeCursor != nil {
+		keys = append(keys, fmt.Sprintf("(%s, id) %s ($%d, $%d)", sortKey, beforeOp, arg, arg+1))
+		values = append(values, cursorValue(beforeCursor), beforeCursor.ID)
+		arg += 2
+	}
+
+	limit := first
+	backwards := first == nil && last != nil
+	if backwards {
+		limit = last
+	}
+
+	order := "ASC"
+	if descending != backwards {
+		order = "DESC"
+	}
+
+	query := `SELECT id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes, comment_count FROM (
+				SELECT p.id, p.user_id, p.title, p.body, p.allow_comments, p.locked, p.created_at, p.updated_at, p.status, p.publish_at, p.upvotes, p.downvotes,
+					(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL) AS comment_count
+				FROM posts p WHERE p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+				) p`
+
+	if len(keys) > 0 {
+		query += " WHERE " + strings.Join(keys, " AND ")
+	}
+
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortKey, order, order)
+
+	if limit != nil {
+		query += fmt.Sprintf(" LIMIT $%d", arg)
+		values = append(values, *limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+";", values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var edges []*model2.PostEdge
+
+	for rows.Next() {
+		var post model2.Post
+		var commentCount int
+
+		err = rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes, &commentCount)
+		if err != nil {
+			return nil, err
+		}
+
+		c := cursor.New(post.CreatedAt, post.ID)
+		if sortKey == "comment_count" {
+			c = cursor.NewCount(commentCount, post.ID)
+		}
+
+		edges = append(edges, &model2.PostEdge{
+			Cursor: c.Encode(),
+			Node:   &post,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var totalCount int
+
+	row := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM posts WHERE deleted_at IS NULL AND status = 'PUBLISHED';`)
+	err = row.Scan(&totalCount)
+	if err != nil {
+		return nil, err
+	}
+
+	return newPostConnection(edges, limit, backwards, totalCount), nil
+}
+
+// newPostConnection builds a page out of edges fetched with one row over the
+// limit. Edges fetched backwards are returned in natural order.
+func newPostConnection(edges []*model2.PostEdge, limit *int, backwards bool, totalCount int) *model2.PostConnection {
+	hasMore := limit != nil && len(edges) > *limit
+	if hasMore {
+		edges = edges[:*limit]
+	}
+
+	if backwards {
+		for i, j := 0, len(edges)-1; i < j; i, j = i+1, j-1 {
+			edges[i], edges[j] = edges[j], edges[i]
+		}
+	}
+
+	pageInfo := &model2.PageInfo{
+		HasNextPage: !backwards && hasMore,
+		HasPrevPage: backwards && hasMore,
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	if edges == nil {
+		edges = []*model2.PostEdge{}
+	}
+
+	return &model2.PostConnection{
+		Edges:      edges,
+		PageInfo:   pageInfo,
+		TotalCount: totalCount,
+	}
+}
+
+// UpdatePost updates the post of its author and returns the updated post. The
+// previous title and body are kept as a revision when the update changes
+// them. Posts that don't exist are
+// reported as NotFound errors, posts of other users and locked posts as
+// Forbidden errors.
+func (r *PostRepository) UpdatePost(ctx context.Context, actor policy.Actor, postID int, req *model2.UpdatePostReq) (*model2.Post, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	err = checkPostEditable(ctx, tx, actor, postID, req.Status != nil || req.PublishAt != nil)
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveRevision(ctx, tx, postID, req.Title, req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []string{"updated_at = NOW()"}
+	values := make([]interface{}, 0)
 
 	arg := 1
 
 	if req.Title != nil {
-		keys = append(keys, fmt.Sprintf(`title=$%d`, arg))
+		keys = append(keys, fmt.Sprintf(`title = $%d`, arg))
 		values = append(values, *req.Title)
 		arg++
 	}
 
 	if req.Body != nil {
-		keys = append(keys, fmt.Sprintf(`description=$%d`, arg))
+		keys = append(keys, fmt.Sprintf(`body = $%d`, arg))
 		values = append(values, *req.Body)
 		arg++
 	}
 
 	if req.AllowComments != nil {
-		keys = append(keys, fmt.Sprintf("allow_comments=$%d", arg))
+		keys = append(keys, fmt.Sprintf("allow_comments = $%d", arg))
 		values = append(values, *req.AllowComments)
 		arg++
 	}
 
+	if req.Status != nil {
+		keys = append(keys, fmt.Sprintf("status = $%d", arg))
+		values = append(values, *req.Status)
+		arg++
+	}
+
+	// published posts have nothing left to schedule
+	if req.PublishAt != nil || (req.Status != nil && *req.Status == model2.PostStatusPublished) {
+		keys = append(keys, fmt.Sprintf("publish_at = $%d", arg))
+		values = append(values, utc(req.PublishAt))
+		arg++
+	}
+
 	joinQuery := strings.Join(keys, ", ")
 
-	query := fmt.Sprintf(`UPDATE posts SET %s WHERE id=$%d AND user_id=$%d;`, joinQuery, arg, arg+1)
-	values = append(values, postID, userID)
+	query := fmt.Sprintf(`UPDATE posts SET %s WHERE id = $%d 
+							RETURNING id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes;`, joinQuery, arg)
+	values = append(values, postID)
+
+	var post model2.Post
 
-	_, err := r.db.ExecContext(ctx, query, values...)
+	row := tx.QueryRowContext(ctx, query, values...)
+	err = row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// GetDraftsByUserID returns the drafts of the user, including scheduled ones,
+// newest first.
+func (r *PostRepository) GetDraftsByUserID(ctx context.Context, userID int) ([]*model2.Post, error) {
+	var posts []*model2.Post
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes 
+												FROM posts WHERE user_id = $1 AND deleted_at IS NULL AND status = 'DRAFT' ORDER BY created_at DESC, id DESC;`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var post model2.Post
+		err = rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, &post)
+	}
+
+	return posts, nil
+}
+
+// PublishPost publishes a draft of its author right away, dropping its
+// schedule if it had one.
+func (r *PostRepository) PublishPost(ctx context.Context, actor policy.Actor, postID int) (*model2.Post, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	err = checkPostEditable(ctx, tx, actor, postID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var post model2.Post
+
+	row := tx.QueryRowContext(ctx, `UPDATE posts SET status = 'PUBLISHED', publish_at = NULL WHERE id = $1 AND status = 'DRAFT' 
+									RETURNING id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes;`, postID)
+	err = row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.Conflict("post is already published")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
 }
 
+// PublishScheduledPosts publishes the drafts scheduled at or before now and
+// returns how many posts were published. Drafts locked by a moderator stay
+// unpublished until they are unlocked.
+func (r *PostRepository) PublishScheduledPosts(ctx context.Context, now time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `UPDATE posts SET status = 'PUBLISHED', publish_at = NULL 
+									WHERE status = 'DRAFT' AND publish_at <= $1 AND deleted_at IS NULL AND NOT locked;`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// GetPostRevisions returns the previous versions of the post, newest first.
+func (r *PostRepository) GetPostRevisions(ctx context.Context, postID int, first *int, after *string) (*model2.PostRevisionConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, post_id, title, body, created_at FROM post_revisions WHERE post_id = $1`
+	values := []interface{}{postID}
+
+	if afterCursor != nil {
+		query += ` AND (created_at, id) < ($2, $3)`
+		values = append(values, afterCursor.Time, afterCursor.ID)
+	}
+
+	query += ` ORDER BY created_at DESC, id DESC`
+
+	if first != nil {
+		query += fmt.Sprintf(` LIMIT $%d`, len(values)+1)
+		values = append(values, *first+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+";", values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	edges := make([]*model2.PostRevisionEdge, 0)
+
+	for rows.Next() {
+		var revision model2.PostRevision
+
+		err = rows.Scan(&revision.ID, &revision.PostID, &revision.Title, &revision.Body, &revision.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, &model2.PostRevisionEdge{
+			Cursor: cursor.New(revision.CreatedAt, revision.ID).Encode(),
+			Node:   &revision,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return newPostRevisionConnection(edges, first), nil
+}
+
+// newPostRevisionConnection builds a page out of edges fetched with one row
+// over the limit.
+func newPostRevisionConnection(edges []*model2.PostRevisionEdge, first *int) *model2.PostRevisionConnection {
+	pageInfo := &model2.PageInfo{}
+
+	if first != nil && len(edges) > *first {
+		edges = edges[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model2.PostRevisionConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}
+}
+
+// RevertPost restores the title and body of one of the post revisions. The
+// version being replaced is kept as a new revision, so a revert can be undone.
+func (r *PostRepository) RevertPost(ctx context.Context, actor policy.Actor, postID, revisionID int) (*model2.Post, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	err = checkPostEditable(ctx, tx, actor, postID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var title, body string
+
+	row := tx.QueryRowContext(ctx, `SELECT title, body FROM post_revisions WHERE id = $1 AND post_id = $2;`, revisionID, postID)
+	err = row.Scan(&title, &body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("revision not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = saveRevision(ctx, tx, postID, &title, &body)
+	if err != nil {
+		return nil, err
+	}
+
+	var post model2.Post
+
+	row = tx.QueryRowContext(ctx, `UPDATE posts SET updated_at = NOW(), title = $1, body = $2 WHERE id = $3 
+									RETURNING id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes;`, title, body, postID)
+	err = row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// DeletePost marks the post of its author as deleted. Deleted posts are hidden
+// from all queries until they are restored or purged.
 func (r *PostRepository) DeletePost(ctx context.Context, userID, postID int) error {
-	_, err := r.db.ExecContext(ctx, `DELETE FROM posts WHERE user_id = $1 AND id = $2;`, userID, postID)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	err = checkPostOwner(ctx, tx, userID, postID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE posts SET deleted_at = NOW() WHERE id = $1;`, postID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RestorePost undoes the deletion of a post deleted after deletedAfter. Posts
+// deleted earlier are past their restore window and reported as not found,
+// posts removed by a moderator are locked and can't be restored.
+func (r *PostRepository) RestorePost(ctx context.Context, userID, postID int, deletedAfter time.Time) (*model2.Post, error) {
+	var post model2.Post
+
+	row := r.db.QueryRowContext(ctx, `UPDATE posts SET deleted_at = NULL 
+									WHERE id = $1 AND user_id = $2 AND deleted_at > $3 AND NOT locked 
+									RETURNING id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes;`, postID, userID, deletedAfter)
+	err := row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, r.restoreError(ctx, userID, postID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// restoreError explains why a post could not be restored.
+func (r *PostRepository) restoreError(ctx context.Context, userID, postID int) error {
+	var (
+		ownerID   int
+		isDeleted bool
+		isLocked  bool
+	)
+
+	row := r.db.QueryRowContext(ctx, `SELECT user_id, deleted_at IS NOT NULL, locked FROM posts WHERE id = $1;`, postID)
+	err := row.Scan(&ownerID, &isDeleted, &isLocked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("post not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	if ownerID != userID {
+		return apperror.Forbidden("post belongs to another user")
+	}
+
+	if !isDeleted {
+		return apperror.Conflict("post is not deleted")
+	}
+
+	if isLocked {
+		return apperror.Forbidden("post is locked")
+	}
+
+	return apperror.NotFound("post can no longer be restored")
+}
+
+// PurgeDeletedPosts permanently removes posts deleted before deletedBefore
+// together with their comments and revisions, and returns how many posts were
+// removed.
+func (r *PostRepository) PurgeDeletedPosts(ctx context.Context, deletedBefore time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM posts WHERE deleted_at <= $1;`, deletedBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
+
+// ModeratePost locks or unlocks the post and turns its comments on or off
+// regardless of who the author is.
+func (r *PostRepository) ModeratePost(ctx context.Context, postID int, req *model2.ModeratePostReq) (*model2.Post, error) {
+	var post model2.Post
+
+	row := r.db.QueryRowContext(ctx, `UPDATE posts SET locked = COALESCE($1, locked), allow_comments = COALESCE($2, allow_comments) 
+									WHERE id = $3 AND deleted_at IS NULL 
+									RETURNING id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes;`, req.IsLocked, req.AllowComments, postID)
+	err := row.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("post not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// utc converts t to UTC. Timestamp columns keep no time zone, so times are
+// stored in UTC to keep their offset from being dropped.
+func utc(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	u := t.UTC()
+
+	return &u
+}
+
+// saveRevision copies the current title and body of the post into its
+// revisions before they are overwritten. Nil title or body keep the current
+// value, and nothing is saved when neither of them changes.
+func saveRevision(ctx context.Context, tx *sql.Tx, postID int, title, body *string) error {
+	_, err := tx.ExecContext(ctx, `INSERT INTO post_revisions (post_id, title, body) 
+									SELECT id, title, body FROM posts 
+									WHERE id = $1 AND (title <> COALESCE($2, title) OR body <> COALESCE($3, body));`, postID, title, body)
+
+	return err
+}
+
+// checkPostOwner locks the post until the end of tx and makes sure it belongs
+// to the user. Deleted posts are reported as not found.
+func checkPostOwner(ctx context.Context, tx *sql.Tx, userID, postID int) error {
+	var ownerID int
+
+	row := tx.QueryRowContext(ctx, `SELECT user_id FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, postID)
+	err := row.Scan(&ownerID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("post not found")
+	}
 	if err != nil {
 		return err
 	}
 
+	if ownerID != userID {
+		return apperror.Forbidden("post belongs to another user")
+	}
+
 	return nil
 }
+
+// checkPostEditable locks the post until the end of tx and makes sure the
+// actor may edit it, changing its status if changesStatus is set. Deleted
+// posts are reported as not found.
+func checkPostEditable(ctx context.Context, tx *sql.Tx, actor policy.Actor, postID int, changesStatus bool) error {
+	var (
+		ownerID  int
+		isLocked bool
+	)
+
+	row := tx.QueryRowContext(ctx, `SELECT user_id, locked FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE;`, postID)
+	err := row.Scan(&ownerID, &isLocked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("post not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	return policy.CanEditPost(actor, ownerID, isLocked, changesStatus)
+}
@@ -5,6 +5,9 @@ import (
 	"database/sql"
 	"github.com/DATA-DOG/go-sqlmock"
 	model2 "github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"testing"
@@ -40,19 +43,39 @@ func (suite *PostRepositorySuite) TestRepository_CreatePostSuccess() {
 	}
 	userID := 1
 
-	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
-	suite.mock.ExpectQuery("INSERT INTO posts").WithArgs(userID, req.Title, req.Body, req.AllowComments).
+	rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+	suite.mock.ExpectQuery("INSERT INTO posts").WithArgs(userID, req.Title, req.Body, req.AllowComments, "PUBLISHED", nil).
 		WillReturnRows(rows)
 
-	userRows := sqlmock.NewRows([]string{"username"}).AddRow("user")
-	suite.mock.ExpectQuery("SELECT username FROM users WHERE (.+)").
-		WithArgs(userID).
-		WillReturnRows(userRows)
-
 	post, err := suite.repo.CreatePost(context.Background(), userID, req)
 
 	suite.NotNil(post)
+	suite.Equal(userID, post.UserID)
+	suite.Equal(model2.PostStatusPublished, post.Status)
 	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_CreatePostScheduled() {
+	publishAt := time.Now().Add(time.Hour).In(time.FixedZone("UTC+3", 3*60*60))
+	req := &model2.CreatePostReq{
+		Title:         "test",
+		Body:          "test",
+		AllowComments: true,
+		PublishAt:     &publishAt,
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+	suite.mock.ExpectQuery("INSERT INTO posts").WithArgs(1, req.Title, req.Body, req.AllowComments, "DRAFT", publishAt.UTC()).
+		WillReturnRows(rows)
+
+	post, err := suite.repo.CreatePost(context.Background(), 1, req)
+
+	suite.Nil(err)
+	suite.Equal(model2.PostStatusDraft, post.Status)
+	suite.True(publishAt.Equal(*post.PublishAt))
+	suite.Equal(time.UTC, post.PublishAt.Location())
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *PostRepositorySuite) TestRepository_CreatePostEmptyFields() {
@@ -61,7 +84,7 @@ func (suite *PostRepositorySuite) TestRepository_CreatePostEmptyFields() {
 	}
 	userID := 1
 
-	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+	rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
 	suite.mock.ExpectQuery("INSERT INTO posts").WithArgs(userID, req.AllowComments).
 		WillReturnRows(rows)
 
@@ -76,36 +99,33 @@ func (suite *PostRepositorySuite) TestRepository_CreatePostEmptyFields() {
 
 func (suite *PostRepositorySuite) TestRepository_GetAllPostsSuccess() {
 	userID := 1
+	createdAt := time.Now()
 
-	rows := sqlmock.NewRows([]string{"id", "title", "body", "allow_comments", "created_at", "id", "username"}).
-		AddRow(1, "1", "1", true, time.Now(), 1, "user").AddRow(2, "2", "2", false, time.Now(), 2, "user2")
-	suite.mock.ExpectQuery(`SELECT (.+) FROM posts p INNER JOIN users u ON (.+) WHERE (.+);`).
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+		AddRow(1, userID, "1", "1", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0).AddRow(2, userID, "2", "2", false, false, createdAt, nil, "PUBLISHED", nil, 0, 0)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM posts WHERE user_id = (.+) ORDER BY (.+);`).
 		WithArgs(userID).WillReturnRows(rows)
 
 	posts, err := suite.repo.GetAllPostsByUserID(context.Background(), userID)
 
 	expected := []*model2.Post{
 		{
-			ID: 1,
-			User: &model2.User{
-				ID:       1,
-				Username: "user",
-			},
+			ID:            1,
+			UserID:        userID,
 			Title:         "1",
 			Body:          "1",
-			CreatedAt:     time.Now(),
+			CreatedAt:     createdAt,
 			AllowComments: true,
+			Status:        model2.PostStatusPublished,
 		},
 		{
-			ID: 2,
-			User: &model2.User{
-				ID:       2,
-				Username: "user2",
-			},
+			ID:            2,
+			UserID:        userID,
 			Title:         "2",
 			Body:          "2",
-			CreatedAt:     time.Now(),
+			CreatedAt:     createdAt,
 			AllowComments: false,
+			Status:        model2.PostStatusPublished,
 		},
 	}
 
@@ -117,7 +137,7 @@ func (suite *PostRepositorySuite) TestRepository_GetAllPostsSuccess() {
 func (suite *PostRepositorySuite) TestRepository_GetAllPostsFailure() {
 	userID := 1
 
-	suite.mock.ExpectQuery(`SELECT (.+) FROM posts p INNER JOIN users u ON (.+) WHERE (.+);`).
+	suite.mock.ExpectQuery(`SELECT (.+) FROM posts WHERE (.+);`).
 		WithArgs(userID).WillReturnError(sql.ErrNoRows)
 
 	posts, err := suite.repo.GetAllPostsByUserID(context.Background(), userID)
@@ -130,9 +150,9 @@ func (suite *PostRepositorySuite) TestRepository_GetAllPostsFailure() {
 // =======================================================================
 
 func (suite *PostRepositorySuite) TestRepository_GetPostByIDSuccess() {
-	rows := sqlmock.NewRows([]string{"id", "title", "body", "allow_comments", "created_at", "id", "username"}).
-		AddRow(1, "1", "1", true, time.Now(), 1, "user")
-	suite.mock.ExpectQuery("SELECT (.+) FROM posts p INNER JOIN users u ON (.+) WHERE (.+);").WithArgs(1).WillReturnRows(rows)
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+		AddRow(1, 1, "1", "1", true, false, time.Now(), nil, "PUBLISHED", nil, 0, 0)
+	suite.mock.ExpectQuery("SELECT (.+) FROM posts WHERE (.+);").WithArgs(1).WillReturnRows(rows)
 
 	post, err := suite.repo.GetPostByID(context.Background(), 1)
 
@@ -141,12 +161,94 @@ func (suite *PostRepositorySuite) TestRepository_GetPostByIDSuccess() {
 }
 
 func (suite *PostRepositorySuite) TestRepository_GetPostByIDFailure() {
-	suite.mock.ExpectQuery("SELECT (.+) FROM posts p INNER JOIN users u ON (.+) WHERE (.+);").
+	suite.mock.ExpectQuery("SELECT (.+) FROM posts WHERE (.+);").
 		WithArgs(1).WillReturnError(sql.ErrNoRows)
 
 	post, err := suite.repo.GetPostByID(context.Background(), 1)
 
 	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// GetPosts
+// =======================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsNewest() {
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes", "comment_count"}).
+		AddRow(3, 1, "3", "3", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 0).
+		AddRow(2, 1, "2", "2", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 4).
+		AddRow(1, 1, "1", "1", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 1)
+	suite.mock.ExpectQuery(`FROM posts p WHERE p.deleted_at IS NULL AND p.status = 'PUBLISHED'\s+\) p ORDER BY created_at DESC, id DESC LIMIT \$1;`).
+		WithArgs(3).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts WHERE deleted_at IS NULL AND status = 'PUBLISHED';`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	first := 2
+	posts, err := suite.repo.GetPosts(context.Background(), &first, nil, nil, nil, model2.PostOrderNewest)
+
+	suite.Nil(err)
+	suite.Len(posts.Edges, 2)
+	suite.Equal(3, posts.Edges[0].Node.ID)
+	suite.Equal(2, posts.Edges[1].Node.ID)
+	suite.True(posts.PageInfo.HasNextPage)
+	suite.Equal(3, posts.TotalCount)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsMostCommentedAfter() {
+	after := cursor.NewCount(4, 2).Encode()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes", "comment_count"}).
+		AddRow(1, 1, "1", "1", true, false, time.Now(), nil, "PUBLISHED", nil, 0, 0, 1)
+	suite.mock.ExpectQuery(`WHERE \(comment_count, id\) < \(\$1, \$2\) ORDER BY comment_count DESC, id DESC LIMIT \$3;`).
+		WithArgs(4, 2, 3).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts WHERE deleted_at IS NULL AND status = 'PUBLISHED';`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	first := 2
+	posts, err := suite.repo.GetPosts(context.Background(), &first, nil, &after, nil, model2.PostOrderMostCommented)
+
+	suite.Nil(err)
+	suite.Len(posts.Edges, 1)
+	suite.False(posts.PageInfo.HasNextPage)
+
+	endCursor, err := cursor.Decode(*posts.PageInfo.EndCursor)
+	suite.Nil(err)
+	suite.Equal(1, endCursor.Count)
+	suite.Equal(1, endCursor.ID)
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsOldestLast() {
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes", "comment_count"}).
+		AddRow(3, 1, "3", "3", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 0).
+		AddRow(2, 1, "2", "2", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 0).
+		AddRow(1, 1, "1", "1", true, false, createdAt, nil, "PUBLISHED", nil, 0, 0, 0)
+	suite.mock.ExpectQuery(`ORDER BY created_at DESC, id DESC LIMIT \$1;`).
+		WithArgs(3).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`SELECT COUNT\(\*\) FROM posts WHERE deleted_at IS NULL AND status = 'PUBLISHED';`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	last := 2
+	posts, err := suite.repo.GetPosts(context.Background(), nil, &last, nil, nil, model2.PostOrderOldest)
+
+	suite.Nil(err)
+	suite.Len(posts.Edges, 2)
+	suite.Equal(2, posts.Edges[0].Node.ID)
+	suite.Equal(3, posts.Edges[1].Node.ID)
+	suite.True(posts.PageInfo.HasPrevPage)
+	suite.False(posts.PageInfo.HasNextPage)
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostsInvalidCursor() {
+	after := "invalid"
+
+	posts, err := suite.repo.GetPosts(context.Background(), nil, nil, &after, nil, model2.PostOrderNewest)
+
+	suite.Nil(posts)
 	suite.NotNil(err)
 }
 
@@ -160,57 +262,384 @@ func (suite *PostRepositorySuite) TestRepository_UpdatePostSuccess() {
 		AllowComments: boolPointer(true),
 	}
 
-	suite.mock.ExpectExec("UPDATE posts SET (.+) WHERE (.+)").
-		WithArgs("test", "test", true, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts WHERE id = \$1 AND deleted_at IS NULL FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectExec(`INSERT INTO post_revisions \(post_id, title, body\)\s+SELECT id, title, body FROM posts\s+WHERE id = \$1 AND \(title <> COALESCE\(\$2, title\) OR body <> COALESCE\(\$3, body\)\);`).
+		WithArgs(1, "test", "test").WillReturnResult(sqlmock.NewResult(1, 1))
+	suite.mock.ExpectQuery(`UPDATE posts SET updated_at = NOW\(\), title = \$1, body = \$2, allow_comments = \$3 WHERE id = \$4\s+RETURNING (.+)`).
+		WithArgs("test", "test", true, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "test", "test", true, false, time.Now(), time.Now(), "PUBLISHED", nil, 0, 0))
+	suite.mock.ExpectCommit()
 
-	err := suite.repo.UpdatePost(context.Background(), 1, 1, req)
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 1, req)
 
 	suite.Nil(err)
+	suite.Equal("test", post.Body)
+	suite.NotNil(post.UpdatedAt)
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *PostRepositorySuite) TestRepository_UpdatePostWithoutSomeFields() {
 	req := &model2.UpdatePostReq{
-		AllowComments: boolPointer(true),
+		AllowComments: boolPointer(false),
 	}
 
-	suite.mock.ExpectExec("UPDATE posts SET (.+) WHERE (.+)").
-		WithArgs(true, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectExec(`INSERT INTO post_revisions`).
+		WithArgs(1, nil, nil).WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`UPDATE posts SET updated_at = NOW\(\), allow_comments = \$1 WHERE id = \$2`).
+		WithArgs(false, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "title", "body", false, false, time.Now(), time.Now(), "PUBLISHED", nil, 0, 0))
+	suite.mock.ExpectCommit()
+
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 1, req)
+
+	suite.Nil(err)
+	suite.Equal("title", post.Title)
+	suite.False(post.AllowComments)
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 1, &model2.UpdatePostReq{Title: strPointer("test")})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostForbidden() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(2, false))
+	suite.mock.ExpectRollback()
+
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 1, &model2.UpdatePostReq{Title: strPointer("test")})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostLocked() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, true))
+	suite.mock.ExpectRollback()
 
-	err := suite.repo.UpdatePost(context.Background(), 1, 1, req)
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1}, 1, &model2.UpdatePostReq{Title: strPointer("test")})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_UpdatePostLockedStatusByModerator() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, true))
+	suite.mock.ExpectRollback()
+
+	published := model2.PostStatusPublished
+	post, err := suite.repo.UpdatePost(context.Background(), policy.Actor{UserID: 1, Role: model2.RoleModerator}, 1, &model2.UpdatePostReq{Status: &published})
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// ModeratePost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_ModeratePostSuccess() {
+	suite.mock.ExpectQuery(`UPDATE posts SET locked = COALESCE\(\$1, locked\), allow_comments = COALESCE\(\$2, allow_comments\)\s+WHERE id = \$3 AND deleted_at IS NULL`).
+		WithArgs(true, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 2, "title", "body", true, true, time.Now(), nil, "PUBLISHED", nil, 0, 0))
+
+	post, err := suite.repo.ModeratePost(context.Background(), 1, &model2.ModeratePostReq{IsLocked: boolPointer(true)})
 
 	suite.Nil(err)
+	suite.True(post.IsLocked)
+	suite.Equal(2, post.UserID)
 }
 
-func (suite *PostRepositorySuite) TestRepository_UpdatePostWithoutAllFields() {
-	req := &model2.UpdatePostReq{}
+func (suite *PostRepositorySuite) TestRepository_ModeratePostNotFound() {
+	suite.mock.ExpectQuery(`UPDATE posts SET locked (.+)`).
+		WithArgs(nil, false, 1).WillReturnError(sql.ErrNoRows)
 
-	suite.mock.ExpectExec("UPDATE posts SET WHERE (.+)").
-		WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	post, err := suite.repo.ModeratePost(context.Background(), 1, &model2.ModeratePostReq{AllowComments: boolPointer(false)})
 
-	err := suite.repo.UpdatePost(context.Background(), 1, 1, req)
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// Drafts
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetDraftsByUserID() {
+	rows := sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+		AddRow(1, 1, "1", "1", true, false, time.Now(), nil, "DRAFT", nil, 0, 0)
+	suite.mock.ExpectQuery(`FROM posts WHERE user_id = \$1 AND deleted_at IS NULL AND status = 'DRAFT'`).
+		WithArgs(1).WillReturnRows(rows)
+
+	posts, err := suite.repo.GetDraftsByUserID(context.Background(), 1)
 
 	suite.Nil(err)
+	suite.Len(posts, 1)
+	suite.Equal(model2.PostStatusDraft, posts[0].Status)
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishPostSuccess() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectQuery(`UPDATE posts SET status = 'PUBLISHED', publish_at = NULL WHERE id = \$1 AND status = 'DRAFT'`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "test", "test", true, false, time.Now(), nil, "PUBLISHED", nil, 0, 0))
+	suite.mock.ExpectCommit()
+
+	post, err := suite.repo.PublishPost(context.Background(), policy.Actor{UserID: 1}, 1)
+
+	suite.Nil(err)
+	suite.Equal(model2.PostStatusPublished, post.Status)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishPostAlreadyPublished() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectQuery(`UPDATE posts SET status = 'PUBLISHED'`).
+		WithArgs(1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	post, err := suite.repo.PublishPost(context.Background(), policy.Actor{UserID: 1}, 1)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_PublishScheduledPosts() {
+	now := time.Now()
+
+	suite.mock.ExpectExec(`UPDATE posts SET status = 'PUBLISHED', publish_at = NULL\s+WHERE status = 'DRAFT' AND publish_at <= \$1 AND deleted_at IS NULL AND NOT locked;`).
+		WithArgs(now.UTC()).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	count, err := suite.repo.PublishScheduledPosts(context.Background(), now)
+
+	suite.Nil(err)
+	suite.Equal(3, count)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// GetPostRevisions
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_GetPostRevisionsSuccess() {
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "post_id", "title", "body", "created_at"}).
+		AddRow(3, 1, "3", "3", createdAt).
+		AddRow(2, 1, "2", "2", createdAt)
+	suite.mock.ExpectQuery(`SELECT id, post_id, title, body, created_at FROM post_revisions WHERE post_id = \$1 ORDER BY created_at DESC, id DESC LIMIT \$2;`).
+		WithArgs(1, 2).WillReturnRows(rows)
+
+	first := 1
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), 1, &first, nil)
+
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 1)
+	suite.Equal(3, revisions.Edges[0].Node.ID)
+	suite.True(revisions.PageInfo.HasNextPage)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_GetPostRevisionsAfter() {
+	createdAt := time.Now()
+	after := cursor.New(createdAt, 3).Encode()
+
+	rows := sqlmock.NewRows([]string{"id", "post_id", "title", "body", "created_at"}).
+		AddRow(2, 1, "2", "2", createdAt)
+	suite.mock.ExpectQuery(`WHERE post_id = \$1 AND \(created_at, id\) < \(\$2, \$3\) ORDER BY created_at DESC, id DESC;`).
+		WithArgs(1, sqlmock.AnyArg(), 3).WillReturnRows(rows)
+
+	revisions, err := suite.repo.GetPostRevisions(context.Background(), 1, nil, &after)
+
+	suite.Nil(err)
+	suite.Len(revisions.Edges, 1)
+	suite.False(revisions.PageInfo.HasNextPage)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// RevertPost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_RevertPostSuccess() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectQuery(`SELECT title, body FROM post_revisions WHERE id = \$1 AND post_id = \$2;`).
+		WithArgs(5, 1).WillReturnRows(sqlmock.NewRows([]string{"title", "body"}).AddRow("old", "old"))
+	suite.mock.ExpectExec(`INSERT INTO post_revisions`).
+		WithArgs(1, "old", "old").WillReturnResult(sqlmock.NewResult(6, 1))
+	suite.mock.ExpectQuery(`UPDATE posts SET updated_at = NOW\(\), title = \$1, body = \$2 WHERE id = \$3`).
+		WithArgs("old", "old", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "old", "old", true, false, time.Now(), time.Now(), "PUBLISHED", nil, 0, 0))
+	suite.mock.ExpectCommit()
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, 1, 5)
+
+	suite.Nil(err)
+	suite.Equal("old", post.Title)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_RevertPostRevisionNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(1, false))
+	suite.mock.ExpectQuery(`SELECT title, body FROM post_revisions (.+);`).
+		WithArgs(5, 1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectRollback()
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, 1, 5)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_RevertPostForbidden() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id, locked FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id", "locked"}).AddRow(2, false))
+	suite.mock.ExpectRollback()
+
+	post, err := suite.repo.RevertPost(context.Background(), policy.Actor{UserID: 1}, 1, 5)
+
+	suite.Nil(post)
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
 // DeletePost
 // ====================================================================================
 
 func (suite *PostRepositorySuite) TestRepository_DeletePostSuccess() {
-	suite.mock.ExpectExec("DELETE FROM posts WHERE (.+)").
-		WithArgs(1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	suite.mock.ExpectExec(`UPDATE posts SET deleted_at = NOW\(\) WHERE id = \$1;`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
 
 	err := suite.repo.DeletePost(context.Background(), 1, 1)
 
 	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_DeletePostForbidden() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(2))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.DeletePost(context.Background(), 1, 1)
+
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
 func (suite *PostRepositorySuite) TestRepository_DeletePostFailure() {
-	suite.mock.ExpectExec("DELETE FROM posts WHERE (.+)").
-		WithArgs(1, 1).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT user_id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	suite.mock.ExpectExec(`UPDATE posts SET deleted_at = NOW\(\) WHERE id = \$1;`).
+		WithArgs(1).WillReturnError(sql.ErrConnDone)
+	suite.mock.ExpectRollback()
 
 	err := suite.repo.DeletePost(context.Background(), 1, 1)
 
 	suite.NotNil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// RestorePost
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_RestorePostSuccess() {
+	deletedAfter := time.Now().Add(-time.Hour)
+
+	suite.mock.ExpectQuery(`UPDATE posts SET deleted_at = NULL\s+WHERE id = \$1 AND user_id = \$2 AND deleted_at > \$3 AND NOT locked`).
+		WithArgs(1, 1, deletedAfter).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "test", "test", true, false, time.Now(), nil, "PUBLISHED", nil, 0, 0))
+
+	post, err := suite.repo.RestorePost(context.Background(), 1, 1, deletedAfter)
+
+	suite.Nil(err)
+	suite.Equal(1, post.ID)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *PostRepositorySuite) TestRepository_RestorePostErrors() {
+	deletedAfter := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name string
+		rows *sqlmock.Rows
+		code apperror.Code
+	}{
+		{"not found", sqlmock.NewRows([]string{"user_id", "is_deleted", "locked"}), apperror.CodeNotFound},
+		{"another user", sqlmock.NewRows([]string{"user_id", "is_deleted", "locked"}).AddRow(2, true, false), apperror.CodeForbidden},
+		{"not deleted", sqlmock.NewRows([]string{"user_id", "is_deleted", "locked"}).AddRow(1, false, false), apperror.CodeConflict},
+		{"locked", sqlmock.NewRows([]string{"user_id", "is_deleted", "locked"}).AddRow(1, true, true), apperror.CodeForbidden},
+		{"window passed", sqlmock.NewRows([]string{"user_id", "is_deleted", "locked"}).AddRow(1, true, false), apperror.CodeNotFound},
+	}
+
+	for _, test := range tests {
+		suite.mock.ExpectQuery(`UPDATE posts SET deleted_at = NULL`).
+			WithArgs(1, 1, deletedAfter).WillReturnError(sql.ErrNoRows)
+		suite.mock.ExpectQuery(`SELECT user_id, deleted_at IS NOT NULL, locked FROM posts WHERE id = \$1;`).
+			WithArgs(1).WillReturnRows(test.rows)
+
+		post, err := suite.repo.RestorePost(context.Background(), 1, 1, deletedAfter)
+
+		suite.Nil(post, test.name)
+		suite.Equal(test.code, apperror.CodeOf(err), test.name)
+	}
+
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// PurgeDeletedPosts
+// ====================================================================================
+
+func (suite *PostRepositorySuite) TestRepository_PurgeDeletedPosts() {
+	deletedBefore := time.Now().Add(-time.Hour)
+
+	suite.mock.ExpectExec(`DELETE FROM posts WHERE deleted_at <= \$1;`).
+		WithArgs(deletedBefore).WillReturnResult(sqlmock.NewResult(0, 2))
+
+	count, err := suite.repo.PurgeDeletedPosts(context.Background(), deletedBefore)
+
+	suite.Nil(err)
+	suite.Equal(2, count)
+	suite.Nil(suite.mock.ExpectationsWereMet())
 }
 
 func strPointer(s string) *string {
@@ -0,0 +1,105 @@
+package reaction
+
+import (
+	"context"
+	"database/sql"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+)
+
+//go:generate mockery --name=IReactionRepository
+
+type IReactionRepository interface {
+	AddReaction(ctx context.Context, userID, commentID int, emoji string) error
+	RemoveReaction(ctx context.Context, userID, commentID int, emoji string) error
+	GetReactions(ctx context.Context, userID int, commentIDs []int) (map[int][]*model.ReactionSummary, error)
+}
+
+type ReactionRepository struct {
+	db *sql.DB
+}
+
+func NewReactionRepository(db *sql.DB) *ReactionRepository {
+	return &ReactionRepository{
+		db: db,
+	}
+}
+
+// AddReaction reacts to the comment with the emoji. Adding the same reaction
+// twice has no effect.
+func (r *ReactionRepository) AddReaction(ctx context.Context, userID, commentID int, emoji string) error {
+	res, err := r.db.ExecContext(ctx, `INSERT INTO reactions (comment_id, user_id, emoji) 
+						SELECT id, $2, $3 FROM comments WHERE id = $1 AND deleted_at IS NULL 
+						ON CONFLICT DO NOTHING;`, commentID, userID, emoji)
+	if err != nil {
+		return err
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return nil
+	}
+
+	// nothing was inserted either because the reaction already exists or
+	// because the comment doesn't
+	var exists bool
+
+	row := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM comments WHERE id = $1 AND deleted_at IS NULL);`, commentID)
+	err = row.Scan(&exists)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return apperror.NotFound("comment not found")
+	}
+
+	return nil
+}
+
+// RemoveReaction takes back the reaction of the user. Removing a reaction
+// that doesn't exist has no effect.
+func (r *ReactionRepository) RemoveReaction(ctx context.Context, userID, commentID int, emoji string) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM reactions WHERE comment_id = $1 AND user_id = $2 AND emoji = $3;`, commentID, userID, emoji)
+
+	return err
+}
+
+// GetReactions summarizes the reactions to every comment in commentIDs, in
+// the order each emoji was first used. Reacted is set for the reactions of
+// the user, pass 0 for anonymous viewers.
+func (r *ReactionRepository) GetReactions(ctx context.Context, userID int, commentIDs []int) (map[int][]*model.ReactionSummary, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT comment_id, emoji, COUNT(*), BOOL_OR(user_id = $2) FROM reactions 
+						WHERE comment_id = ANY($1) 
+						GROUP BY comment_id, emoji 
+						ORDER BY comment_id, MIN(created_at), emoji;`, commentIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	reactions := make(map[int][]*model.ReactionSummary, len(commentIDs))
+
+	for rows.Next() {
+		var commentID int
+		var summary model.ReactionSummary
+
+		err = rows.Scan(&commentID, &summary.Emoji, &summary.Count, &summary.Reacted)
+		if err != nil {
+			return nil, err
+		}
+
+		reactions[commentID] = append(reactions[commentID], &summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
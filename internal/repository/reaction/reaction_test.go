@@ -0,0 +1,99 @@
+package reaction
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/repotest"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type ReactionRepositorySuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *ReactionRepository
+}
+
+func (suite *ReactionRepositorySuite) SetupTest() {
+	var err error
+	suite.db, suite.mock, err = sqlmock.New(sqlmock.ValueConverterOption(repotest.SliceConverter{}))
+	assert.NoError(suite.T(), err)
+	suite.repo = NewReactionRepository(suite.db)
+}
+
+func TestReactionRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReactionRepositorySuite))
+}
+
+// AddReaction
+// ====================================================================================
+
+func (suite *ReactionRepositorySuite) TestRepository_AddReactionSuccess() {
+	suite.mock.ExpectExec(`INSERT INTO reactions \(comment_id, user_id, emoji\)\s+SELECT id, \$2, \$3 FROM comments WHERE id = \$1 AND deleted_at IS NULL\s+ON CONFLICT DO NOTHING;`).
+		WithArgs(1, 2, "👍").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.AddReaction(context.Background(), 2, 1, "👍")
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReactionRepositorySuite) TestRepository_AddReactionTwice() {
+	suite.mock.ExpectExec(`INSERT INTO reactions`).
+		WithArgs(1, 2, "👍").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM comments WHERE id = \$1 AND deleted_at IS NULL\);`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	err := suite.repo.AddReaction(context.Background(), 2, 1, "👍")
+
+	suite.Nil(err)
+}
+
+func (suite *ReactionRepositorySuite) TestRepository_AddReactionCommentNotFound() {
+	suite.mock.ExpectExec(`INSERT INTO reactions`).
+		WithArgs(1, 2, "👍").WillReturnResult(sqlmock.NewResult(0, 0))
+	suite.mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	err := suite.repo.AddReaction(context.Background(), 2, 1, "👍")
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// RemoveReaction
+// ====================================================================================
+
+func (suite *ReactionRepositorySuite) TestRepository_RemoveReaction() {
+	suite.mock.ExpectExec(`DELETE FROM reactions WHERE comment_id = \$1 AND user_id = \$2 AND emoji = \$3;`).
+		WithArgs(1, 2, "👍").WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := suite.repo.RemoveReaction(context.Background(), 2, 1, "👍")
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// GetReactions
+// ====================================================================================
+
+func (suite *ReactionRepositorySuite) TestRepository_GetReactions() {
+	rows := sqlmock.NewRows([]string{"comment_id", "emoji", "count", "reacted"}).
+		AddRow(1, "🎉", 3, true).
+		AddRow(1, "👍", 1, false).
+		AddRow(2, "👍", 2, false)
+
+	suite.mock.ExpectQuery(`SELECT comment_id, emoji, COUNT\(\*\), BOOL_OR\(user_id = \$2\) FROM reactions\s+WHERE comment_id = ANY\(\$1\)\s+GROUP BY comment_id, emoji\s+ORDER BY comment_id, MIN\(created_at\), emoji;`).
+		WithArgs([]int{1, 2, 3}, 5).WillReturnRows(rows)
+
+	reactions, err := suite.repo.GetReactions(context.Background(), 5, []int{1, 2, 3})
+
+	suite.Nil(err)
+	suite.Equal([]*model.ReactionSummary{{Emoji: "🎉", Count: 3, Reacted: true}, {Emoji: "👍", Count: 1}}, reactions[1])
+	suite.Len(reactions[2], 1)
+	suite.Empty(reactions[3])
+}
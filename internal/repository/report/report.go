@@ -0,0 +1,278 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+//go:generate mockery --name=IReportRepository
+
+type IReportRepository interface {
+	ReportPost(ctx context.Context, userID, postID int, reason model.ReportReason, note *string) (*model.Report, error)
+	ReportComment(ctx context.Context, userID, commentID int, reason model.ReportReason, note *string) (*model.Report, error)
+	GetReports(ctx context.Context, status model.ReportStatus, first *int, after *string) (*model.ReportConnection, error)
+	ResolveReport(ctx context.Context, actor policy.Actor, reportID int, action model.ReportAction) (*model.Report, error)
+}
+
+const uniqueViolation = "23505"
+
+type ReportRepository struct {
+	db *sql.DB
+}
+
+func NewReportRepository(db *sql.DB) *ReportRepository {
+	return &ReportRepository{
+		db: db,
+	}
+}
+
+// ReportPost files a report of the user against a published post. A user
+// can have one open report per post.
+func (r *ReportRepository) ReportPost(ctx context.Context, userID, postID int, reason model.ReportReason, note *string) (*model.Report, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO reports (reporter_id, post_id, reason, note) 
+						SELECT $1, id, $3, $4 FROM posts WHERE id = $2 AND deleted_at IS NULL AND status = 'PUBLISHED' 
+						RETURNING id, reporter_id, post_id, comment_id, reason, note, status, action, resolved_by, resolved_at, created_at;`,
+		userID, postID, reason, note)
+
+	return scanNewReport(row, "post not found")
+}
+
+// ReportComment files a report of the user against a comment. A user can
+// have one open report per comment.
+func (r *ReportRepository) ReportComment(ctx context.Context, userID, commentID int, reason model.ReportReason, note *string) (*model.Report, error) {
+	row := r.db.QueryRowContext(ctx, `INSERT INTO reports (reporter_id, comment_id, reason, note) 
+						SELECT $1, id, $3, $4 FROM comments WHERE id = $2 AND deleted_at IS NULL 
+						RETURNING id, reporter_id, post_id, comment_id, reason, note, status, action, resolved_by, resolved_at, created_at;`,
+		userID, commentID, reason, note)
+
+	return scanNewReport(row, "comment not found")
+}
+
+// scanNewReport reads a report inserted from the reported row. No row means
+// the reported content doesn't exist.
+func scanNewReport(row *sql.Row, notFound string) (*model.Report, error) {
+	report, err := scanReport(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound(notFound)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, apperror.Conflict("content is already reported")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// GetReports returns the reports with the status, oldest first, so the
+// moderation queue is worked through in the order reports came in.
+func (r *ReportRepository) GetReports(ctx context.Context, status model.ReportStatus, first *int, after *string) (*model.ReportConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `SELECT id, reporter_id, post_id, comment_id, reason, note, status, action, resolved_by, resolved_at, created_at FROM reports WHERE status = $1`
+	values := []interface{}{status}
+
+	if afterCursor != nil {
+		query += ` AND (created_at, id) > ($2, $3)`
+		values = append(values, afterCursor.Time, afterCursor.ID)
+	}
+
+	query += ` ORDER BY created_at, id`
+
+	if first != nil {
+		query += fmt.Sprintf(` LIMIT $%d`, len(values)+1)
+		values = append(values, *first+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query+";", values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	edges := make([]*model.ReportEdge, 0)
+
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, &model.ReportEdge{
+			Cursor: cursor.New(report.CreatedAt, report.ID).Encode(),
+			Node:   report,
+		})
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(edges) > *first {
+		edges = edges[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.ReportConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+// ResolveReport applies the action to the reported content and records who
+// resolved the report. Every open report of the same content is resolved
+// with it, so the content leaves the queue at once. BAN_AUTHOR is reported as
+// a Forbidden error when the actor may not ban the author.
+func (r *ReportRepository) ResolveReport(ctx context.Context, actor policy.Actor, reportID int, action model.ReportAction) (*model.Report, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback()
+
+	var (
+		postID    *int
+		commentID *int
+		reason    model.ReportReason
+		status    model.ReportStatus
+	)
+
+	row := tx.QueryRowContext(ctx, `SELECT post_id, comment_id, reason, status FROM reports WHERE id = $1 FOR UPDATE;`, reportID)
+	err = row.Scan(&postID, &commentID, &reason, &status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("report not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if status != model.ReportStatusOpen {
+		return nil, apperror.Conflict("report is already resolved")
+	}
+
+	// reports are resolved before the action is applied, because deleting a
+	// comment detaches its reports
+	_, err = tx.ExecContext(ctx, `UPDATE reports SET status = 'RESOLVED', action = $1, resolved_by = $2, resolved_at = NOW() 
+						WHERE id = $3 OR (status = 'OPEN' AND (post_id = $4 OR comment_id = $5));`, action, actor.UserID, reportID, postID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	if action != model.ReportActionDismiss {
+		err = applyAction(ctx, tx, actor, action, postID, commentID, reason)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	row = tx.QueryRowContext(ctx, `SELECT id, reporter_id, post_id, comment_id, reason, note, status, action, resolved_by, resolved_at, created_at FROM reports WHERE id = $1;`, reportID)
+	report, err := scanReport(row)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// applyAction takes the reported content away. HIDE turns a post back into a
+// locked draft and marks a comment deleted, DELETE marks a post deleted and
+// locked so its author can't restore it, and removes a comment together with
+// its replies. BAN_AUTHOR hides the content and bans its author, if the actor
+// outranks them.
+func applyAction(ctx context.Context, tx *sql.Tx, actor policy.Actor, action model.ReportAction, postID, commentID *int, reason model.ReportReason) error {
+	var row *sql.Row
+
+	switch {
+	case postID != nil && action == model.ReportActionDelete:
+		row = tx.QueryRowContext(ctx, `UPDATE posts SET deleted_at = COALESCE(deleted_at, NOW()), locked = TRUE WHERE id = $1 RETURNING user_id;`, *postID)
+	case postID != nil:
+		row = tx.QueryRowContext(ctx, `UPDATE posts SET status = 'DRAFT', publish_at = NULL, locked = TRUE WHERE id = $1 RETURNING user_id;`, *postID)
+	case commentID != nil && action == model.ReportActionDelete:
+		row = tx.QueryRowContext(ctx, `DELETE FROM comments WHERE id = $1 RETURNING user_id;`, *commentID)
+	case commentID != nil:
+		row = tx.QueryRowContext(ctx, `UPDATE comments SET deleted_at = COALESCE(deleted_at, NOW()) WHERE id = $1 RETURNING user_id;`, *commentID)
+	default:
+		return apperror.NotFound("reported content no longer exists")
+	}
+
+	var authorID int
+
+	err := row.Scan(&authorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("reported content no longer exists")
+	}
+	if err != nil {
+		return err
+	}
+
+	if action != model.ReportActionBanAuthor {
+		return nil
+	}
+
+	var authorRole model.Role
+
+	row = tx.QueryRowContext(ctx, `SELECT role FROM users WHERE id = $1 FOR UPDATE;`, authorID)
+	err = row.Scan(&authorRole)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound("author not found")
+	}
+	if err != nil {
+		return err
+	}
+
+	err = policy.CanBanAuthor(actor, authorID, authorRole)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE users SET banned_at = NOW(), banned_until = NULL, ban_reason = $2 WHERE id = $1;`, authorID, fmt.Sprintf("reported for %s", reason))
+	if err != nil {
+		return err
+	}
+
+	// access tokens of the author must stop working right away
+	_, err = tx.ExecContext(ctx, `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`, authorID)
+
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanReport(row scanner) (*model.Report, error) {
+	var report model.Report
+
+	err := row.Scan(&report.ID, &report.ReporterID, &report.PostID, &report.CommentID, &report.Reason, &report.Note, &report.Status, &report.Action, &report.ResolvedByID, &report.ResolvedAt, &report.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
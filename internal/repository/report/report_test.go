@@ -0,0 +1,253 @@
+package report
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/policy"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type ReportRepositorySuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *ReportRepository
+}
+
+func (suite *ReportRepositorySuite) SetupTest() {
+	var err error
+	suite.db, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.repo = NewReportRepository(suite.db)
+}
+
+func TestReportRepositorySuite(t *testing.T) {
+	suite.Run(t, new(ReportRepositorySuite))
+}
+
+var moderator = policy.Actor{UserID: 9, Role: model.RoleModerator}
+
+var reportColumns = []string{"id", "reporter_id", "post_id", "comment_id", "reason", "note", "status", "action", "resolved_by", "resolved_at", "created_at"}
+
+// ReportPost
+// ====================================================================================
+
+func (suite *ReportRepositorySuite) TestRepository_ReportPostSuccess() {
+	createdAt := time.Now()
+	note := "buy followers"
+
+	suite.mock.ExpectQuery(`INSERT INTO reports \(reporter_id, post_id, reason, note\)\s+SELECT \$1, id, \$3, \$4 FROM posts WHERE id = \$2 AND deleted_at IS NULL AND status = 'PUBLISHED'`).
+		WithArgs(2, 1, model.ReportReasonSpam, &note).
+		WillReturnRows(sqlmock.NewRows(reportColumns).AddRow(1, 2, 1, nil, "SPAM", note, "OPEN", nil, nil, nil, createdAt))
+
+	report, err := suite.repo.ReportPost(context.Background(), 2, 1, model.ReportReasonSpam, &note)
+
+	postID := 1
+	suite.Nil(err)
+	suite.Equal(&model.Report{ID: 1, ReporterID: 2, PostID: &postID, Reason: model.ReportReasonSpam, Note: &note, Status: model.ReportStatusOpen, CreatedAt: createdAt}, report)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ReportPostNotFound() {
+	suite.mock.ExpectQuery(`INSERT INTO reports`).
+		WithArgs(2, 1, model.ReportReasonSpam, nil).
+		WillReturnRows(sqlmock.NewRows(reportColumns))
+
+	_, err := suite.repo.ReportPost(context.Background(), 2, 1, model.ReportReasonSpam, nil)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ReportPostTwice() {
+	suite.mock.ExpectQuery(`INSERT INTO reports`).
+		WithArgs(2, 1, model.ReportReasonSpam, nil).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	_, err := suite.repo.ReportPost(context.Background(), 2, 1, model.ReportReasonSpam, nil)
+
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+}
+
+// ReportComment
+// ====================================================================================
+
+func (suite *ReportRepositorySuite) TestRepository_ReportCommentSuccess() {
+	suite.mock.ExpectQuery(`INSERT INTO reports \(reporter_id, comment_id, reason, note\)\s+SELECT \$1, id, \$3, \$4 FROM comments WHERE id = \$2 AND deleted_at IS NULL`).
+		WithArgs(2, 3, model.ReportReasonHarassment, nil).
+		WillReturnRows(sqlmock.NewRows(reportColumns).AddRow(1, 2, nil, 3, "HARASSMENT", nil, "OPEN", nil, nil, nil, time.Now()))
+
+	report, err := suite.repo.ReportComment(context.Background(), 2, 3, model.ReportReasonHarassment, nil)
+
+	suite.Nil(err)
+	suite.Nil(report.PostID)
+	suite.Equal(3, *report.CommentID)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// GetReports
+// ====================================================================================
+
+func (suite *ReportRepositorySuite) TestRepository_GetReports() {
+	createdAt := time.Now()
+	after := cursor.New(createdAt, 1).Encode()
+	first := 1
+
+	rows := sqlmock.NewRows(reportColumns).
+		AddRow(2, 5, 1, nil, "SPAM", nil, "OPEN", nil, nil, nil, createdAt).
+		AddRow(3, 6, nil, 4, "OTHER", nil, "OPEN", nil, nil, nil, createdAt)
+
+	suite.mock.ExpectQuery(`SELECT (.+) FROM reports WHERE status = \$1 AND \(created_at, id\) > \(\$2, \$3\) ORDER BY created_at, id LIMIT \$4;`).
+		WithArgs(model.ReportStatusOpen, sqlmock.AnyArg(), 1, 2).WillReturnRows(rows)
+
+	reports, err := suite.repo.GetReports(context.Background(), model.ReportStatusOpen, &first, &after)
+
+	suite.Nil(err)
+	suite.Len(reports.Edges, 1)
+	suite.Equal(2, reports.Edges[0].Node.ID)
+	suite.True(reports.PageInfo.HasNextPage)
+	suite.Equal(cursor.New(createdAt, 2).Encode(), *reports.PageInfo.EndCursor)
+}
+
+func (suite *ReportRepositorySuite) TestRepository_GetReportsInvalidCursor() {
+	after := "invalid"
+
+	_, err := suite.repo.GetReports(context.Background(), model.ReportStatusOpen, nil, &after)
+
+	suite.Equal(apperror.CodeValidation, apperror.CodeOf(err))
+}
+
+// ResolveReport
+// ====================================================================================
+
+func (suite *ReportRepositorySuite) expectReport(postID, commentID interface{}, status string) {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT post_id, comment_id, reason, status FROM reports WHERE id = \$1 FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"post_id", "comment_id", "reason", "status"}).AddRow(postID, commentID, "SPAM", status))
+}
+
+func (suite *ReportRepositorySuite) expectResolved(action string, postID, commentID interface{}) {
+	suite.mock.ExpectQuery(`SELECT (.+) FROM reports WHERE id = \$1;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows(reportColumns).AddRow(1, 2, postID, commentID, "SPAM", nil, "RESOLVED", action, 9, time.Now(), time.Now()))
+	suite.mock.ExpectCommit()
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportDismiss() {
+	suite.expectReport(1, nil, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports SET status = 'RESOLVED', action = \$1, resolved_by = \$2, resolved_at = NOW\(\)\s+WHERE id = \$3 OR \(status = 'OPEN' AND \(post_id = \$4 OR comment_id = \$5\)\);`).
+		WithArgs(model.ReportActionDismiss, 9, 1, 1, nil).WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.expectResolved("DISMISS", 1, nil)
+
+	report, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionDismiss)
+
+	suite.Nil(err)
+	suite.Equal(model.ReportStatusResolved, report.Status)
+	suite.Equal(model.ReportActionDismiss, *report.Action)
+	suite.Equal(9, *report.ResolvedByID)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportHidePost() {
+	suite.expectReport(1, nil, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports`).
+		WithArgs(model.ReportActionHide, 9, 1, 1, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`UPDATE posts SET status = 'DRAFT', publish_at = NULL, locked = TRUE WHERE id = \$1 RETURNING user_id;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(4))
+	suite.expectResolved("HIDE", 1, nil)
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionHide)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportDeleteComment() {
+	suite.expectReport(nil, 3, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports`).
+		WithArgs(model.ReportActionDelete, 9, 1, nil, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`DELETE FROM comments WHERE id = \$1 RETURNING user_id;`).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(4))
+	suite.expectResolved("DELETE", nil, nil)
+
+	report, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionDelete)
+
+	suite.Nil(err)
+	suite.Nil(report.CommentID)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportBanAuthor() {
+	suite.expectReport(nil, 3, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports`).
+		WithArgs(model.ReportActionBanAuthor, 9, 1, nil, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`UPDATE comments SET deleted_at = COALESCE\(deleted_at, NOW\(\)\) WHERE id = \$1 RETURNING user_id;`).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(4))
+	suite.mock.ExpectQuery(`SELECT role FROM users WHERE id = \$1 FOR UPDATE;`).
+		WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("USER"))
+	suite.mock.ExpectExec(`UPDATE users SET banned_at = NOW\(\), banned_until = NULL, ban_reason = \$2 WHERE id = \$1;`).
+		WithArgs(4, "reported for SPAM").WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`UPDATE sessions SET revoked_at = NOW\(\) WHERE user_id = \$1 AND revoked_at IS NULL;`).
+		WithArgs(4).WillReturnResult(sqlmock.NewResult(0, 2))
+	suite.expectResolved("BAN_AUTHOR", nil, 3)
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionBanAuthor)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportBanAuthorOutranked() {
+	suite.expectReport(1, nil, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports`).
+		WithArgs(model.ReportActionBanAuthor, 9, 1, 1, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`UPDATE posts SET status = 'DRAFT'(.+) RETURNING user_id;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(4))
+	suite.mock.ExpectQuery(`SELECT role FROM users WHERE id = \$1 FOR UPDATE;`).
+		WithArgs(4).WillReturnRows(sqlmock.NewRows([]string{"role"}).AddRow("ADMIN"))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionBanAuthor)
+
+	suite.Equal(apperror.CodeForbidden, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportContentGone() {
+	suite.expectReport(nil, nil, "OPEN")
+	suite.mock.ExpectExec(`UPDATE reports`).
+		WithArgs(model.ReportActionHide, 9, 1, nil, nil).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionHide)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportAlreadyResolved() {
+	suite.expectReport(1, nil, "RESOLVED")
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionHide)
+
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *ReportRepositorySuite) TestRepository_ResolveReportNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT post_id, comment_id, reason, status FROM reports`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"post_id", "comment_id", "reason", "status"}))
+	suite.mock.ExpectRollback()
+
+	_, err := suite.repo.ResolveReport(context.Background(), moderator, 1, model.ReportActionHide)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
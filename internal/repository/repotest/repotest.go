@@ -0,0 +1,29 @@
+// Package repotest holds helpers shared by the sqlmock based repository
+// tests.
+package repotest
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"time"
+)
+
+// SliceConverter passes slices through like pgx does for array parameters.
+type SliceConverter struct{}
+
+func (SliceConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	if v != nil && reflect.TypeOf(v).Kind() == reflect.Slice {
+		return v, nil
+	}
+
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+// UTCTime matches time arguments in UTC, the zone timestamps are stored in.
+type UTCTime struct{}
+
+func (UTCTime) Match(v driver.Value) bool {
+	t, ok := v.(time.Time)
+
+	return ok && t.Location() == time.UTC
+}
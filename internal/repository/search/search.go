@@ -0,0 +1,216 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+)
+
+//go:generate mockery --name=ISearchRepository
+
+type ISearchRepository interface {
+	Search(ctx context.Context, query string, first *int, after *string) (*model.SearchConnection, error)
+}
+
+// Kinds of search results, also used in cursors to tell apart posts and
+// comments with the same ID.
+const (
+	KindPost    = "post"
+	KindComment = "comment"
+)
+
+type SearchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) *SearchRepository {
+	return &SearchRepository{
+		db: db,
+	}
+}
+
+type hit struct {
+	kind    string
+	id      int
+	rank    float64
+	snippet string
+}
+
+// Search returns visible posts and comments matching the query, the most
+// relevant first. The query is parsed with websearch_to_tsquery, so it
+// supports quoted phrases, OR and -word.
+func (r *SearchRepository) Search(ctx context.Context, query string, first *int, after *string) (*model.SearchConnection, error) {
+	afterCursor, err := cursor.DecodeOptional(after)
+	if err != nil {
+		return nil, err
+	}
+
+	values := []interface{}{query}
+	filter := ""
+	limit := ""
+
+	if afterCursor != nil {
+		filter = fmt.Sprintf(" WHERE (rank, kind, id) < ($%d, $%d, $%d)", len(values)+1, len(values)+2, len(values)+3)
+		values = append(values, afterCursor.Rank, afterCursor.Kind, afterCursor.ID)
+	}
+
+	if first != nil {
+		limit = fmt.Sprintf(" LIMIT $%d", len(values)+1)
+		values = append(values, *first+1)
+	}
+
+	// snippets are only built for the rows of the page
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT kind, id, rank, ts_headline('simple', text, websearch_to_tsquery('simple', $1), 'MaxWords=30, MinWords=10') FROM (
+				SELECT kind, id, rank, text FROM (
+					SELECT 'post' AS kind, p.id, ts_rank(p.search_vector, q) AS rank, p.title || ' ' || p.body AS text
+					FROM posts p, websearch_to_tsquery('simple', $1) q
+					WHERE p.search_vector @@ q AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+					UNION ALL
+					SELECT 'comment', c.id, ts_rank(c.search_vector, q), c.body
+					FROM comments c JOIN posts p ON p.id = c.post_id, websearch_to_tsquery('simple', $1) q
+					WHERE c.search_vector @@ q AND c.deleted_at IS NULL AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+				) r%s
+				ORDER BY rank DESC, kind DESC, id DESC%s
+			) r ORDER BY rank DESC, kind DESC, id DESC;`, filter, limit), values...)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	var hits []hit
+	var postIDs, commentIDs []int
+
+	for rows.Next() {
+		var h hit
+
+		err = rows.Scan(&h.kind, &h.id, &h.rank, &h.snippet)
+		if err != nil {
+			return nil, err
+		}
+
+		if h.kind == KindPost {
+			postIDs = append(postIDs, h.id)
+		} else {
+			commentIDs = append(commentIDs, h.id)
+		}
+
+		hits = append(hits, h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	posts, err := r.getPosts(ctx, postIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := r.getComments(ctx, commentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	pageInfo := &model.PageInfo{}
+
+	if first != nil && len(hits) > *first {
+		hits = hits[:*first]
+		pageInfo.HasNextPage = true
+	}
+
+	edges := make([]*model.SearchEdge, 0, len(hits))
+
+	for _, h := range hits {
+		var node model.SearchResult
+
+		// results removed after the search query are left out
+		if h.kind == KindPost {
+			post, ok := posts[h.id]
+			if !ok {
+				continue
+			}
+			node = post
+		} else {
+			comment, ok := comments[h.id]
+			if !ok {
+				continue
+			}
+			node = comment
+		}
+
+		edges = append(edges, &model.SearchEdge{
+			Cursor:  cursor.NewRank(h.rank, h.kind, h.id).Encode(),
+			Node:    node,
+			Snippet: h.snippet,
+		})
+	}
+
+	if len(edges) > 0 {
+		pageInfo.StartCursor = &edges[0].Cursor
+		pageInfo.EndCursor = &edges[len(edges)-1].Cursor
+	}
+
+	return &model.SearchConnection{
+		Edges:    edges,
+		PageInfo: pageInfo,
+	}, nil
+}
+
+func (r *SearchRepository) getPosts(ctx context.Context, ids []int) (map[int]*model.Post, error) {
+	posts := make(map[int]*model.Post, len(ids))
+	if len(ids) == 0 {
+		return posts, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, user_id, title, body, allow_comments, locked, created_at, updated_at, status, publish_at, upvotes, downvotes 
+										FROM posts WHERE id = ANY($1) AND deleted_at IS NULL;`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var post model.Post
+
+		err = rows.Scan(&post.ID, &post.UserID, &post.Title, &post.Body, &post.AllowComments, &post.IsLocked, &post.CreatedAt, &post.UpdatedAt, &post.Status, &post.PublishAt, &post.Upvotes, &post.Downvotes)
+		if err != nil {
+			return nil, err
+		}
+
+		posts[post.ID] = &post
+	}
+
+	return posts, rows.Err()
+}
+
+func (r *SearchRepository) getComments(ctx context.Context, ids []int) (map[int]*model.Comment, error) {
+	comments := make(map[int]*model.Comment, len(ids))
+	if len(ids) == 0 {
+		return comments, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT id, post_id, user_id, parent_comment_id, body, created_at, updated_at, deleted_at, upvotes, downvotes 
+										FROM comments WHERE id = ANY($1) AND deleted_at IS NULL;`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var comment model.Comment
+
+		err = rows.Scan(&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Body, &comment.CreatedAt, &comment.UpdatedAt, &comment.DeletedAt, &comment.Upvotes, &comment.Downvotes)
+		if err != nil {
+			return nil, err
+		}
+
+		comments[comment.ID] = &comment
+	}
+
+	return comments, rows.Err()
+}
@@ -0,0 +1,100 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/repotest"
+	"github.com/aaanger/graphql-test/pkg/cursor"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type SearchRepositorySuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *SearchRepository
+}
+
+func (suite *SearchRepositorySuite) SetupTest() {
+	var err error
+	suite.db, suite.mock, err = sqlmock.New(sqlmock.ValueConverterOption(repotest.SliceConverter{}))
+	assert.NoError(suite.T(), err)
+	suite.repo = NewSearchRepository(suite.db)
+}
+
+func TestSearchRepositorySuite(t *testing.T) {
+	suite.Run(t, new(SearchRepositorySuite))
+}
+
+// Search
+// ====================================================================================
+
+func (suite *SearchRepositorySuite) TestRepository_SearchPostsAndComments() {
+	first := 2
+
+	suite.mock.ExpectQuery(`SELECT kind, id, rank, ts_headline(.+)websearch_to_tsquery\('simple', \$1\)(.+)UNION ALL(.+)ORDER BY rank DESC, kind DESC, id DESC LIMIT \$2`).
+		WithArgs("graphql", first+1).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "rank", "snippet"}).
+			AddRow("post", 1, 0.6, "<b>graphql</b> post").
+			AddRow("comment", 1, 0.3, "about <b>graphql</b>").
+			AddRow("comment", 2, 0.1, "<b>graphql</b> again"))
+	suite.mock.ExpectQuery(`SELECT (.+) FROM posts WHERE id = ANY\(\$1\) AND deleted_at IS NULL;`).
+		WithArgs([]int{1}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "body", "allow_comments", "locked", "created_at", "updated_at", "status", "publish_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, "graphql", "post", true, false, time.Now(), nil, "PUBLISHED", nil, 0, 0))
+	suite.mock.ExpectQuery(`SELECT (.+) FROM comments WHERE id = ANY\(\$1\) AND deleted_at IS NULL;`).
+		WithArgs([]int{1, 2}).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "user_id", "parent_comment_id", "body", "created_at", "updated_at", "deleted_at", "upvotes", "downvotes"}).
+			AddRow(1, 1, 2, nil, "about graphql", time.Now(), nil, nil, 0, 0).
+			AddRow(2, 1, 2, nil, "graphql again", time.Now(), nil, nil, 0, 0))
+
+	results, err := suite.repo.Search(context.Background(), "graphql", &first, nil)
+
+	suite.Nil(err)
+	suite.Len(results.Edges, 2)
+	suite.True(results.PageInfo.HasNextPage)
+
+	post, ok := results.Edges[0].Node.(*model.Post)
+	suite.True(ok)
+	suite.Equal(1, post.ID)
+	suite.Equal("<b>graphql</b> post", results.Edges[0].Snippet)
+
+	comment, ok := results.Edges[1].Node.(*model.Comment)
+	suite.True(ok)
+	suite.Equal(1, comment.ID)
+
+	endCursor, err := cursor.Decode(*results.PageInfo.EndCursor)
+	suite.Nil(err)
+	suite.Equal(KindComment, endCursor.Kind)
+	suite.Equal(0.3, endCursor.Rank)
+}
+
+func (suite *SearchRepositorySuite) TestRepository_SearchAfterCursor() {
+	after := cursor.NewRank(0.3, KindComment, 1).Encode()
+
+	suite.mock.ExpectQuery(`WHERE \(rank, kind, id\) < \(\$2, \$3, \$4\)(.+)LIMIT \$5`).
+		WithArgs("graphql", 0.3, KindComment, 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "id", "rank", "snippet"}))
+
+	first := 2
+	results, err := suite.repo.Search(context.Background(), "graphql", &first, &after)
+
+	suite.Nil(err)
+	suite.Empty(results.Edges)
+	suite.Nil(results.PageInfo.EndCursor)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *SearchRepositorySuite) TestRepository_SearchInvalidCursor() {
+	after := "invalid"
+
+	results, err := suite.repo.Search(context.Background(), "graphql", nil, &after)
+
+	suite.Nil(results)
+	suite.ErrorIs(err, cursor.ErrInvalidCursor)
+}
@@ -0,0 +1,108 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"time"
+)
+
+//go:generate mockery --name=ISessionRepository
+
+type ISessionRepository interface {
+	CreateSession(ctx context.Context, userID int, refreshTokenHash string, expiresAt time.Time) (*model.Session, error)
+	RotateSession(ctx context.Context, refreshTokenHash, newRefreshTokenHash string, expiresAt time.Time) (*model.Session, error)
+	IsSessionActive(ctx context.Context, sessionID int) (bool, error)
+	RevokeSession(ctx context.Context, userID, sessionID int) error
+	RevokeAllSessions(ctx context.Context, userID int) error
+}
+
+var ErrInvalidRefreshToken = apperror.Unauthenticated("invalid refresh token")
+
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+// CreateSession opens a session for the user. expires_at has no time zone, so
+// it is stored in UTC like every other timestamp.
+func (r *SessionRepository) CreateSession(ctx context.Context, userID int, refreshTokenHash string, expiresAt time.Time) (*model.Session, error) {
+	expiresAt = expiresAt.UTC()
+
+	session := model.Session{
+		UserID:    userID,
+		ExpiresAt: expiresAt,
+	}
+
+	row := r.db.QueryRowContext(ctx, `INSERT INTO sessions (user_id, refresh_token_hash, expires_at) VALUES ($1, $2, $3) RETURNING id, created_at;`,
+		userID, refreshTokenHash, expiresAt)
+
+	err := row.Scan(&session.ID, &session.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// RotateSession replaces the refresh token of an active session, so every
+// refresh token can be used only once.
+func (r *SessionRepository) RotateSession(ctx context.Context, refreshTokenHash, newRefreshTokenHash string, expiresAt time.Time) (*model.Session, error) {
+	expiresAt = expiresAt.UTC()
+
+	session := model.Session{
+		ExpiresAt: expiresAt,
+	}
+
+	row := r.db.QueryRowContext(ctx, `UPDATE sessions SET refresh_token_hash = $1, expires_at = $2
+				WHERE refresh_token_hash = $3 AND revoked_at IS NULL AND expires_at > NOW()
+				RETURNING id, user_id, created_at;`, newRefreshTokenHash, expiresAt, refreshTokenHash)
+
+	err := row.Scan(&session.ID, &session.UserID, &session.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepository) IsSessionActive(ctx context.Context, sessionID int) (bool, error) {
+	var isActive bool
+
+	row := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM sessions WHERE id = $1 AND revoked_at IS NULL AND expires_at > NOW());`, sessionID)
+
+	err := row.Scan(&isActive)
+	if err != nil {
+		return false, err
+	}
+
+	return isActive, nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID, sessionID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;`, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeAllSessions(ctx context.Context, userID int) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE sessions SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package session
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+type SessionRepositorySuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *SessionRepository
+}
+
+func (suite *SessionRepositorySuite) SetupTest() {
+	var err error
+	suite.db, suite.mock, err = sqlmock.New()
+	assert.NoError(suite.T(), err)
+	suite.repo = NewSessionRepository(suite.db)
+}
+
+func TestSessionRepositorySuite(t *testing.T) {
+	suite.Run(t, new(SessionRepositorySuite))
+}
+
+// CreateSession
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_CreateSessionSuccess() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+	suite.mock.ExpectQuery("INSERT INTO sessions").WithArgs(1, "hash", expiresAt.UTC()).WillReturnRows(rows)
+
+	session, err := suite.repo.CreateSession(context.Background(), 1, "hash", expiresAt)
+
+	suite.Nil(err)
+	suite.Equal(1, session.ID)
+	suite.Equal(1, session.UserID)
+}
+
+func (suite *SessionRepositorySuite) TestRepository_CreateSessionStoresUTC() {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	defer func() { time.Local = local }()
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "created_at"}).AddRow(1, time.Now())
+	suite.mock.ExpectQuery("INSERT INTO sessions").WithArgs(1, "hash", expiresAt.UTC()).WillReturnRows(rows)
+
+	session, err := suite.repo.CreateSession(context.Background(), 1, "hash", expiresAt)
+
+	suite.Nil(err)
+	suite.Equal(time.UTC, session.ExpiresAt.Location())
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// RotateSession
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_RotateSessionSuccess() {
+	expiresAt := time.Now().Add(time.Hour)
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "created_at"}).AddRow(3, 1, time.Now())
+	suite.mock.ExpectQuery(`UPDATE sessions SET refresh_token_hash = \$1, expires_at = \$2(.+)revoked_at IS NULL`).
+		WithArgs("new", expiresAt.UTC(), "old").WillReturnRows(rows)
+
+	session, err := suite.repo.RotateSession(context.Background(), "old", "new", expiresAt)
+
+	suite.Nil(err)
+	suite.Equal(3, session.ID)
+	suite.Equal(1, session.UserID)
+}
+
+func (suite *SessionRepositorySuite) TestRepository_RotateSessionUnknownToken() {
+	suite.mock.ExpectQuery("UPDATE sessions SET (.+)").
+		WithArgs("new", sqlmock.AnyArg(), "old").WillReturnError(sql.ErrNoRows)
+
+	session, err := suite.repo.RotateSession(context.Background(), "old", "new", time.Now())
+
+	suite.Nil(session)
+	suite.ErrorIs(err, ErrInvalidRefreshToken)
+}
+
+// IsSessionActive
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_IsSessionActive() {
+	rows := sqlmock.NewRows([]string{"exists"}).AddRow(false)
+	suite.mock.ExpectQuery("SELECT EXISTS (.+) FROM sessions WHERE (.+)").WithArgs(1).WillReturnRows(rows)
+
+	isActive, err := suite.repo.IsSessionActive(context.Background(), 1)
+
+	suite.Nil(err)
+	suite.False(isActive)
+}
+
+// RevokeSession
+// ==============================================
+
+func (suite *SessionRepositorySuite) TestRepository_RevokeSessionSuccess() {
+	suite.mock.ExpectExec("UPDATE sessions SET revoked_at = NOW\\(\\) WHERE id = (.+) AND user_id = (.+)").
+		WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := suite.repo.RevokeSession(context.Background(), 1, 2)
+
+	suite.Nil(err)
+}
+
+func (suite *SessionRepositorySuite) TestRepository_RevokeAllSessionsSuccess() {
+	suite.mock.ExpectExec("UPDATE sessions SET revoked_at = NOW\\(\\) WHERE user_id = (.+)").
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 3))
+
+	err := suite.repo.RevokeAllSessions(context.Background(), 1)
+
+	suite.Nil(err)
+}
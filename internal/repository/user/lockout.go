@@ -0,0 +1,47 @@
+package user
+
+import (
+	"golang.org/x/crypto/bcrypt"
+	"time"
+)
+
+const (
+	// MaxFailedLogins is how many wrong passwords in a row lock an account.
+	MaxFailedLogins = 5
+	// MaxFailedLoginsPerIP is how many failed logins from one IP within
+	// IPFailureWindow lock the IP, whatever accounts they were for.
+	MaxFailedLoginsPerIP = 20
+	IPFailureWindow      = 15 * time.Minute
+
+	BaseLockout = time.Minute
+	MaxLockout  = 24 * time.Hour
+)
+
+// dummyPasswordHash is compared when the email isn't registered.
+const dummyPasswordHash = "$2a$10$4GUZCD8NZC5vivL0xVb4DeK3uiEsngae8LzTS1QKAx9ImpMKmdCcO"
+
+// Lockout returns how long to lock after the given number of failures:
+// nothing below threshold, then BaseLockout doubling with every further
+// failure, up to MaxLockout.
+func Lockout(failures, threshold int) time.Duration {
+	if failures < threshold {
+		return 0
+	}
+
+	lockout := BaseLockout
+	for i := threshold; i < failures && lockout < MaxLockout; i++ {
+		lockout *= 2
+	}
+
+	if lockout > MaxLockout {
+		return MaxLockout
+	}
+
+	return lockout
+}
+
+// CompareDummyPassword takes as long as checking a real password, so logins
+// with unknown emails can't be told apart by their response time.
+func CompareDummyPassword(password string) {
+	_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+}
@@ -0,0 +1,15 @@
+package user
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestLockout(t *testing.T) {
+	assert.Equal(t, time.Duration(0), Lockout(MaxFailedLogins-1, MaxFailedLogins))
+	assert.Equal(t, BaseLockout, Lockout(MaxFailedLogins, MaxFailedLogins))
+	assert.Equal(t, 2*BaseLockout, Lockout(MaxFailedLogins+1, MaxFailedLogins))
+	assert.Equal(t, 8*BaseLockout, Lockout(MaxFailedLogins+3, MaxFailedLogins))
+	assert.Equal(t, MaxLockout, Lockout(MaxFailedLogins+100, MaxFailedLogins))
+}
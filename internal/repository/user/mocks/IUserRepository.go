@@ -4,9 +4,11 @@ package mocks
 
 import (
 	context "context"
-	model2 "github.com/aaanger/graphql-test/internal/graph/model"
 
+	model "github.com/aaanger/graphql-test/internal/graph/model"
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // IUserRepository is an autogenerated mock type for the IUserRepository type
@@ -14,78 +16,232 @@ type IUserRepository struct {
 	mock.Mock
 }
 
-// Login provides a mock function with given fields: ctx, req
-func (_m *IUserRepository) Login(ctx context.Context, req *model2.LoginReq) (*model2.User, string, error) {
-	ret := _m.Called(ctx, req)
+// BanUser provides a mock function with given fields: ctx, userID, until, reason
+func (_m *IUserRepository) BanUser(ctx context.Context, userID int, until *time.Time, reason string) (*model.User, error) {
+	ret := _m.Called(ctx, userID, until, reason)
 
 	if len(ret) == 0 {
-		panic("no return value specified for Login")
+		panic("no return value specified for BanUser")
 	}
 
-	var r0 *model2.User
-	var r1 string
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model2.LoginReq) (*model2.User, string, error)); ok {
-		return rf(ctx, req)
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, *time.Time, string) (*model.User, error)); ok {
+		return rf(ctx, userID, until, reason)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *model2.LoginReq) *model2.User); ok {
-		r0 = rf(ctx, req)
+	if rf, ok := ret.Get(0).(func(context.Context, int, *time.Time, string) *model.User); ok {
+		r0 = rf(ctx, userID, until, reason)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model2.User)
+			r0 = ret.Get(0).(*model.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *model2.LoginReq) string); ok {
-		r1 = rf(ctx, req)
+	if rf, ok := ret.Get(1).(func(context.Context, int, *time.Time, string) error); ok {
+		r1 = rf(ctx, userID, until, reason)
 	} else {
-		r1 = ret.Get(1).(string)
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CheckBan provides a mock function with given fields: ctx, userID
+func (_m *IUserRepository) CheckBan(ctx context.Context, userID int) error {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CheckBan")
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, *model2.LoginReq) error); ok {
-		r2 = rf(ctx, req)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) error); ok {
+		r0 = rf(ctx, userID)
 	} else {
-		r2 = ret.Error(2)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1, r2
+	return r0
+}
+
+// GetEmailsByIDs provides a mock function with given fields: ctx, ids
+func (_m *IUserRepository) GetEmailsByIDs(ctx context.Context, ids []int) (map[int]string, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEmailsByIDs")
+	}
+
+	var r0 map[int]string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) (map[int]string, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) map[int]string); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int]string)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetUsersByIDs provides a mock function with given fields: ctx, ids
+func (_m *IUserRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]*model.User, error) {
+	ret := _m.Called(ctx, ids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsersByIDs")
+	}
+
+	var r0 []*model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []int) ([]*model.User, error)); ok {
+		return rf(ctx, ids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []int) []*model.User); ok {
+		r0 = rf(ctx, ids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []int) error); ok {
+		r1 = rf(ctx, ids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Login provides a mock function with given fields: ctx, req, ip
+func (_m *IUserRepository) Login(ctx context.Context, req *model.LoginReq, ip string) (*model.User, error) {
+	ret := _m.Called(ctx, req, ip)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Login")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LoginReq, string) (*model.User, error)); ok {
+		return rf(ctx, req, ip)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *model.LoginReq, string) *model.User); ok {
+		r0 = rf(ctx, req, ip)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *model.LoginReq, string) error); ok {
+		r1 = rf(ctx, req, ip)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
 // Register provides a mock function with given fields: ctx, req
-func (_m *IUserRepository) Register(ctx context.Context, req *model2.RegisterReq) (*model2.User, string, error) {
+func (_m *IUserRepository) Register(ctx context.Context, req *model.RegisterReq) (*model.User, error) {
 	ret := _m.Called(ctx, req)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Register")
 	}
 
-	var r0 *model2.User
-	var r1 string
-	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, *model2.RegisterReq) (*model2.User, string, error)); ok {
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *model.RegisterReq) (*model.User, error)); ok {
 		return rf(ctx, req)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, *model2.RegisterReq) *model2.User); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, *model.RegisterReq) *model.User); ok {
 		r0 = rf(ctx, req)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*model2.User)
+			r0 = ret.Get(0).(*model.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, *model2.RegisterReq) string); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, *model.RegisterReq) error); ok {
 		r1 = rf(ctx, req)
 	} else {
-		r1 = ret.Get(1).(string)
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetRole provides a mock function with given fields: ctx, userID, role
+func (_m *IUserRepository) SetRole(ctx context.Context, userID int, role model.Role) (*model.User, error) {
+	ret := _m.Called(ctx, userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetRole")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, model.Role) (*model.User, error)); ok {
+		return rf(ctx, userID, role)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, model.Role) *model.User); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, model.Role) error); ok {
+		r1 = rf(ctx, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UnbanUser provides a mock function with given fields: ctx, userID
+func (_m *IUserRepository) UnbanUser(ctx context.Context, userID int) (*model.User, error) {
+	ret := _m.Called(ctx, userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UnbanUser")
+	}
+
+	var r0 *model.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int) (*model.User, error)); ok {
+		return rf(ctx, userID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) *model.User); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.User)
+		}
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, *model2.RegisterReq) error); ok {
-		r2 = rf(ctx, req)
+	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+		r1 = rf(ctx, userID)
 	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
 // NewIUserRepository creates a new instance of IUserRepository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
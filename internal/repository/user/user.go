@@ -3,19 +3,41 @@ package user
 import (
 	"context"
 	"database/sql"
+	"errors"
 	model2 "github.com/aaanger/graphql-test/internal/graph/model"
-	"github.com/aaanger/graphql-test/pkg/jwt"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/jackc/pgx/v5/pgconn"
 	"golang.org/x/crypto/bcrypt"
 	"strings"
+	"time"
 )
 
 //go:generate mockery --name=IUserRepository
 
 type IUserRepository interface {
-	Register(ctx context.Context, req *model2.RegisterReq) (*model2.User, string, error)
-	Login(ctx context.Context, req *model2.LoginReq) (*model2.User, string, error)
+	Register(ctx context.Context, req *model2.RegisterReq) (*model2.User, error)
+	Login(ctx context.Context, req *model2.LoginReq, ip string) (*model2.User, error)
+	GetUsersByIDs(ctx context.Context, ids []int) ([]*model2.User, error)
+	GetEmailsByIDs(ctx context.Context, ids []int) (map[int]string, error)
+	SetRole(ctx context.Context, userID int, role model2.Role) (*model2.User, error)
+	BanUser(ctx context.Context, userID int, until *time.Time, reason string) (*model2.User, error)
+	UnbanUser(ctx context.Context, userID int) (*model2.User, error)
+	CheckBan(ctx context.Context, userID int) error
 }
 
+// ErrInvalidCredentials doesn't tell whether the email or the password was
+// wrong, so it can't be used to find registered emails.
+var ErrInvalidCredentials = apperror.Unauthenticated("invalid email or password")
+
+const uniqueViolation = "23505"
+
+// userColumns are read by scanUser.
+const userColumns = `id, email, username, role, banned_at, banned_until, COALESCE(ban_reason, ''), failed_login_attempts, locked_until`
+
+// profileColumns are userColumns with an empty email. They are read for
+// profiles of other users, which must not carry the email.
+const profileColumns = `id, '', username, role, banned_at, banned_until, COALESCE(ban_reason, ''), failed_login_attempts, locked_until`
+
 type UserRepository struct {
 	db *sql.DB
 }
@@ -26,10 +48,10 @@ func NewUserRepository(db *sql.DB) *UserRepository {
 	}
 }
 
-func (r *UserRepository) Register(ctx context.Context, req *model2.RegisterReq) (*model2.User, string, error) {
+func (r *UserRepository) Register(ctx context.Context, req *model2.RegisterReq) (*model2.User, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	passwordHash := string(hashedBytes)
@@ -40,41 +62,269 @@ func (r *UserRepository) Register(ctx context.Context, req *model2.RegisterReq)
 		Password: passwordHash,
 	}
 
-	row := r.db.QueryRowContext(ctx, `INSERT INTO users (email, username, password_hash) VALUES($1, $2, $3) RETURNING id;`, req.Email, req.Username, passwordHash)
+	row := r.db.QueryRowContext(ctx, `INSERT INTO users (email, username, password_hash) VALUES($1, $2, $3) RETURNING id, role;`, user.Email, req.Username, passwordHash)
 
-	err = row.Scan(&user.ID)
-	if err != nil {
-		return nil, "", err
+	err = row.Scan(&user.ID, &user.Role)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return nil, apperror.Conflict("user with this email or username already exists")
 	}
-
-	accessToken, err := jwt.GenerateAccessToken(user.ID)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return &user, accessToken, nil
+	return &user, nil
 }
 
-func (r *UserRepository) Login(ctx context.Context, req *model2.LoginReq) (*model2.User, string, error) {
+// Login checks the credentials of the user. Failed logins are counted per
+// account and per IP, and both are locked for a while once they fail too
+// often. A locked account answers like an unknown email, with
+// ErrInvalidCredentials after the same bcrypt work, so lockouts don't reveal
+// which emails are registered.
+func (r *UserRepository) Login(ctx context.Context, req *model2.LoginReq, ip string) (*model2.User, error) {
+	err := r.checkIPLock(ctx, ip)
+	if err != nil {
+		return nil, err
+	}
+
 	user := model2.User{
 		Email: strings.ToLower(req.Email),
 	}
 
-	row := r.db.QueryRowContext(ctx, `SELECT id, username, password_hash FROM users WHERE email = $1;`, req.Email)
-	err := row.Scan(&user.ID, &user.Username, &user.Password)
+	row := r.db.QueryRowContext(ctx, `SELECT id, username, password_hash, role, banned_at, banned_until, COALESCE(ban_reason, ''), failed_login_attempts, locked_until 
+						FROM users WHERE email = $1;`, user.Email)
+	err = row.Scan(&user.ID, &user.Username, &user.Password, &user.Role, &user.BannedAt, &user.BannedUntil, &user.BanReason, &user.FailedLoginAttempts, &user.LockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		CompareDummyPassword(req.Password)
+		return nil, r.failLogin(ctx, 0, ip)
+	}
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
+
+	if user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return nil, r.failLogin(ctx, 0, ip)
+	}
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, r.failLogin(ctx, user.ID, ip)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		_, err = r.db.ExecContext(ctx, `UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1;`, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		user.FailedLoginAttempts = 0
+		user.LockedUntil = nil
+	}
+
+	// checked after the password, so the ban doesn't tell anyone else the
+	// email is registered
+	if user.IsBanned(time.Now()) {
+		return nil, apperror.Banned(user.BannedUntil, user.BanReason)
+	}
+
+	return &user, nil
+}
+
+// checkIPLock returns a RATE_LIMITED error while logins from the IP are
+// locked. locked_until has no time zone and holds UTC, so it is compared with
+// the current UTC time rather than NOW() in the zone of the database.
+func (r *UserRepository) checkIPLock(ctx context.Context, ip string) error {
+	if ip == "" {
+		return nil
+	}
+
+	var lockedUntil time.Time
+
+	row := r.db.QueryRowContext(ctx, `SELECT locked_until FROM login_ip_attempts WHERE ip = $1 AND locked_until > $2;`, ip, time.Now().UTC())
+	err := row.Scan(&lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return apperror.RateLimited(time.Until(lockedUntil))
+}
+
+// failLogin counts a failed login of the account, unless the email is
+// unknown, and of the IP, locks whichever failed too often and returns
+// ErrInvalidCredentials.
+func (r *UserRepository) failLogin(ctx context.Context, userID int, ip string) error {
+	if userID != 0 {
+		var failures int
+
+		row := r.db.QueryRowContext(ctx, `UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = $1 RETURNING failed_login_attempts;`, userID)
+		err := row.Scan(&failures)
+		if err != nil {
+			return err
+		}
+
+		if lockout := Lockout(failures, MaxFailedLogins); lockout > 0 {
+			_, err = r.db.ExecContext(ctx, `UPDATE users SET locked_until = $1 WHERE id = $2;`, time.Now().UTC().Add(lockout), userID)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if ip != "" {
+		var failures int
+
+		// failures older than the window are forgotten
+		row := r.db.QueryRowContext(ctx, `INSERT INTO login_ip_attempts (ip, failed_attempts) VALUES ($1, 1) 
+						ON CONFLICT (ip) DO UPDATE SET 
+							failed_attempts = CASE WHEN login_ip_attempts.updated_at < NOW() - make_interval(secs => $2) THEN 1 ELSE login_ip_attempts.failed_attempts + 1 END, 
+							updated_at = NOW() 
+						RETURNING failed_attempts;`, ip, IPFailureWindow.Seconds())
+		err := row.Scan(&failures)
+		if err != nil {
+			return err
+		}
+
+		if lockout := Lockout(failures, MaxFailedLoginsPerIP); lockout > 0 {
+			_, err = r.db.ExecContext(ctx, `UPDATE login_ip_attempts SET locked_until = $1 WHERE ip = $2;`, time.Now().UTC().Add(lockout), ip)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return ErrInvalidCredentials
+}
+
+// GetUsersByIDs returns the profiles of the users, without their emails.
+func (r *UserRepository) GetUsersByIDs(ctx context.Context, ids []int) ([]*model2.User, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+profileColumns+` FROM users WHERE id = ANY($1);`, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	users := make([]*model2.User, 0, len(ids))
+
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetEmailsByIDs returns the emails of the users keyed by user ID.
+func (r *UserRepository) GetEmailsByIDs(ctx context.Context, ids []int) (map[int]string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, email FROM users WHERE id = ANY($1);`, ids)
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	accessToken, err := jwt.GenerateAccessToken(user.ID)
+	defer rows.Close()
+
+	emails := make(map[int]string, len(ids))
+
+	for rows.Next() {
+		var id int
+		var email string
+
+		err = rows.Scan(&id, &email)
+		if err != nil {
+			return nil, err
+		}
+
+		emails[id] = email
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return emails, nil
+}
+
+// SetRole changes the role of the user and returns the updated user.
+func (r *UserRepository) SetRole(ctx context.Context, userID int, role model2.Role) (*model2.User, error) {
+	row := r.db.QueryRowContext(ctx, `UPDATE users SET role = $1 WHERE id = $2 RETURNING `+userColumns+`;`, role, userID)
+
+	return scanUpdatedUser(row)
+}
+
+// BanUser bans the user until the given time, or for good when until is nil.
+// A new ban replaces the previous one.
+func (r *UserRepository) BanUser(ctx context.Context, userID int, until *time.Time, reason string) (*model2.User, error) {
+	row := r.db.QueryRowContext(ctx, `UPDATE users SET banned_at = NOW(), banned_until = $1, ban_reason = $2 WHERE id = $3 RETURNING `+userColumns+`;`, until, reason, userID)
+
+	return scanUpdatedUser(row)
+}
+
+func (r *UserRepository) UnbanUser(ctx context.Context, userID int) (*model2.User, error) {
+	row := r.db.QueryRowContext(ctx, `UPDATE users SET banned_at = NULL, banned_until = NULL, ban_reason = NULL WHERE id = $1 RETURNING `+userColumns+`;`, userID)
+
+	return scanUpdatedUser(row)
+}
+
+// CheckBan returns a BANNED error while the user is banned.
+func (r *UserRepository) CheckBan(ctx context.Context, userID int) error {
+	var (
+		until  *time.Time
+		reason string
+	)
+
+	row := r.db.QueryRowContext(ctx, `SELECT banned_until, COALESCE(ban_reason, '') FROM users 
+						WHERE id = $1 AND banned_at IS NOT NULL AND (banned_until IS NULL OR banned_until > NOW());`, userID)
+	err := row.Scan(&until, &reason)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return apperror.Banned(until, reason)
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(row scanner) (*model2.User, error) {
+	var user model2.User
+
+	err := row.Scan(&user.ID, &user.Email, &user.Username, &user.Role, &user.BannedAt, &user.BannedUntil, &user.BanReason, &user.FailedLoginAttempts, &user.LockedUntil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// scanUpdatedUser reads the user returned by an UPDATE, no row means there is
+// no such user.
+func scanUpdatedUser(row *sql.Row) (*model2.User, error) {
+	user, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, apperror.NotFound("user not found")
+	}
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	return &user, accessToken, nil
+	return user, nil
 }
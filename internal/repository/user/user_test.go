@@ -6,10 +6,14 @@ import (
 	"errors"
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/repotest"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"golang.org/x/crypto/bcrypt"
 	"testing"
+	"time"
 )
 
 type UserRepositorySuite struct {
@@ -21,7 +25,7 @@ type UserRepositorySuite struct {
 
 func (suite *UserRepositorySuite) SetupTest() {
 	var err error
-	suite.db, suite.mock, err = sqlmock.New()
+	suite.db, suite.mock, err = sqlmock.New(sqlmock.ValueConverterOption(repotest.SliceConverter{}))
 	assert.NoError(suite.T(), err)
 	suite.repo = NewUserRepository(suite.db)
 }
@@ -40,26 +44,42 @@ func (suite *UserRepositorySuite) TestRepository_RegisterSuccess() {
 		Password: "test",
 	}
 
-	rows := sqlmock.NewRows([]string{"id"}).AddRow(1)
+	rows := sqlmock.NewRows([]string{"id", "role"}).AddRow(1, "USER")
 	suite.mock.ExpectQuery("INSERT INTO users").WithArgs(req.Email, req.Username, sqlmock.AnyArg()).
 		WillReturnRows(rows)
 
-	user, token, err := suite.repo.Register(context.Background(), req)
+	user, err := suite.repo.Register(context.Background(), req)
 
 	suite.NotNil(user)
-	suite.NotEmpty(token)
 	suite.Nil(err)
 }
 
+func (suite *UserRepositorySuite) TestRepository_RegisterNormalizesEmail() {
+	req := &model.RegisterReq{
+		Email:    "Test@Example.com",
+		Username: "test",
+		Password: "test",
+	}
+
+	rows := sqlmock.NewRows([]string{"id", "role"}).AddRow(1, "USER")
+	suite.mock.ExpectQuery("INSERT INTO users").WithArgs("test@example.com", req.Username, sqlmock.AnyArg()).
+		WillReturnRows(rows)
+
+	user, err := suite.repo.Register(context.Background(), req)
+
+	suite.Nil(err)
+	suite.Equal("test@example.com", user.Email)
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
 func (suite *UserRepositorySuite) TestRepository_RegisterEmptyFields() {
 	req := &model.RegisterReq{
 		Password: "test",
 	}
 
-	user, token, err := suite.repo.Register(context.Background(), req)
+	user, err := suite.repo.Register(context.Background(), req)
 
 	suite.Nil(user)
-	suite.Empty(token)
 	suite.NotNil(err)
 }
 
@@ -70,16 +90,33 @@ func (suite *UserRepositorySuite) TestRepository_RegisterPasswordHashError() {
 		Password: "",
 	}
 
-	user, token, err := suite.repo.Register(context.Background(), req)
+	user, err := suite.repo.Register(context.Background(), req)
 
 	suite.Nil(user)
-	suite.Empty(token)
 	suite.NotNil(err)
 }
 
+func (suite *UserRepositorySuite) TestRepository_RegisterDuplicate() {
+	req := &model.RegisterReq{
+		Email:    "test",
+		Username: "test",
+		Password: "test",
+	}
+
+	suite.mock.ExpectQuery("INSERT INTO users").WithArgs(req.Email, req.Username, sqlmock.AnyArg()).
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	user, err := suite.repo.Register(context.Background(), req)
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeConflict, apperror.CodeOf(err))
+}
+
 // Login
 // ==========================
 
+var loginColumns = []string{"id", "username", "password_hash", "role", "banned_at", "banned_until", "ban_reason", "failed_login_attempts", "locked_until"}
+
 func (suite *UserRepositorySuite) TestRepository_LoginSuccess() {
 	req := &model.LoginReq{
 		Email:    "test",
@@ -88,24 +125,78 @@ func (suite *UserRepositorySuite) TestRepository_LoginSuccess() {
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
 
-	rows := sqlmock.NewRows([]string{"id", "username", "password_hash"}).AddRow(1, "test", string(hashedPassword))
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "MODERATOR", nil, nil, "", 0, nil)
 	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
 		WithArgs(req.Email).WillReturnRows(rows)
 
-	user, token, err := suite.repo.Login(context.Background(), req)
+	user, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(err)
+	suite.Equal(model.RoleModerator, user.Role)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginNormalizesEmail() {
+	req := &model.LoginReq{
+		Email:    "Test@Example.com",
+		Password: "test",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", 0, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs("test@example.com").WillReturnRows(rows)
+
+	_, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(err)
+	suite.NoError(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginBanned() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	bannedUntil := time.Now().Add(time.Hour)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", time.Now(), bannedUntil, "spam", 0, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+
+	user, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginAfterBanExpired() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	bannedUntil := time.Now().Add(-time.Hour)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", time.Now().Add(-2*time.Hour), bannedUntil, "spam", 0, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+
+	user, err := suite.repo.Login(context.Background(), req, "")
 
-	suite.NotNil(user)
-	suite.NotEmpty(token)
 	suite.Nil(err)
+	suite.Equal(1, user.ID)
 }
 
 func (suite *UserRepositorySuite) TestRepository_LoginEmptyFields() {
 	req := &model.LoginReq{}
 
-	user, token, err := suite.repo.Login(context.Background(), req)
+	user, err := suite.repo.Login(context.Background(), req, "")
 
 	suite.Nil(user)
-	suite.Empty(token)
 	suite.NotNil(err)
 }
 
@@ -119,10 +210,9 @@ func (suite *UserRepositorySuite) TestRepository_InvalidEmail() {
 		WithArgs(req.Email).
 		WillReturnError(errors.New("sql: no rows in result set"))
 
-	user, token, err := suite.repo.Login(context.Background(), req)
+	user, err := suite.repo.Login(context.Background(), req, "")
 
 	suite.Nil(user)
-	suite.Empty(token)
 	suite.NotNil(err)
 }
 
@@ -139,9 +229,309 @@ func (suite *UserRepositorySuite) TestRepository_LoginWrongPassword() {
 		WithArgs(req.Email).
 		WillReturnRows(rows)
 
-	user, token, err := suite.repo.Login(context.Background(), req)
+	user, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(user)
+	suite.NotNil(err)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginHidesWhichCredentialWasWrong() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "wrong",
+	}
+
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnError(sql.ErrNoRows)
+
+	_, unknownEmailErr := suite.repo.Login(context.Background(), req, "")
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("test"), bcrypt.MinCost)
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", 0, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`UPDATE users SET failed_login_attempts = failed_login_attempts \+ 1`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"failed_login_attempts"}).AddRow(1))
+
+	_, wrongPasswordErr := suite.repo.Login(context.Background(), req, "")
+
+	suite.ErrorIs(unknownEmailErr, ErrInvalidCredentials)
+	suite.ErrorIs(wrongPasswordErr, ErrInvalidCredentials)
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginLocksAccount() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "wrong",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("test"), bcrypt.MinCost)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", MaxFailedLogins-1, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`UPDATE users SET failed_login_attempts = failed_login_attempts \+ 1 WHERE id = \$1 RETURNING failed_login_attempts;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"failed_login_attempts"}).AddRow(MaxFailedLogins))
+	suite.mock.ExpectExec(`UPDATE users SET locked_until = \$1 WHERE id = \$2;`).
+		WithArgs(repotest.UTCTime{}, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	user, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginLocksAccountInUTC() {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+3", 3*60*60)
+	defer func() { time.Local = local }()
+
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "wrong",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("test"), bcrypt.MinCost)
+
+	suite.mock.ExpectQuery(`SELECT locked_until FROM login_ip_attempts`).
+		WithArgs("10.0.0.1", repotest.UTCTime{}).WillReturnRows(sqlmock.NewRows([]string{"locked_until"}))
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", MaxFailedLogins-1, nil)
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+	suite.mock.ExpectQuery(`UPDATE users SET failed_login_attempts = failed_login_attempts \+ 1`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"failed_login_attempts"}).AddRow(MaxFailedLogins))
+	suite.mock.ExpectExec(`UPDATE users SET locked_until = \$1 WHERE id = \$2;`).
+		WithArgs(repotest.UTCTime{}, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectQuery(`INSERT INTO login_ip_attempts`).
+		WithArgs("10.0.0.1", IPFailureWindow.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(MaxFailedLoginsPerIP))
+	suite.mock.ExpectExec(`UPDATE login_ip_attempts SET locked_until = \$1 WHERE ip = \$2;`).
+		WithArgs(repotest.UTCTime{}, "10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	_, err := suite.repo.Login(context.Background(), req, "10.0.0.1")
+
+	suite.ErrorIs(err, ErrInvalidCredentials)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginAccountLocked() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", MaxFailedLogins, time.Now().Add(time.Minute))
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+
+	user, err := suite.repo.Login(context.Background(), req, "")
 
+	// the right password is rejected like an unknown email
 	suite.Nil(user)
-	suite.Empty(token)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginResetsFailures() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+
+	rows := sqlmock.NewRows(loginColumns).AddRow(1, "test", string(hashedPassword), "USER", nil, nil, "", 3, time.Now().Add(-time.Minute))
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnRows(rows)
+	suite.mock.ExpectExec(`UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = \$1;`).
+		WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	user, err := suite.repo.Login(context.Background(), req, "")
+
+	suite.Nil(err)
+	suite.Equal(0, user.FailedLoginAttempts)
+	suite.Nil(user.LockedUntil)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginIPLocked() {
+	req := &model.LoginReq{
+		Email:    "test",
+		Password: "test",
+	}
+
+	rows := sqlmock.NewRows([]string{"locked_until"}).AddRow(time.Now().Add(time.Minute))
+	suite.mock.ExpectQuery(`SELECT locked_until FROM login_ip_attempts WHERE ip = \$1 AND locked_until > \$2;`).
+		WithArgs("10.0.0.1", repotest.UTCTime{}).WillReturnRows(rows)
+
+	user, err := suite.repo.Login(context.Background(), req, "10.0.0.1")
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeRateLimited, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *UserRepositorySuite) TestRepository_LoginLocksIP() {
+	req := &model.LoginReq{
+		Email:    "unknown",
+		Password: "test",
+	}
+
+	suite.mock.ExpectQuery(`SELECT locked_until FROM login_ip_attempts`).
+		WithArgs("10.0.0.1", repotest.UTCTime{}).WillReturnRows(sqlmock.NewRows([]string{"locked_until"}))
+	suite.mock.ExpectQuery(`SELECT (.+) FROM users WHERE (.+)`).
+		WithArgs(req.Email).WillReturnError(sql.ErrNoRows)
+	suite.mock.ExpectQuery(`INSERT INTO login_ip_attempts`).
+		WithArgs("10.0.0.1", IPFailureWindow.Seconds()).
+		WillReturnRows(sqlmock.NewRows([]string{"failed_attempts"}).AddRow(MaxFailedLoginsPerIP))
+	suite.mock.ExpectExec(`UPDATE login_ip_attempts SET locked_until = \$1 WHERE ip = \$2;`).
+		WithArgs(repotest.UTCTime{}, "10.0.0.1").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	user, err := suite.repo.Login(context.Background(), req, "10.0.0.1")
+
+	suite.Nil(user)
+	suite.ErrorIs(err, ErrInvalidCredentials)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// GetUsersByIDs
+// =================
+
+var userColumnNames = []string{"id", "email", "username", "role", "banned_at", "banned_until", "ban_reason", "failed_login_attempts", "locked_until"}
+
+func (suite *UserRepositorySuite) TestRepository_GetUsersByIDsSuccess() {
+	lockedUntil := time.Now().Add(time.Minute)
+
+	rows := sqlmock.NewRows(userColumnNames).
+		AddRow(1, "", "first", "USER", nil, nil, "", MaxFailedLogins, lockedUntil).
+		AddRow(2, "", "second", "ADMIN", nil, nil, "", 0, nil)
+	suite.mock.ExpectQuery(`SELECT id, '', username, role, banned_at, banned_until, COALESCE\(ban_reason, ''\), failed_login_attempts, locked_until FROM users WHERE id = ANY\(\$1\);`).
+		WithArgs([]int{1, 2, 3}).WillReturnRows(rows)
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{1, 2, 3})
+
+	suite.Nil(err)
+	suite.Len(users, 2)
+	suite.Equal(MaxFailedLogins, users[0].FailedLoginAttempts)
+	suite.True(users[0].LockedUntil.Equal(lockedUntil))
+	suite.Equal("second", users[1].Username)
+}
+
+func (suite *UserRepositorySuite) TestRepository_GetUsersByIDsFailure() {
+	suite.mock.ExpectQuery("SELECT (.+) FROM users").
+		WithArgs([]int{1}).WillReturnError(errors.New("error"))
+
+	users, err := suite.repo.GetUsersByIDs(context.Background(), []int{1})
+
+	suite.Nil(users)
+	suite.NotNil(err)
+}
+
+// GetEmailsByIDs
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_GetEmailsByIDsSuccess() {
+	rows := sqlmock.NewRows([]string{"id", "email"}).AddRow(1, "first@mail.com")
+	suite.mock.ExpectQuery(`SELECT id, email FROM users WHERE id = ANY\(\$1\);`).
+		WithArgs([]int{1, 2}).WillReturnRows(rows)
+
+	emails, err := suite.repo.GetEmailsByIDs(context.Background(), []int{1, 2})
+
+	suite.Nil(err)
+	suite.Equal(map[int]string{1: "first@mail.com"}, emails)
+}
+
+func (suite *UserRepositorySuite) TestRepository_GetEmailsByIDsFailure() {
+	suite.mock.ExpectQuery("SELECT id, email FROM users").
+		WithArgs([]int{1}).WillReturnError(errors.New("error"))
+
+	emails, err := suite.repo.GetEmailsByIDs(context.Background(), []int{1})
+
+	suite.Nil(emails)
 	suite.NotNil(err)
 }
+
+// SetRole
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_SetRoleSuccess() {
+	rows := sqlmock.NewRows(userColumnNames).AddRow(2, "test@mail.com", "test", "MODERATOR", nil, nil, "", 0, nil)
+	suite.mock.ExpectQuery(`UPDATE users SET role = \$1 WHERE id = \$2 RETURNING id, email, username, role`).
+		WithArgs(model.RoleModerator, 2).WillReturnRows(rows)
+
+	user, err := suite.repo.SetRole(context.Background(), 2, model.RoleModerator)
+
+	suite.Nil(err)
+	suite.Equal(model.RoleModerator, user.Role)
+}
+
+func (suite *UserRepositorySuite) TestRepository_SetRoleNotFound() {
+	suite.mock.ExpectQuery(`UPDATE users SET role`).
+		WithArgs(model.RoleAdmin, 2).WillReturnError(sql.ErrNoRows)
+
+	user, err := suite.repo.SetRole(context.Background(), 2, model.RoleAdmin)
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// Bans
+// =================
+
+func (suite *UserRepositorySuite) TestRepository_BanUserSuccess() {
+	until := time.Now().Add(24 * time.Hour)
+
+	rows := sqlmock.NewRows(userColumnNames).
+		AddRow(2, "test@mail.com", "test", "USER", time.Now(), until, "spam", 0, nil)
+	suite.mock.ExpectQuery(`UPDATE users SET banned_at = NOW\(\), banned_until = \$1, ban_reason = \$2 WHERE id = \$3`).
+		WithArgs(&until, "spam", 2).WillReturnRows(rows)
+
+	user, err := suite.repo.BanUser(context.Background(), 2, &until, "spam")
+
+	suite.Nil(err)
+	suite.True(user.IsBanned(time.Now()))
+	suite.Equal("spam", user.BanReason)
+}
+
+func (suite *UserRepositorySuite) TestRepository_BanUserNotFound() {
+	suite.mock.ExpectQuery(`UPDATE users SET banned_at`).
+		WithArgs(nil, "spam", 2).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	user, err := suite.repo.BanUser(context.Background(), 2, nil, "spam")
+
+	suite.Nil(user)
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+func (suite *UserRepositorySuite) TestRepository_UnbanUser() {
+	rows := sqlmock.NewRows(userColumnNames).AddRow(2, "test@mail.com", "test", "USER", nil, nil, "", 0, nil)
+	suite.mock.ExpectQuery(`UPDATE users SET banned_at = NULL, banned_until = NULL, ban_reason = NULL WHERE id = \$1`).
+		WithArgs(2).WillReturnRows(rows)
+
+	user, err := suite.repo.UnbanUser(context.Background(), 2)
+
+	suite.Nil(err)
+	suite.False(user.IsBanned(time.Now()))
+}
+
+func (suite *UserRepositorySuite) TestRepository_CheckBanBanned() {
+	rows := sqlmock.NewRows([]string{"banned_until", "ban_reason"}).AddRow(nil, "spam")
+	suite.mock.ExpectQuery(`SELECT banned_until, COALESCE\(ban_reason, ''\) FROM users\s+WHERE id = \$1 AND banned_at IS NOT NULL AND \(banned_until IS NULL OR banned_until > NOW\(\)\);`).
+		WithArgs(2).WillReturnRows(rows)
+
+	err := suite.repo.CheckBan(context.Background(), 2)
+
+	suite.Equal(apperror.CodeBanned, apperror.CodeOf(err))
+}
+
+func (suite *UserRepositorySuite) TestRepository_CheckBanNotBanned() {
+	suite.mock.ExpectQuery(`SELECT banned_until`).
+		WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"banned_until", "ban_reason"}))
+
+	err := suite.repo.CheckBan(context.Background(), 2)
+
+	suite.Nil(err)
+}
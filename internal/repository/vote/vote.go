@@ -0,0 +1,165 @@
+package vote
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+)
+
+//go:generate mockery --name=IVoteRepository
+
+type IVoteRepository interface {
+	VotePost(ctx context.Context, userID, postID int, value model.VoteValue) error
+	VoteComment(ctx context.Context, userID, commentID int, value model.VoteValue) error
+	GetPostVotes(ctx context.Context, userID int, postIDs []int) (map[int]model.VoteValue, error)
+	GetCommentVotes(ctx context.Context, userID int, commentIDs []int) (map[int]model.VoteValue, error)
+}
+
+type VoteRepository struct {
+	db *sql.DB
+}
+
+func NewVoteRepository(db *sql.DB) *VoteRepository {
+	return &VoteRepository{
+		db: db,
+	}
+}
+
+// target describes an entity that can be voted on: the table holding its
+// counters, the table holding the votes, the column of the votes table
+// referencing the entity and the query locking an entity open for voting.
+type target struct {
+	table    string
+	votes    string
+	key      string
+	lock     string
+	notFound string
+}
+
+var (
+	postTarget = target{
+		table:    "posts",
+		votes:    "post_votes",
+		key:      "post_id",
+		lock:     `SELECT id FROM posts WHERE id = $1 AND deleted_at IS NULL AND status = 'PUBLISHED' FOR UPDATE;`,
+		notFound: "post not found",
+	}
+	commentTarget = target{
+		table: "comments",
+		votes: "comment_votes",
+		key:   "comment_id",
+		lock: `SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'
+				WHERE c.id = $1 AND c.deleted_at IS NULL FOR UPDATE OF c;`,
+		notFound: "comment not found",
+	}
+)
+
+// VotePost replaces the vote of the user on the post. NONE withdraws the
+// vote.
+func (r *VoteRepository) VotePost(ctx context.Context, userID, postID int, value model.VoteValue) error {
+	return r.vote(ctx, postTarget, userID, postID, value)
+}
+
+// VoteComment replaces the vote of the user on the comment. NONE withdraws
+// the vote.
+func (r *VoteRepository) VoteComment(ctx context.Context, userID, commentID int, value model.VoteValue) error {
+	return r.vote(ctx, commentTarget, userID, commentID, value)
+}
+
+// vote stores the vote and moves the counters of the entity by the
+// difference to the previous vote, so reads never have to count votes. The
+// entity row stays locked until commit, which keeps concurrent votes of the
+// same user from being counted twice.
+func (r *VoteRepository) vote(ctx context.Context, t target, userID, id int, value model.VoteValue) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	var lockedID int
+
+	err = tx.QueryRowContext(ctx, t.lock, id).Scan(&lockedID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound(t.notFound)
+	}
+	if err != nil {
+		return err
+	}
+
+	var previous int
+
+	row := tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT value FROM %s WHERE %s = $1 AND user_id = $2;`, t.votes, t.key), id, userID)
+	err = row.Scan(&previous)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	upvotes, downvotes := model.VoteDelta(model.VoteValueOf(previous), value)
+	if upvotes == 0 && downvotes == 0 {
+		return tx.Commit()
+	}
+
+	if value == model.VoteValueNone {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM %s WHERE %s = $1 AND user_id = $2;`, t.votes, t.key), id, userID)
+	} else {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO %s (%s, user_id, value) VALUES ($1, $2, $3) 
+						ON CONFLICT (%s, user_id) DO UPDATE SET value = EXCLUDED.value, created_at = NOW();`, t.votes, t.key, t.key),
+			id, userID, value.Number())
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`UPDATE %s SET upvotes = upvotes + $1, downvotes = downvotes + $2 WHERE id = $3;`, t.table),
+		upvotes, downvotes, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetPostVotes returns the votes of the user on the given posts. Posts the
+// user hasn't voted on are missing from the result.
+func (r *VoteRepository) GetPostVotes(ctx context.Context, userID int, postIDs []int) (map[int]model.VoteValue, error) {
+	return r.getVotes(ctx, postTarget, userID, postIDs)
+}
+
+// GetCommentVotes returns the votes of the user on the given comments.
+// Comments the user hasn't voted on are missing from the result.
+func (r *VoteRepository) GetCommentVotes(ctx context.Context, userID int, commentIDs []int) (map[int]model.VoteValue, error) {
+	return r.getVotes(ctx, commentTarget, userID, commentIDs)
+}
+
+func (r *VoteRepository) getVotes(ctx context.Context, t target, userID int, ids []int) (map[int]model.VoteValue, error) {
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(`SELECT %s, value FROM %s WHERE user_id = $1 AND %s = ANY($2);`, t.key, t.votes, t.key), userID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	votes := make(map[int]model.VoteValue, len(ids))
+
+	for rows.Next() {
+		var id, value int
+
+		err = rows.Scan(&id, &value)
+		if err != nil {
+			return nil, err
+		}
+
+		votes[id] = model.VoteValueOf(value)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return votes, nil
+}
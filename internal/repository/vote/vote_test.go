@@ -0,0 +1,171 @@
+package vote
+
+import (
+	"context"
+	"database/sql"
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/aaanger/graphql-test/internal/graph/model"
+	"github.com/aaanger/graphql-test/internal/repository/repotest"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+)
+
+type VoteRepositorySuite struct {
+	suite.Suite
+	db   *sql.DB
+	mock sqlmock.Sqlmock
+	repo *VoteRepository
+}
+
+func (suite *VoteRepositorySuite) SetupTest() {
+	var err error
+	suite.db, suite.mock, err = sqlmock.New(sqlmock.ValueConverterOption(repotest.SliceConverter{}))
+	assert.NoError(suite.T(), err)
+	suite.repo = NewVoteRepository(suite.db)
+}
+
+func TestVoteRepositorySuite(t *testing.T) {
+	suite.Run(t, new(VoteRepositorySuite))
+}
+
+// VotePost
+// ====================================================================================
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostFirstVote() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT id FROM posts WHERE id = \$1 AND deleted_at IS NULL AND status = 'PUBLISHED' FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectQuery(`SELECT value FROM post_votes WHERE post_id = \$1 AND user_id = \$2;`).
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+	suite.mock.ExpectExec(`INSERT INTO post_votes \(post_id, user_id, value\) VALUES \(\$1, \$2, \$3\)\s+ON CONFLICT \(post_id, user_id\) DO UPDATE`).
+		WithArgs(1, 2, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`UPDATE posts SET upvotes = upvotes \+ \$1, downvotes = downvotes \+ \$2 WHERE id = \$3;`).
+		WithArgs(1, 0, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.VotePost(context.Background(), 2, 1, model.VoteValueUp)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostSwitchVote() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectQuery(`SELECT value FROM post_votes`).
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+	suite.mock.ExpectExec(`INSERT INTO post_votes`).
+		WithArgs(1, 2, -1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`UPDATE posts SET upvotes`).
+		WithArgs(-1, 1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.VotePost(context.Background(), 2, 1, model.VoteValueDown)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostWithdraw() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectQuery(`SELECT value FROM post_votes`).
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(-1))
+	suite.mock.ExpectExec(`DELETE FROM post_votes WHERE post_id = \$1 AND user_id = \$2;`).
+		WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`UPDATE posts SET upvotes`).
+		WithArgs(0, -1, 1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.VotePost(context.Background(), 2, 1, model.VoteValueNone)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostSameVote() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	suite.mock.ExpectQuery(`SELECT value FROM post_votes`).
+		WithArgs(1, 2).WillReturnRows(sqlmock.NewRows([]string{"value"}).AddRow(1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.VotePost(context.Background(), 2, 1, model.VoteValueUp)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VotePostNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT id FROM posts (.+) FOR UPDATE;`).
+		WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.VotePost(context.Background(), 2, 1, model.VoteValueUp)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+// VoteComment
+// ====================================================================================
+
+func (suite *VoteRepositorySuite) TestRepository_VoteCommentSuccess() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id AND p.deleted_at IS NULL AND p.status = 'PUBLISHED'\s+WHERE c.id = \$1 AND c.deleted_at IS NULL FOR UPDATE OF c;`).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	suite.mock.ExpectQuery(`SELECT value FROM comment_votes WHERE comment_id = \$1 AND user_id = \$2;`).
+		WithArgs(3, 2).WillReturnRows(sqlmock.NewRows([]string{"value"}))
+	suite.mock.ExpectExec(`INSERT INTO comment_votes \(comment_id, user_id, value\)`).
+		WithArgs(3, 2, -1).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectExec(`UPDATE comments SET upvotes = upvotes \+ \$1, downvotes = downvotes \+ \$2 WHERE id = \$3;`).
+		WithArgs(0, 1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	suite.mock.ExpectCommit()
+
+	err := suite.repo.VoteComment(context.Background(), 2, 3, model.VoteValueDown)
+
+	suite.Nil(err)
+	suite.Nil(suite.mock.ExpectationsWereMet())
+}
+
+func (suite *VoteRepositorySuite) TestRepository_VoteCommentNotFound() {
+	suite.mock.ExpectBegin()
+	suite.mock.ExpectQuery(`SELECT c.id FROM comments (.+) FOR UPDATE OF c;`).
+		WithArgs(3).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	suite.mock.ExpectRollback()
+
+	err := suite.repo.VoteComment(context.Background(), 2, 3, model.VoteValueUp)
+
+	suite.Equal(apperror.CodeNotFound, apperror.CodeOf(err))
+}
+
+// GetVotes
+// ====================================================================================
+
+func (suite *VoteRepositorySuite) TestRepository_GetPostVotes() {
+	suite.mock.ExpectQuery(`SELECT post_id, value FROM post_votes WHERE user_id = \$1 AND post_id = ANY\(\$2\);`).
+		WithArgs(2, []int{1, 2, 3}).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "value"}).AddRow(1, 1).AddRow(3, -1))
+
+	votes, err := suite.repo.GetPostVotes(context.Background(), 2, []int{1, 2, 3})
+
+	suite.Nil(err)
+	suite.Equal(map[int]model.VoteValue{1: model.VoteValueUp, 3: model.VoteValueDown}, votes)
+}
+
+func (suite *VoteRepositorySuite) TestRepository_GetCommentVotes() {
+	suite.mock.ExpectQuery(`SELECT comment_id, value FROM comment_votes WHERE user_id = \$1 AND comment_id = ANY\(\$2\);`).
+		WithArgs(2, []int{4}).
+		WillReturnRows(sqlmock.NewRows([]string{"comment_id", "value"}).AddRow(4, 1))
+
+	votes, err := suite.repo.GetCommentVotes(context.Background(), 2, []int{4})
+
+	suite.Nil(err)
+	suite.Equal(map[int]model.VoteValue{4: model.VoteValueUp}, votes)
+}
@@ -0,0 +1,130 @@
+package apperror
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Code classifies an error for API clients. It is exposed as
+// extensions.code of GraphQL errors.
+type Code string
+
+const (
+	CodeNotFound        Code = "NOT_FOUND"
+	CodeForbidden       Code = "FORBIDDEN"
+	CodeValidation      Code = "VALIDATION"
+	CodeConflict        Code = "CONFLICT"
+	CodeUnauthenticated Code = "UNAUTHENTICATED"
+	CodeBanned          Code = "BANNED"
+	CodeRateLimited     Code = "RATE_LIMITED"
+	CodeInternal        Code = "INTERNAL"
+)
+
+// Error is a domain error. Message and Extensions are safe to show to
+// clients, the wrapped error is an internal detail that is only logged.
+type Error struct {
+	Code    Code
+	Message string
+	Err     error
+
+	// Extensions are added to extensions of the GraphQL error next to the
+	// code.
+	Extensions map[string]interface{}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns a copy of the error with err as its internal cause.
+func (e *Error) Wrap(err error) *Error {
+	return &Error{
+		Code:       e.Code,
+		Message:    e.Message,
+		Err:        err,
+		Extensions: e.Extensions,
+	}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Code: CodeNotFound, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Code: CodeForbidden, Message: message}
+}
+
+func Validation(message string) *Error {
+	return &Error{Code: CodeValidation, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Code: CodeConflict, Message: message}
+}
+
+func Unauthenticated(message string) *Error {
+	return &Error{Code: CodeUnauthenticated, Message: message}
+}
+
+// Banned tells a banned user how long the ban lasts, until is nil for a
+// permanent ban. The expiry and the reason are also exposed as extensions.
+func Banned(until *time.Time, reason string) *Error {
+	message := "user is banned permanently"
+	extensions := map[string]interface{}{
+		"bannedUntil": nil,
+	}
+
+	if until != nil {
+		message = "user is banned until " + until.UTC().Format(time.RFC3339)
+		extensions["bannedUntil"] = until.UTC().Format(time.RFC3339)
+	}
+
+	if reason != "" {
+		extensions["reason"] = reason
+	}
+
+	return &Error{Code: CodeBanned, Message: message, Extensions: extensions}
+}
+
+// RateLimited tells the client to slow down. The wait is exposed in whole
+// seconds as extensions.retryAfter, like the Retry-After HTTP header.
+func RateLimited(retryAfter time.Duration) *Error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return &Error{
+		Code:    CodeRateLimited,
+		Message: "too many requests, retry in " + strconv.Itoa(seconds) + "s",
+		Extensions: map[string]interface{}{
+			"retryAfter": seconds,
+		},
+	}
+}
+
+// Internal hides err behind a generic message.
+func Internal(err error) *Error {
+	return &Error{Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// CodeOf returns the code of the first domain error in the chain of err, or
+// CodeInternal when there is none.
+func CodeOf(err error) Code {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+
+	return CodeInternal
+}
@@ -0,0 +1,51 @@
+package apperror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestError_WrapKeepsCause(t *testing.T) {
+	notFound := NotFound("post not found")
+	err := notFound.Wrap(sql.ErrNoRows)
+
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Equal(t, "post not found", err.Message)
+	assert.Equal(t, "post not found: sql: no rows in result set", err.Error())
+	assert.Nil(t, notFound.Err)
+}
+
+func TestCodeOf(t *testing.T) {
+	assert.Equal(t, CodeForbidden, CodeOf(fmt.Errorf("update post: %w", Forbidden("not yours"))))
+	assert.Equal(t, CodeInternal, CodeOf(errors.New("connection refused")))
+	assert.Equal(t, CodeInternal, CodeOf(nil))
+}
+
+func TestBanned(t *testing.T) {
+	until := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	err := Banned(&until, "spam")
+
+	assert.Equal(t, CodeBanned, CodeOf(err))
+	assert.Equal(t, "user is banned until 2026-01-02T03:04:05Z", err.Message)
+	assert.Equal(t, map[string]interface{}{"bannedUntil": "2026-01-02T03:04:05Z", "reason": "spam"}, err.Extensions)
+
+	err = Banned(nil, "")
+
+	assert.Equal(t, "user is banned permanently", err.Message)
+	assert.Equal(t, map[string]interface{}{"bannedUntil": nil}, err.Extensions)
+}
+
+func TestRateLimited(t *testing.T) {
+	err := RateLimited(1500 * time.Millisecond)
+
+	assert.Equal(t, CodeRateLimited, CodeOf(err))
+	assert.Equal(t, "too many requests, retry in 2s", err.Message)
+	assert.Equal(t, map[string]interface{}{"retryAfter": 2}, err.Extensions)
+
+	assert.Equal(t, 1, RateLimited(0).Extensions["retryAfter"])
+}
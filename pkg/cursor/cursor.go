@@ -0,0 +1,101 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"time"
+)
+
+var ErrInvalidCursor = apperror.Validation("invalid cursor")
+
+// Cursor points at a row of a keyset-paginated list. Rows are ordered by
+// either Time, Count or Rank and ties are broken by ID, so a cursor stays
+// stable no matter how many rows share the same sort value. Kind tells apart
+// rows of lists mixing several entities, whose IDs may collide.
+type Cursor struct {
+	Time  time.Time `json:"t"`
+	Count int       `json:"n,omitempty"`
+	Rank  float64   `json:"r,omitempty"`
+	Kind  string    `json:"k,omitempty"`
+	ID    int       `json:"id"`
+}
+
+func New(t time.Time, id int) Cursor {
+	return Cursor{
+		Time: t,
+		ID:   id,
+	}
+}
+
+func NewCount(count, id int) Cursor {
+	return Cursor{
+		Count: count,
+		ID:    id,
+	}
+}
+
+func NewRank(rank float64, kind string, id int) Cursor {
+	return Cursor{
+		Rank: rank,
+		Kind: kind,
+		ID:   id,
+	}
+}
+
+// Encode returns the opaque string handed out to clients.
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Before reports whether c sorts before other in ascending order.
+func (c Cursor) Before(other Cursor) bool {
+	if !c.Time.Equal(other.Time) {
+		return c.Time.Before(other.Time)
+	}
+
+	if c.Count != other.Count {
+		return c.Count < other.Count
+	}
+
+	if c.Rank != other.Rank {
+		return c.Rank < other.Rank
+	}
+
+	if c.Kind != other.Kind {
+		return c.Kind < other.Kind
+	}
+
+	return c.ID < other.ID
+}
+
+func Decode(s string) (Cursor, error) {
+	var c Cursor
+
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(data, &c); err != nil || c.ID == 0 {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// DecodeOptional decodes s when it is set.
+func DecodeOptional(s *string) (*Cursor, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	c, err := Decode(*s)
+	if err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
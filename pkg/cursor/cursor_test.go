@@ -0,0 +1,64 @@
+package cursor
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	c := New(time.Date(2024, 1, 2, 3, 4, 5, 123456000, time.UTC), 42)
+
+	decoded, err := Decode(c.Encode())
+
+	assert.Nil(t, err)
+	assert.True(t, c.Time.Equal(decoded.Time))
+	assert.Equal(t, 42, decoded.ID)
+}
+
+func TestCursor_DecodeInvalid(t *testing.T) {
+	for _, s := range []string{"", "not base64!", "e30", "2024-01-02T03:04:05Z"} {
+		_, err := Decode(s)
+		assert.ErrorIs(t, err, ErrInvalidCursor, s)
+	}
+}
+
+func TestCursor_DecodeOptional(t *testing.T) {
+	c, err := DecodeOptional(nil)
+	assert.Nil(t, c)
+	assert.Nil(t, err)
+
+	s := New(time.Now(), 1).Encode()
+	c, err = DecodeOptional(&s)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, c.ID)
+}
+
+func TestCursor_BeforeBreaksTiesByID(t *testing.T) {
+	now := time.Now()
+
+	assert.True(t, New(now, 1).Before(New(now, 2)))
+	assert.False(t, New(now, 2).Before(New(now, 1)))
+	assert.True(t, New(now, 2).Before(New(now.Add(time.Microsecond), 1)))
+}
+
+func TestCursor_CountEncodeDecode(t *testing.T) {
+	decoded, err := Decode(NewCount(7, 3).Encode())
+
+	assert.Nil(t, err)
+	assert.Equal(t, 7, decoded.Count)
+	assert.Equal(t, 3, decoded.ID)
+	assert.True(t, NewCount(7, 3).Before(NewCount(8, 1)))
+	assert.True(t, NewCount(7, 3).Before(NewCount(7, 4)))
+}
+
+func TestCursor_RankEncodeDecode(t *testing.T) {
+	c := NewRank(0.0607927, "post", 3)
+
+	decoded, err := Decode(c.Encode())
+
+	assert.Nil(t, err)
+	assert.Equal(t, c, decoded)
+	assert.True(t, NewRank(0.1, "post", 3).Before(NewRank(0.2, "comment", 1)))
+	assert.True(t, NewRank(0.1, "comment", 3).Before(NewRank(0.1, "post", 1)))
+}
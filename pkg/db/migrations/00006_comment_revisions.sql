@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments ADD COLUMN updated_at TIMESTAMP;
+
+CREATE TABLE comment_revisions (
+    id SERIAL PRIMARY KEY,
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX comment_revisions_comment_id_idx ON comment_revisions (comment_id, created_at DESC, id DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE comment_revisions;
+
+ALTER TABLE comments DROP COLUMN updated_at;
+-- +goose StatementEnd
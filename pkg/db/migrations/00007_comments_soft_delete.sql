@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE comments ADD COLUMN deleted_at TIMESTAMP;
+
+-- replies are removed together with their parent when a thread is purged
+ALTER TABLE comments DROP CONSTRAINT comments_parent_comment_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_parent_comment_id_fkey
+    FOREIGN KEY (parent_comment_id) REFERENCES comments(id) ON DELETE CASCADE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE comments DROP CONSTRAINT comments_parent_comment_id_fkey;
+ALTER TABLE comments ADD CONSTRAINT comments_parent_comment_id_fkey
+    FOREIGN KEY (parent_comment_id) REFERENCES comments(id);
+
+ALTER TABLE comments DROP COLUMN deleted_at;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX posts_deleted_at_idx ON posts (deleted_at) WHERE deleted_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX posts_deleted_at_idx;
+
+ALTER TABLE posts DROP COLUMN deleted_at;
+-- +goose StatementEnd
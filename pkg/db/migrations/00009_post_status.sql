@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'PUBLISHED' CHECK (status IN ('DRAFT', 'PUBLISHED'));
+ALTER TABLE posts ADD COLUMN publish_at TIMESTAMP;
+
+CREATE INDEX posts_publish_at_idx ON posts (publish_at) WHERE status = 'DRAFT' AND publish_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX posts_publish_at_idx;
+
+ALTER TABLE posts DROP COLUMN publish_at;
+ALTER TABLE posts DROP COLUMN status;
+-- +goose StatementEnd
@@ -0,0 +1,36 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN upvotes INT NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN downvotes INT NOT NULL DEFAULT 0;
+
+ALTER TABLE comments ADD COLUMN upvotes INT NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN downvotes INT NOT NULL DEFAULT 0;
+
+CREATE TABLE post_votes (
+    post_id INT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (post_id, user_id)
+);
+
+CREATE TABLE comment_votes (
+    comment_id INT NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    value SMALLINT NOT NULL CHECK (value IN (-1, 1)),
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE comment_votes;
+DROP TABLE post_votes;
+
+ALTER TABLE comments DROP COLUMN downvotes;
+ALTER TABLE comments DROP COLUMN upvotes;
+
+ALTER TABLE posts DROP COLUMN downvotes;
+ALTER TABLE posts DROP COLUMN upvotes;
+-- +goose StatementEnd
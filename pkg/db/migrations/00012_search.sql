@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE posts ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', title), 'A') || setweight(to_tsvector('simple', body), 'B')
+) STORED;
+
+ALTER TABLE comments ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    to_tsvector('simple', body)
+) STORED;
+
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (search_vector);
+CREATE INDEX comments_search_vector_idx ON comments USING GIN (search_vector);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX comments_search_vector_idx;
+DROP INDEX posts_search_vector_idx;
+
+ALTER TABLE comments DROP COLUMN search_vector;
+ALTER TABLE posts DROP COLUMN search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE reports (
+    id SERIAL PRIMARY KEY,
+    reporter_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INT REFERENCES posts(id) ON DELETE SET NULL,
+    comment_id INT REFERENCES comments(id) ON DELETE SET NULL,
+    reason VARCHAR(16) NOT NULL CHECK (reason IN ('SPAM', 'HARASSMENT', 'HATE_SPEECH', 'VIOLENCE', 'OTHER')),
+    note TEXT CHECK (LENGTH(note) <= 500),
+    status VARCHAR(16) NOT NULL DEFAULT 'OPEN' CHECK (status IN ('OPEN', 'RESOLVED')),
+    action VARCHAR(16) CHECK (action IN ('DISMISS', 'HIDE', 'DELETE', 'BAN_AUTHOR')),
+    resolved_by INT REFERENCES users(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX reports_status_idx ON reports (status, created_at, id);
+CREATE UNIQUE INDEX reports_open_post_idx ON reports (reporter_id, post_id) WHERE status = 'OPEN' AND post_id IS NOT NULL;
+CREATE UNIQUE INDEX reports_open_comment_idx ON reports (reporter_id, comment_id) WHERE status = 'OPEN' AND comment_id IS NOT NULL;
+
+ALTER TABLE users ADD COLUMN banned_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN banned_until TIMESTAMP;
+ALTER TABLE users ADD COLUMN ban_reason TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN ban_reason;
+ALTER TABLE users DROP COLUMN banned_until;
+ALTER TABLE users DROP COLUMN banned_at;
+
+DROP TABLE reports;
+-- +goose StatementEnd
@@ -0,0 +1,88 @@
+package jwt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const defaultKeyID = "default"
+
+type Config struct {
+	// KeyID is the kid of the signing key.
+	KeyID string
+	// Secret or SecretFile hold the HS256 secret. They are ignored when
+	// PrivateKeyFile is set.
+	Secret     string
+	SecretFile string
+	// PrivateKeyFile is a PEM encoded RSA (RS256) or Ed25519 (EdDSA) key.
+	PrivateKeyFile string
+	// VerificationKeys lists previous keys still accepted for verification
+	// as comma separated kid=path pairs. A file holds a PEM public or private
+	// key, or an HMAC secret.
+	VerificationKeys string
+}
+
+func Open(cfg Config) (*Manager, error) {
+	keyID := cfg.KeyID
+	if keyID == "" {
+		keyID = defaultKeyID
+	}
+
+	var (
+		signingKey *Key
+		err        error
+	)
+
+	switch {
+	case cfg.PrivateKeyFile != "":
+		signingKey, err = readKey(keyID, cfg.PrivateKeyFile)
+	case cfg.SecretFile != "":
+		signingKey, err = readKey(keyID, cfg.SecretFile)
+	case cfg.Secret != "":
+		signingKey = NewHMACKey(keyID, []byte(cfg.Secret))
+	default:
+		err = errors.New("no signing key configured")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var verificationKeys []*Key
+
+	for _, pair := range strings.Split(cfg.VerificationKeys, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		id, path, ok := strings.Cut(pair, "=")
+		if !ok || id == "" || path == "" {
+			return nil, fmt.Errorf("invalid verification key %q, expected kid=path", pair)
+		}
+
+		key, err := readKey(id, path)
+		if err != nil {
+			return nil, err
+		}
+
+		verificationKeys = append(verificationKeys, key)
+	}
+
+	return NewManager(signingKey, verificationKeys...)
+}
+
+func readKey(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ParseKey(id, []byte(strings.TrimSpace(string(data))))
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return key, nil
+}
@@ -1,31 +1,85 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"github.com/dgrijalva/jwt-go"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"strconv"
 	"time"
 )
 
 const (
-	tokenExpire     = 12 * time.Hour
-	tokenSigningKey = "a1#slkj2327@KSmsda.#k32q"
+	AccessTokenExpire  = 15 * time.Minute
+	RefreshTokenExpire = 30 * 24 * time.Hour
+
+	refreshTokenSize = 32
 )
 
 type tokenClaims struct {
-	jwt.StandardClaims
-	UserID int `json:"id"`
+	jwt.RegisteredClaims
+	SessionID int    `json:"sid"`
+	Role      string `json:"role,omitempty"`
+}
+
+// Claims are the values carried by a valid access token.
+type Claims struct {
+	UserID    int
+	SessionID int
+	Role      string
+}
+
+// Manager signs access tokens with one key and verifies them with any of its
+// keys, so a key can be replaced while tokens signed by the previous one are
+// still accepted.
+type Manager struct {
+	signingKey *Key
+	keys       map[string]*Key
+}
+
+// NewManager returns a manager signing with signingKey. verificationKeys are
+// only used to verify tokens, usually they are the previous signing keys.
+func NewManager(signingKey *Key, verificationKeys ...*Key) (*Manager, error) {
+	if signingKey == nil || !signingKey.canSign() {
+		return nil, errors.New("signing key must contain a private key")
+	}
+
+	m := &Manager{
+		signingKey: signingKey,
+		keys:       map[string]*Key{signingKey.ID: signingKey},
+	}
+
+	for _, key := range verificationKeys {
+		if _, ok := m.keys[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+
+		m.keys[key.ID] = key
+	}
+
+	return m, nil
 }
 
-func GenerateAccessToken(userID int) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &tokenClaims{
-		jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(tokenExpire).Unix(),
-			IssuedAt:  time.Now().Unix(),
+// GenerateAccessToken signs a token for the session. The role is copied into
+// the token, so role changes apply to tokens issued after them.
+func (m *Manager) GenerateAccessToken(userID, sessionID int, role string) (string, error) {
+	now := time.Now()
+
+	token := jwt.NewWithClaims(m.signingKey.method, &tokenClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.Itoa(userID),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenExpire)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		userID,
+		SessionID: sessionID,
+		Role:      role,
 	})
+	token.Header["kid"] = m.signingKey.ID
 
-	signedToken, err := token.SignedString([]byte(tokenSigningKey))
+	signedToken, err := token.SignedString(m.signingKey.signKey)
 	if err != nil {
 		return "", err
 	}
@@ -33,26 +87,59 @@ func GenerateAccessToken(userID int) (string, error) {
 	return signedToken, nil
 }
 
-func ParseToken(accessToken string) (int, error) {
+func (m *Manager) ParseToken(accessToken string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(accessToken, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+
+		key, ok := m.keys[kid]
+		if !ok {
+			return nil, errors.New("unknown signing key")
+		}
+
+		// the algorithm comes from the token, so it must match the key
+		// to prevent algorithm confusion
+		if token.Method.Alg() != key.method.Alg() {
 			return nil, errors.New("invalid signing token method")
 		}
 
-		return []byte(tokenSigningKey), nil
-	})
+		return key.verifyKey, nil
+	}, jwt.WithExpirationRequired())
 	if err != nil {
-		return 0, err
-	}
-
-	if !token.Valid {
-		return 0, errors.New("invalid token")
+		return nil, err
 	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
-		return 0, errors.New("invalid token claims")
+		return nil, errors.New("invalid token claims")
 	}
 
-	return claims.UserID, nil
+	userID, err := strconv.Atoi(claims.Subject)
+	if err != nil {
+		return nil, errors.New("invalid token subject")
+	}
+
+	return &Claims{
+		UserID:    userID,
+		SessionID: claims.SessionID,
+		Role:      claims.Role,
+	}, nil
+}
+
+// GenerateRefreshToken returns a random opaque refresh token. Only its hash
+// is stored, so a leaked sessions table can't be used to refresh.
+func GenerateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenSize)
+
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func HashRefreshToken(refreshToken string) string {
+	sum := sha256.Sum256([]byte(refreshToken))
+
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,182 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManager_SignAndParse(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	for _, key := range []*Key{
+		NewHMACKey("hmac", []byte("secret")),
+		NewRSAKey("rsa", rsaKey),
+		NewEd25519Key("ed", edKey),
+	} {
+		m, err := NewManager(key)
+		require.NoError(t, err)
+
+		token, err := m.GenerateAccessToken(1, 2, "USER")
+		require.NoError(t, err, key.ID)
+
+		claims, err := m.ParseToken(token)
+		assert.Nil(t, err, key.ID)
+		assert.Equal(t, &Claims{UserID: 1, SessionID: 2, Role: "USER"}, claims, key.ID)
+	}
+}
+
+func TestManager_RotatedKeyStillVerifies(t *testing.T) {
+	oldKey := NewHMACKey("old", []byte("old secret"))
+	old, err := NewManager(oldKey)
+	require.NoError(t, err)
+
+	token, err := old.GenerateAccessToken(1, 2, "USER")
+	require.NoError(t, err)
+
+	rotated, err := NewManager(NewHMACKey("new", []byte("new secret")), oldKey)
+	require.NoError(t, err)
+
+	claims, err := rotated.ParseToken(token)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, claims.UserID)
+
+	retired, err := NewManager(NewHMACKey("new", []byte("new secret")))
+	require.NoError(t, err)
+
+	_, err = retired.ParseToken(token)
+	assert.NotNil(t, err)
+}
+
+func TestManager_RejectsAlgorithmMismatch(t *testing.T) {
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	// a token signed with HS256 under the kid of an EdDSA key
+	attacker, err := NewManager(NewHMACKey("ed", []byte("secret")))
+	require.NoError(t, err)
+
+	token, err := attacker.GenerateAccessToken(1, 2, "USER")
+	require.NoError(t, err)
+
+	m, err := NewManager(NewEd25519Key("ed", edKey))
+	require.NoError(t, err)
+
+	_, err = m.ParseToken(token)
+	assert.NotNil(t, err)
+}
+
+func TestNewManager_RequiresPrivateSigningKey(t *testing.T) {
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	key, err := NewPublicKey("public", publicKey)
+	require.NoError(t, err)
+
+	_, err = NewManager(key)
+	assert.NotNil(t, err)
+}
+
+func TestOpen_PrivateKeyFileWithVerificationKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	der, err := x509.MarshalPKCS8PrivateKey(edKey)
+	require.NoError(t, err)
+
+	privatePath := filepath.Join(dir, "ed25519.pem")
+	require.NoError(t, os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0600))
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	der, err = x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+
+	publicPath := filepath.Join(dir, "rsa.pub")
+	require.NoError(t, os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600))
+
+	secretPath := filepath.Join(dir, "secret")
+	require.NoError(t, os.WriteFile(secretPath, []byte("secret\n"), 0600))
+
+	m, err := Open(Config{
+		KeyID:            "2024-02",
+		PrivateKeyFile:   privatePath,
+		VerificationKeys: "2024-01=" + publicPath + ", legacy=" + secretPath,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "EdDSA", m.signingKey.Algorithm())
+	assert.Equal(t, "RS256", m.keys["2024-01"].Algorithm())
+	assert.Equal(t, []byte("secret"), m.keys["legacy"].verifyKey)
+
+	legacy, err := NewManager(NewHMACKey("legacy", []byte("secret")))
+	require.NoError(t, err)
+
+	token, err := legacy.GenerateAccessToken(1, 2, "USER")
+	require.NoError(t, err)
+
+	_, err = m.ParseToken(token)
+	assert.Nil(t, err)
+}
+
+func TestOpen_NoKey(t *testing.T) {
+	_, err := Open(Config{})
+	assert.NotNil(t, err)
+
+	_, err = Open(Config{Secret: "secret", VerificationKeys: "missing-path"})
+	assert.NotNil(t, err)
+}
+
+func TestManager_JWKSHandler(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicKey, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	edKey, err := NewPublicKey("ed", publicKey)
+	require.NoError(t, err)
+
+	m, err := NewManager(NewRSAKey("rsa", rsaKey), edKey, NewHMACKey("hmac", []byte("secret")))
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	m.JWKSHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/.well-known/jwks.json", nil))
+
+	var set JWKSet
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, JWK{KeyType: "OKP", KeyID: "ed", Use: "sig", Algorithm: "EdDSA", Curve: "Ed25519", X: set.Keys[0].X}, set.Keys[0])
+	assert.Equal(t, "rsa", set.Keys[1].KeyID)
+	assert.Equal(t, "RS256", set.Keys[1].Algorithm)
+	assert.Equal(t, "AQAB", set.Keys[1].E)
+}
+
+func TestHashRefreshToken(t *testing.T) {
+	token, err := GenerateRefreshToken()
+	require.NoError(t, err)
+
+	other, err := GenerateRefreshToken()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, token, other)
+	assert.Equal(t, HashRefreshToken(token), HashRefreshToken(token))
+	assert.NotEqual(t, HashRefreshToken(token), HashRefreshToken(other))
+}
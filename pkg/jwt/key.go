@@ -0,0 +1,123 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key is a token key identified by kid. Keys parsed from public keys can only
+// verify tokens, all other keys can also sign them.
+type Key struct {
+	ID string
+
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+func NewRSAKey(id string, privateKey *rsa.PrivateKey) *Key {
+	return &Key{
+		ID:        id,
+		method:    jwt.SigningMethodRS256,
+		signKey:   privateKey,
+		verifyKey: &privateKey.PublicKey,
+	}
+}
+
+func NewEd25519Key(id string, privateKey ed25519.PrivateKey) *Key {
+	return &Key{
+		ID:        id,
+		method:    jwt.SigningMethodEdDSA,
+		signKey:   privateKey,
+		verifyKey: privateKey.Public(),
+	}
+}
+
+// NewPublicKey returns a verification-only key, used for keys whose private
+// part has been rotated out of this instance.
+func NewPublicKey(id string, publicKey crypto.PublicKey) (*Key, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &Key{ID: id, method: jwt.SigningMethodRS256, verifyKey: k}, nil
+	case ed25519.PublicKey:
+		return &Key{ID: id, method: jwt.SigningMethodEdDSA, verifyKey: k}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+	}
+}
+
+// ParseKey reads a PEM encoded private or public key. Data that isn't PEM is
+// used as an HMAC secret.
+func ParseKey(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		if len(data) == 0 {
+			return nil, errors.New("empty key")
+		}
+
+		return NewHMACKey(id, data), nil
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewRSAKey(id, privateKey), nil
+	case "PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		switch k := privateKey.(type) {
+		case *rsa.PrivateKey:
+			return NewRSAKey(id, k), nil
+		case ed25519.PrivateKey:
+			return NewEd25519Key(id, k), nil
+		default:
+			return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+		}
+	case "RSA PUBLIC KEY":
+		publicKey, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPublicKey(id, publicKey)
+	case "PUBLIC KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPublicKey(id, publicKey)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+}
+
+func (k *Key) Algorithm() string {
+	return k.method.Alg()
+}
+
+func (k *Key) canSign() bool {
+	return k.signKey != nil
+}
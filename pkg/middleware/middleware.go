@@ -3,12 +3,27 @@ package middleware
 import (
 	"context"
 	"errors"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/aaanger/graphql-test/pkg/apperror"
 	"github.com/aaanger/graphql-test/pkg/jwt"
+	"net"
 	"net/http"
 	"strings"
 )
 
-func UserIdentity(next http.Handler) http.Handler {
+// SessionChecker reports whether the session an access token was issued for
+// is still active, so revoked tokens are rejected before they expire.
+type SessionChecker interface {
+	IsSessionActive(ctx context.Context, sessionID int) (bool, error)
+}
+
+// BanChecker returns a BANNED error while the user is banned, so tokens of
+// banned users are rejected for the length of the ban.
+type BanChecker interface {
+	CheckBan(ctx context.Context, userID int) error
+}
+
+func UserIdentity(tokens *jwt.Manager, sessions SessionChecker, bans BanChecker, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		header := r.Header.Get("Authorization")
 
@@ -17,29 +32,71 @@ func UserIdentity(next http.Handler) http.Handler {
 			return
 		}
 
-		headerParts := strings.Split(header, " ")
-
-		if len(headerParts) != 2 {
-			http.Error(w, "Invalid authorization header", http.StatusUnauthorized)
+		ctx, err := authenticate(r.Context(), tokens, sessions, bans, header)
+		if apperror.CodeOf(err) == apperror.CodeBanned {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
 			return
 		}
 
-		userID, err := jwt.ParseToken(headerParts[1])
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// WebsocketInit authenticates subscriptions by the Authorization value of the
+// connection_init payload, because browsers can't set headers on websocket
+// upgrade requests. A header already handled by UserIdentity is kept.
+func WebsocketInit(tokens *jwt.Manager, sessions SessionChecker, bans BanChecker) transport.WebsocketInitFunc {
+	return func(ctx context.Context, initPayload transport.InitPayload) (context.Context, *transport.InitPayload, error) {
+		header := initPayload.Authorization()
+
+		if header == "" {
+			return ctx, &initPayload, nil
+		}
+
+		ctx, err := authenticate(ctx, tokens, sessions, bans, header)
 		if err != nil {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return ctx, nil, err
 		}
 
-		ctx := context.WithValue(r.Context(), "userID", userID)
+		return ctx, &initPayload, nil
+	}
+}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
+// ClientIP stores the IP of the client in the request context. Behind a
+// reverse proxy trustProxy takes the IP from the last X-Forwarded-For entry,
+// the one added by the proxy, so clients can't forge it.
+func ClientIP(trustProxy bool, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+
+		if forwarded := r.Header.Get("X-Forwarded-For"); trustProxy && forwarded != "" {
+			entries := strings.Split(forwarded, ",")
+			ip = strings.TrimSpace(entries[len(entries)-1])
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), "clientIP", ip)))
 	})
 }
 
+// GetClientIP returns the IP stored by ClientIP, or an empty string.
+func GetClientIP(ctx context.Context) string {
+	ip, _ := ctx.Value("clientIP").(string)
+
+	return ip
+}
+
 func GetUserID(ctx context.Context) (int, error) {
 	id := ctx.Value("userID")
 
 	if id == nil {
-		return 0, errors.New("user id not found")
+		return 0, apperror.Unauthenticated("authentication required")
 	}
 
 	userID, ok := id.(int)
@@ -49,3 +106,71 @@ func GetUserID(ctx context.Context) (int, error) {
 
 	return userID, nil
 }
+
+func GetSessionID(ctx context.Context) (int, error) {
+	id := ctx.Value("sessionID")
+
+	if id == nil {
+		return 0, apperror.Unauthenticated("authentication required")
+	}
+
+	sessionID, ok := id.(int)
+	if !ok {
+		return 0, errors.New("invalid type of session id")
+	}
+
+	return sessionID, nil
+}
+
+// GetRole returns the role of the authenticated user, if the request carries
+// one.
+func GetRole(ctx context.Context) (string, error) {
+	role, ok := ctx.Value("role").(string)
+	if !ok || role == "" {
+		return "", errors.New("role not found")
+	}
+
+	return role, nil
+}
+
+func authenticate(ctx context.Context, tokens *jwt.Manager, sessions SessionChecker, bans BanChecker, header string) (context.Context, error) {
+	claims, err := parseAuthorizationHeader(tokens, header)
+	if err != nil {
+		return ctx, err
+	}
+
+	isActive, err := sessions.IsSessionActive(ctx, claims.SessionID)
+	if err != nil {
+		return ctx, err
+	}
+
+	if !isActive {
+		return ctx, errors.New("Session has been revoked")
+	}
+
+	err = bans.CheckBan(ctx, claims.UserID)
+	if err != nil {
+		return ctx, err
+	}
+
+	ctx = context.WithValue(ctx, "userID", claims.UserID)
+	ctx = context.WithValue(ctx, "sessionID", claims.SessionID)
+	ctx = context.WithValue(ctx, "role", claims.Role)
+
+	return ctx, nil
+}
+
+func parseAuthorizationHeader(tokens *jwt.Manager, header string) (*jwt.Claims, error) {
+	headerParts := strings.Split(header, " ")
+
+	if len(headerParts) != 2 {
+		return nil, errors.New("Invalid authorization header")
+	}
+
+	claims, err := tokens.ParseToken(headerParts[1])
+	if err != nil {
+		return nil, errors.New("Invalid token")
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,80 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultBufferSize = 16
+
+// Hub fans out published messages to everyone subscribed to the same topic.
+// A subscriber that falls behind by more than the buffer size misses
+// messages instead of blocking the publisher.
+type Hub[K comparable, V any] struct {
+	mu          sync.RWMutex
+	subscribers map[K]map[chan V]struct{}
+	bufferSize  int
+}
+
+func NewHub[K comparable, V any](bufferSize int) *Hub[K, V] {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Hub[K, V]{
+		subscribers: make(map[K]map[chan V]struct{}),
+		bufferSize:  bufferSize,
+	}
+}
+
+// Subscribe returns a channel receiving messages published to topic. The
+// subscription is removed and the channel closed once ctx is done.
+func (h *Hub[K, V]) Subscribe(ctx context.Context, topic K) <-chan V {
+	ch := make(chan V, h.bufferSize)
+
+	h.mu.Lock()
+	if h.subscribers[topic] == nil {
+		h.subscribers[topic] = make(map[chan V]struct{})
+	}
+	h.subscribers[topic][ch] = struct{}{}
+	h.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		h.unsubscribe(topic, ch)
+	}()
+
+	return ch
+}
+
+func (h *Hub[K, V]) Publish(topic K, msg V) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for ch := range h.subscribers[topic] {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// Subscribers returns the number of active subscriptions to topic.
+func (h *Hub[K, V]) Subscribers(topic K) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.subscribers[topic])
+}
+
+func (h *Hub[K, V]) unsubscribe(topic K, ch chan V) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscribers[topic], ch)
+	if len(h.subscribers[topic]) == 0 {
+		delete(h.subscribers, topic)
+	}
+
+	close(ch)
+}
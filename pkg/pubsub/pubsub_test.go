@@ -0,0 +1,58 @@
+package pubsub
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestHub_PublishToTopicSubscribers(t *testing.T) {
+	hub := NewHub[int, string](1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	first := hub.Subscribe(ctx, 1)
+	other := hub.Subscribe(ctx, 2)
+
+	hub.Publish(1, "hello")
+
+	assert.Equal(t, "hello", <-first)
+	assert.Empty(t, other)
+}
+
+func TestHub_SlowSubscriberDoesNotBlock(t *testing.T) {
+	hub := NewHub[int, string](1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := hub.Subscribe(ctx, 1)
+
+	hub.Publish(1, "first")
+	hub.Publish(1, "second")
+
+	assert.Equal(t, "first", <-ch)
+	assert.Empty(t, ch)
+}
+
+func TestHub_UnsubscribeOnContextDone(t *testing.T) {
+	hub := NewHub[int, string](1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := hub.Subscribe(ctx, 1)
+	assert.Equal(t, 1, hub.Subscribers(1))
+
+	cancel()
+
+	select {
+	case _, ok := <-ch:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed after context cancellation")
+	}
+
+	assert.Equal(t, 0, hub.Subscribers(1))
+	hub.Publish(1, "after close")
+}
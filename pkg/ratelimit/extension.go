@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/aaanger/graphql-test/pkg/middleware"
+	"github.com/vektah/gqlparser/v2/ast"
+	"strconv"
+)
+
+// Extension limits calls of root fields, such as login or createComment,
+// with a token bucket per field and caller. Authenticated callers are told
+// apart by the user ID of their token, anonymous callers by their IP.
+//
+// Limits are keyed by the field name rather than the operation name of the
+// request, because clients choose operation names freely. Every call of a
+// limited field takes a token, so a document repeating the field under
+// different aliases is charged once per call.
+type Extension struct {
+	Store  Store
+	Limits map[string]Limit
+}
+
+var _ interface {
+	graphql.HandlerExtension
+	graphql.FieldInterceptor
+} = Extension{}
+
+func (e Extension) ExtensionName() string {
+	return "RateLimit"
+}
+
+// Validate makes sure every limited field exists, so a misspelled field
+// doesn't silently go unlimited.
+func (e Extension) Validate(schema graphql.ExecutableSchema) error {
+	s := schema.Schema()
+
+	for name := range e.Limits {
+		if isField(s.Query, name) || isField(s.Mutation, name) || isField(s.Subscription, name) {
+			continue
+		}
+
+		return fmt.Errorf("rate limit for unknown field %q", name)
+	}
+
+	return nil
+}
+
+func (e Extension) InterceptField(ctx context.Context, next graphql.Resolver) (any, error) {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || !isRootObject(fc.Object) {
+		return next(ctx)
+	}
+
+	limit, ok := e.Limits[fc.Field.Name]
+	if !ok {
+		return next(ctx)
+	}
+
+	allowed, retryAfter, err := e.Store.Take(ctx, fc.Field.Name+":"+caller(ctx), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, apperror.RateLimited(retryAfter)
+	}
+
+	return next(ctx)
+}
+
+func isRootObject(name string) bool {
+	return name == "Query" || name == "Mutation" || name == "Subscription"
+}
+
+func isField(definition *ast.Definition, name string) bool {
+	return definition != nil && definition.Fields.ForName(name) != nil
+}
+
+// caller identifies who is calling, the user when the request is
+// authenticated and the client IP otherwise.
+func caller(ctx context.Context) string {
+	userID, err := middleware.GetUserID(ctx)
+	if err == nil {
+		return "user:" + strconv.Itoa(userID)
+	}
+
+	return "ip:" + middleware.GetClientIP(ctx)
+}
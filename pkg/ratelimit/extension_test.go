@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/aaanger/graphql-test/pkg/apperror"
+	"github.com/stretchr/testify/assert"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"testing"
+	"time"
+)
+
+func rootField(ctx context.Context, name string) context.Context {
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Object: "Mutation",
+		Field:  graphql.CollectedField{Field: &ast.Field{Name: name}},
+	})
+}
+
+func resolved(ctx context.Context) (any, error) {
+	return true, nil
+}
+
+func TestExtension_LimitsPerCaller(t *testing.T) {
+	ext := Extension{
+		Store:  NewMemoryStore(),
+		Limits: map[string]Limit{"login": {Burst: 1, Period: time.Minute}},
+	}
+
+	first := rootField(context.WithValue(context.Background(), "clientIP", "10.0.0.1"), "login")
+	second := rootField(context.WithValue(context.Background(), "clientIP", "10.0.0.2"), "login")
+	user := rootField(context.WithValue(context.WithValue(context.Background(), "clientIP", "10.0.0.1"), "userID", 1), "login")
+
+	_, err := ext.InterceptField(first, resolved)
+	assert.Nil(t, err)
+
+	_, err = ext.InterceptField(first, resolved)
+	assert.Equal(t, apperror.CodeRateLimited, apperror.CodeOf(err))
+	assert.Equal(t, 60, err.(*apperror.Error).Extensions["retryAfter"])
+
+	_, err = ext.InterceptField(second, resolved)
+	assert.Nil(t, err)
+
+	_, err = ext.InterceptField(user, resolved)
+	assert.Nil(t, err)
+}
+
+func TestExtension_ChargesAliasedCallsAcrossOperations(t *testing.T) {
+	ext := Extension{
+		Store:  NewMemoryStore(),
+		Limits: map[string]Limit{"login": {Burst: 2, Period: time.Minute}},
+	}
+
+	// a: login(...) b: login(...) c: login(...) spread over two operations
+	call := func(operation, alias string) error {
+		ctx := graphql.WithOperationContext(context.WithValue(context.Background(), "clientIP", "10.0.0.1"), &graphql.OperationContext{
+			OperationName: operation,
+		})
+		ctx = graphql.WithFieldContext(ctx, &graphql.FieldContext{
+			Object: "Mutation",
+			Field:  graphql.CollectedField{Field: &ast.Field{Name: "login", Alias: alias}},
+		})
+
+		_, err := ext.InterceptField(ctx, resolved)
+		return err
+	}
+
+	assert.Nil(t, call("SignIn", "a"))
+	assert.Nil(t, call("SignIn", "b"))
+	assert.Equal(t, apperror.CodeRateLimited, apperror.CodeOf(call("SignIn", "c")))
+	assert.Equal(t, apperror.CodeRateLimited, apperror.CodeOf(call("Other", "a")))
+}
+
+func TestExtension_SkipsUnlimitedAndNestedFields(t *testing.T) {
+	ext := Extension{
+		Store:  NewMemoryStore(),
+		Limits: map[string]Limit{"login": {Burst: 1, Period: time.Minute}},
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := ext.InterceptField(rootField(context.Background(), "register"), resolved)
+		assert.Nil(t, err)
+
+		// a nested field that happens to share the name of a limited one
+		_, err = ext.InterceptField(graphql.WithFieldContext(rootField(context.Background(), "me"), &graphql.FieldContext{
+			Object: "User",
+			Field:  graphql.CollectedField{Field: &ast.Field{Name: "login"}},
+		}), resolved)
+		assert.Nil(t, err)
+	}
+}
+
+func TestExtension_Validate(t *testing.T) {
+	schema := &graphql.ExecutableSchemaMock{
+		SchemaFunc: func() *ast.Schema {
+			return gqlparser.MustLoadSchema(&ast.Source{Input: `
+				type Query { posts: [String!]! }
+				type Mutation { login: String! }
+			`})
+		},
+	}
+
+	assert.Nil(t, Extension{Limits: map[string]Limit{"login": {Burst: 1, Period: time.Second}}}.Validate(schema))
+	assert.Error(t, Extension{Limits: map[string]Limit{"logn": {Burst: 1, Period: time.Second}}}.Validate(schema))
+}
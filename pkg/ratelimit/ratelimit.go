@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+// Limit is a token bucket holding up to Burst tokens, refilled at Burst
+// tokens per Period. Every call takes one token.
+type Limit struct {
+	Burst  int
+	Period time.Duration
+}
+
+// interval is how long it takes to refill one token.
+func (l Limit) interval() time.Duration {
+	return l.Period / time.Duration(l.Burst)
+}
+
+func (l Limit) String() string {
+	return strconv.Itoa(l.Burst) + "/" + l.Period.String()
+}
+
+// ParseLimits reads comma separated limits in the name=burst/period format,
+// e.g. "login=5/1m,createComment=30/1m", where name is a root field.
+func ParseLimits(value string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		name, spec, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid rate limit %q, expected name=burst/period", pair)
+		}
+
+		burst, period, ok := strings.Cut(spec, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q, expected name=burst/period", pair)
+		}
+
+		var limit Limit
+		var err error
+
+		limit.Burst, err = strconv.Atoi(burst)
+		if err != nil || limit.Burst <= 0 {
+			return nil, fmt.Errorf("invalid burst of rate limit %q", pair)
+		}
+
+		limit.Period, err = time.ParseDuration(period)
+		if err != nil || limit.Period <= 0 {
+			return nil, fmt.Errorf("invalid period of rate limit %q", pair)
+		}
+
+		limits[name] = limit
+	}
+
+	return limits, nil
+}
+
+// Store keeps the token buckets. MemoryStore is enough for a single instance,
+// several instances behind a load balancer need a shared store.
+type Store interface {
+	// Take takes a token from the bucket of key. When the bucket is empty
+	// it returns false and how long until the next token.
+	Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error)
+}
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	// fullAt is when the bucket is refilled and can be forgotten.
+	fullAt time.Time
+}
+
+// MemoryStore keeps the buckets in memory. Refilled buckets are dropped from
+// time to time, so keys of past clients don't pile up.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (bool, time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{
+			tokens:    float64(limit.Burst),
+			updatedAt: now,
+		}
+		s.buckets[key] = b
+	}
+
+	interval := limit.interval()
+
+	b.tokens += float64(now.Sub(b.updatedAt)) / float64(interval)
+	if b.tokens > float64(limit.Burst) {
+		b.tokens = float64(limit.Burst)
+	}
+	b.updatedAt = now
+
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) * float64(interval)), nil
+	}
+
+	b.tokens--
+	b.fullAt = now.Add(time.Duration((float64(limit.Burst) - b.tokens) * float64(interval)))
+
+	return true, 0, nil
+}
+
+// sweep drops the buckets that are full again. The caller must hold s.mu.
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+
+	s.lastSweep = now
+}
@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestStore() (*MemoryStore, *clock) {
+	c := &clock{now: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	store := NewMemoryStore()
+	store.now = c.Now
+
+	return store, c
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" login=5/1m, createComment=30/1h ,")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]Limit{
+		"login":         {Burst: 5, Period: time.Minute},
+		"createComment": {Burst: 30, Period: time.Hour},
+	}, limits)
+}
+
+func TestParseLimits_Invalid(t *testing.T) {
+	for _, value := range []string{"login", "login=5", "login=0/1m", "login=five/1m", "login=5/soon", "=5/1m"} {
+		_, err := ParseLimits(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestMemoryStore_TakesBurstThenWaits(t *testing.T) {
+	store, _ := newTestStore()
+	limit := Limit{Burst: 3, Period: 3 * time.Second}
+
+	for i := 0; i < 3; i++ {
+		allowed, _, err := store.Take(context.Background(), "key", limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+
+	allowed, retryAfter, err := store.Take(context.Background(), "key", limit)
+
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryAfter)
+}
+
+func TestMemoryStore_Refills(t *testing.T) {
+	store, c := newTestStore()
+	limit := Limit{Burst: 2, Period: 2 * time.Second}
+
+	for i := 0; i < 2; i++ {
+		_, _, _ = store.Take(context.Background(), "key", limit)
+	}
+
+	c.now = c.now.Add(500 * time.Millisecond)
+
+	allowed, retryAfter, _ := store.Take(context.Background(), "key", limit)
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	c.now = c.now.Add(500 * time.Millisecond)
+
+	allowed, _, _ = store.Take(context.Background(), "key", limit)
+	assert.True(t, allowed)
+
+	// other keys have buckets of their own
+	allowed, _, _ = store.Take(context.Background(), "other", limit)
+	assert.True(t, allowed)
+}
+
+func TestMemoryStore_SweepsFullBuckets(t *testing.T) {
+	store, c := newTestStore()
+	limit := Limit{Burst: 1, Period: time.Second}
+
+	_, _, _ = store.Take(context.Background(), "idle", limit)
+
+	c.now = c.now.Add(2 * sweepInterval)
+	_, _, _ = store.Take(context.Background(), "active", limit)
+
+	assert.NotContains(t, store.buckets, "idle")
+	assert.Contains(t, store.buckets, "active")
+}